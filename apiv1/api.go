@@ -35,6 +35,14 @@ const (
 	ProductionRoute = "/production"
 	// ListProductionsRoute route to ListProductionsEndpoint
 	ListProductionsRoute = "/productions"
+	// UpdateProductionRoute route to RenameProductionEndpoint
+	UpdateProductionRoute = "/production/:prod"
+	// DeleteProductionRoute route to DeleteProductionEndpoint
+	DeleteProductionRoute = "/production/:prod"
+	// ArchiveProductionRoute route to ArchiveProductionEndpoint POST,DELETE
+	ArchiveProductionRoute = "/production/:prod/archive"
+	// TransferProductionRoute route to TransferProductionEndpoint
+	TransferProductionRoute = "/production/:prod/transfer"
 
 	// resource routes
 
@@ -48,16 +56,76 @@ const (
 	UpdateResourceRoute = "/resource/:prod/:kind/:id"
 	// DeleteResourceRoute route to ResourceEndpoint
 	DeleteResourceRoute = "/resource/:prod/:kind/:id"
+	// ListRevisionsRoute route to ListRevisionsEndpoint
+	ListRevisionsRoute = "/resource/:id/history"
+	// DiffRevisionsRoute route to DiffRevisionsEndpoint
+	DiffRevisionsRoute = "/resource/:id/diff/:from/:to"
+	// RestoreRevisionRoute route to RestoreRevisionEndpoint
+	RestoreRevisionRoute = "/resource/:id/restore/:rev"
+	// ListTrashRoute route to ListTrashEndpoint
+	ListTrashRoute = "/trash/:prod"
+	// UndeleteResourceRoute route to UndeleteResourceEndpoint
+	UndeleteResourceRoute = "/trash/:prod/:id"
 
 	// BuildRoute route to BuildEndpoint
 	BuildRoute = "/build"
+	// GetBuildRoute route to GetBuildEndpoint
+	GetBuildRoute = "/build/:prod/:id"
+	// BuildArtifactRoute route to BuildArtifactEndpoint
+	BuildArtifactRoute = "/build/:prod/:id/feed.xml"
+	// ListBuildsRoute route to ListBuildsEndpoint
+	ListBuildsRoute = "/builds/:prod"
+	// RollbackRoute route to RollbackEndpoint
+	RollbackRoute = "/rollback"
+	// ImportFeedRoute route to ImportFeedEndpoint
+	ImportFeedRoute = "/import"
+	// LintRoute route to LintEndpoint
+	LintRoute = "/lint/:prod"
+	// ScheduleRoute route to ScheduleEndpoint
+	ScheduleRoute = "/schedule/:prod"
+	// StatsRoute route to StatsEndpoint
+	StatsRoute = "/stats/:prod"
+	// SubscriptionRoute route to CreateSubscriptionEndpoint
+	SubscriptionRoute = "/subscription"
+	// ListSubscriptionsRoute route to ListSubscriptionsEndpoint
+	ListSubscriptionsRoute = "/subscriptions/:prod"
+	// RevokeSubscriptionRoute route to RevokeSubscriptionEndpoint
+	RevokeSubscriptionRoute = "/subscription/:prod/:token"
+	// MemberRoute route to AddMemberEndpoint
+	MemberRoute = "/member"
+	// ListMembersRoute route to ListMembersEndpoint
+	ListMembersRoute = "/members/:prod"
+	// RemoveMemberRoute route to RemoveMemberEndpoint
+	RemoveMemberRoute = "/member/:prod/:id"
+	// WebhookRoute route to CreateWebhookEndpoint
+	WebhookRoute = "/webhook"
+	// ListWebhooksRoute route to ListWebhooksEndpoint
+	ListWebhooksRoute = "/webhooks/:prod"
+	// DeleteWebhookRoute route to DeleteWebhookEndpoint
+	DeleteWebhookRoute = "/webhook/:prod/:id"
+	// ListDeliveriesRoute route to ListDeliveriesEndpoint
+	ListDeliveriesRoute = "/webhooks/:prod/deliveries"
 	// UploadRoute route to UploadEndpoint
 	UploadRoute = "/upload/:prod"
+	// CreateUploadRoute route to CreateUploadEndpoint
+	CreateUploadRoute = "/uploads/:prod"
+	// UploadChunkRoute route to UploadOffsetEndpoint, UploadChunkEndpoint and DeleteUploadEndpoint
+	UploadChunkRoute = "/uploads/:prod/:id"
+	// VerifyRoute route to VerifyEndpoint GET,POST
+	VerifyRoute = "/verify/:prod"
+	// ReconcileRoute route to ReconcileEndpoint GET,POST
+	ReconcileRoute = "/fsck/:prod"
+	// RepairReportRoute route to RepairReportEndpoint
+	RepairReportRoute = "/fsck/:prod/repair"
 
 	// CDN routes
 
 	// FeedRoute route to feed.xml
 	FeedRoute = "/s/:name/feed.xml"
+	// PrivateFeedRoute route to a subscriber's feed.xml
+	PrivateFeedRoute = "/s/:name/:token/feed.xml"
+	// HubRoute route to the built-in WebSub hub
+	HubRoute = "/hub"
 
 	// GraphQL API routes
 
@@ -74,6 +142,24 @@ const (
 	SyncTask = "/sync"
 	// DeleteTask route to DeleteTaskEndpoint
 	DeleteTask = "/sync/:prod"
+	// PurgeProductionTask route to PurgeProductionTaskEndpoint
+	PurgeProductionTask = "/purge/:prod"
+	// ScheduleTask route to ScheduleTaskEndpoint
+	ScheduleTask = "/schedule"
+	// BuildTask route to BuildTaskEndpoint
+	BuildTask = "/build"
+	// PurgeTrashTask route to PurgeTrashTaskEndpoint
+	PurgeTrashTask = "/purge"
+	// StatsTask route to StatsTaskEndpoint
+	StatsTask = "/stats"
+	// DeliverTask route to DeliverTaskEndpoint
+	DeliverTask = "/deliver"
+	// DistributeTask route to DistributeTaskEndpoint
+	DistributeTask = "/distribute"
+	// VerifyTask route to VerifyTaskEndpoint
+	VerifyTask = "/verify"
+	// RepairTask route to RepairTaskEndpoint
+	RepairTask = "/fsck"
 
 	// status routes
 
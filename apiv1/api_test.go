@@ -0,0 +1,121 @@
+package apiv1
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/txsvc/platform/v2/pkg/apis/provider"
+	"github.com/txsvc/platform/v2/pkg/authentication"
+
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/errordef"
+)
+
+const (
+	adminToken = "admin-token"
+	userToken  = "user-token"
+)
+
+type (
+	// testQueue records tasks instead of dispatching them
+	testQueue struct {
+		tasks []provider.HttpTask
+		err   error
+	}
+)
+
+var (
+	// testTokens are the authorizations of the tokens used in tests. The admin token is minted like PODOPS_API_KEY,
+	// see cmd/apiuser.
+	testTokens = map[string]*authentication.Authorization{
+		adminToken: {ClientID: "admin", Scope: authentication.ScopeAPIAdmin},
+		userToken:  {ClientID: "client", Scope: strings.Join([]string{ScopeProductionRead, ScopeProductionWrite, ScopeProductionBuild, ScopeResourceRead, ScopeResourceWrite}, ",")},
+	}
+)
+
+func (q *testQueue) CreateHttpTask(ctx context.Context, task provider.HttpTask) error {
+	if q.err != nil {
+		return q.err
+	}
+	q.tasks = append(q.tasks, task)
+	return nil
+}
+
+// setupAPI registers an empty local store, the test tokens and a task queue that only records the tasks
+func setupAPI(t *testing.T) *testQueue {
+	dir := t.TempDir()
+
+	r, err := backend.NewLocalRepository(filepath.Join(dir, "inventory.db"))
+	if !assert.NoError(t, err) {
+		t.FailNow()
+	}
+	backend.RegisterStore(r, backend.NewLocalBlobStore(filepath.Join(dir, "bucket")))
+
+	q := &testQueue{}
+	tp = q
+	checkAuthorization = func(ctx context.Context, c echo.Context, scope string) (*authentication.Authorization, error) {
+		token, err := authentication.GetBearerToken(c.Request())
+		if err != nil {
+			return nil, err
+		}
+		auth, ok := testTokens[token]
+		if !ok || !strings.Contains(auth.Scope, scope) {
+			return nil, errordef.ErrNotAuthorized
+		}
+		return auth, nil
+	}
+	getClientID = func(ctx context.Context, r *http.Request) (string, error) {
+		token, err := authentication.GetBearerToken(r)
+		if err != nil {
+			return "", err
+		}
+		if auth, ok := testTokens[token]; ok {
+			return auth.ClientID, nil
+		}
+		return "", errordef.ErrNotAuthorized
+	}
+	t.Cleanup(func() {
+		tp = nil
+		checkAuthorization = authentication.CheckAuthorization
+		getClientID = authentication.GetClientID
+	})
+	return q
+}
+
+// callEndpoint calls an endpoint on behalf of a token. params are the names and values of the route parameters.
+func callEndpoint(h echo.HandlerFunc, method, token string, payload interface{}, params ...string) *httptest.ResponseRecorder {
+	var body []byte
+	if payload != nil {
+		body, _ = json.Marshal(payload)
+	}
+	req := httptest.NewRequest(method, "/", bytes.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+	rec := httptest.NewRecorder()
+
+	c := echo.New().NewContext(req, rec)
+	var names, values []string
+	for i := 0; i+1 < len(params); i += 2 {
+		names = append(names, params[i])
+		values = append(values, params[i+1])
+	}
+	c.SetParamNames(names...)
+	c.SetParamValues(values...)
+	if err := h(c); err != nil {
+		c.Error(err)
+	}
+	return rec
+}
+
+// assertStatus is used instead of assert.Equal to show the response when the status does not match
+func assertStatus(t *testing.T, status int, rec *httptest.ResponseRecorder) bool {
+	return assert.Equal(t, status, rec.Code, rec.Body.String())
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/txsvc/platform/v2/pkg/authentication"
 
+	"github.com/podops/podops"
 	"github.com/podops/podops/backend"
 	"github.com/podops/podops/internal/errordef"
 )
@@ -19,9 +20,24 @@ const (
 	ScopeResourceWrite   = "resource:write"
 )
 
+var (
+	// checkAuthorization validates the bearer token of a request against a scope, tests replace it
+	checkAuthorization = authentication.CheckAuthorization
+	// getClientID returns the client the bearer token of a request was issued to, tests replace it
+	getClientID = authentication.GetClientID
+
+	// roleScopes maps the role of a member to the scopes the role grants in the production
+	roleScopes = map[string][]string{
+		podops.RoleOwner:     {ScopeProductionRead, ScopeProductionWrite, ScopeProductionBuild, ScopeResourceRead, ScopeResourceWrite},
+		podops.RolePublisher: {ScopeProductionRead, ScopeProductionBuild, ScopeResourceRead, ScopeResourceWrite},
+		podops.RoleEditor:    {ScopeProductionRead, ScopeResourceRead, ScopeResourceWrite},
+		podops.RoleViewer:    {ScopeProductionRead, ScopeResourceRead},
+	}
+)
+
 // AuthorizeAccess verifies that the user has the required roles in her authorization
 func AuthorizeAccess(ctx context.Context, c echo.Context, scope string) error {
-	_, err := authentication.CheckAuthorization(ctx, c, scope)
+	_, err := checkAuthorization(ctx, c, scope)
 	if err != nil {
 		return err
 	}
@@ -32,7 +48,7 @@ func AuthorizeAccess(ctx context.Context, c echo.Context, scope string) error {
 // AuthorizeAccessProduction verifies that the user has the required roles in
 // her authorization and can access the production.
 func AuthorizeAccessProduction(ctx context.Context, c echo.Context, scope, claim string) error {
-	auth, err := authentication.CheckAuthorization(ctx, c, scope)
+	auth, err := checkAuthorization(ctx, c, scope)
 	if err != nil {
 		return err
 	}
@@ -47,17 +63,36 @@ func AuthorizeAccessProduction(ctx context.Context, c echo.Context, scope, claim
 		return errordef.ErrNoSuchProduction
 	}
 
+	return authorizeMember(ctx, auth, scope, p)
+}
+
+// AuthorizeOwnerProduction verifies that the user owns the production. Transferring and
+// deleting a production is limited to its owner, members with the owner role can't do it.
+func AuthorizeOwnerProduction(ctx context.Context, c echo.Context, claim string) error {
+	auth, err := checkAuthorization(ctx, c, ScopeProductionWrite)
+	if err != nil {
+		return err
+	}
+
+	if auth.HasAdminScope() {
+		// can access any production
+		return nil
+	}
+
+	p, err := backend.GetProduction(ctx, claim)
+	if err != nil || p == nil {
+		return errordef.ErrNoSuchProduction
+	}
 	if p.Owner != auth.ClientID {
 		return errordef.ErrNotAuthorized
 	}
-
 	return nil
 }
 
 // AuthorizeAccessResource verifies that the user has the required roles in
 // her authorization and can access the resource.
 func AuthorizeAccessResource(ctx context.Context, c echo.Context, scope, claim string) error {
-	auth, err := authentication.CheckAuthorization(ctx, c, scope)
+	auth, err := checkAuthorization(ctx, c, scope)
 	if err != nil {
 		return err
 	}
@@ -76,9 +111,30 @@ func AuthorizeAccessResource(ctx context.Context, c echo.Context, scope, claim s
 	if err != nil || p == nil {
 		return errordef.ErrNoSuchProduction
 	}
-	if p.Owner != auth.ClientID {
-		return errordef.ErrNotAuthorized
+
+	return authorizeMember(ctx, auth, scope, p)
+}
+
+// authorizeMember verifies that the client owns the production or is a member whose role grants scope
+func authorizeMember(ctx context.Context, auth *authentication.Authorization, scope string, p *podops.Production) error {
+	if p.Owner == auth.ClientID {
+		return nil
 	}
 
-	return nil
+	m, err := backend.FindMember(ctx, p.GUID, auth.ClientID)
+	if err == nil && m == nil {
+		// the user might have been invited before having an account
+		if err = backend.ClaimInvitations(ctx, auth.Realm, auth.UserID, auth.ClientID); err == nil {
+			m, err = backend.FindMember(ctx, p.GUID, auth.ClientID)
+		}
+	}
+	if err != nil || m == nil {
+		return errordef.ErrNotAuthorized
+	}
+	for _, s := range roleScopes[m.Role] {
+		if s == scope {
+			return nil
+		}
+	}
+	return errordef.ErrNotAuthorized
 }
@@ -1,6 +1,8 @@
 package apiv1
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,17 +12,22 @@ import (
 	"github.com/txsvc/platform/v2"
 	"github.com/txsvc/platform/v2/pkg/api"
 	"github.com/txsvc/platform/v2/pkg/apis/provider"
+	"github.com/txsvc/platform/v2/pkg/authentication"
 	"github.com/txsvc/platform/v2/pkg/env"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
 
 	"github.com/podops/podops"
 	"github.com/podops/podops/backend"
 	"github.com/podops/podops/feed"
+	"github.com/podops/podops/internal/errordef"
 	"github.com/podops/podops/internal/messagedef"
+	"github.com/podops/podops/internal/validator"
 )
 
 var (
 	// full canonical route
 	syncTaskEndpoint string = podops.DefaultCDNEndpoint + "/_w/sync"
+	buildJobEndpoint string = podops.DefaultAPIEndpoint + WebhookNamespacePrefix + BuildTask
 
 	tp provider.HttpTaskProvider
 )
@@ -43,9 +50,9 @@ func background() provider.HttpTaskProvider {
 	return tp
 }
 
-// BuildFeedEndpoint starts the build of the feed
+// BuildFeedEndpoint queues a build of the feed and returns the build job
 func BuildFeedEndpoint(c echo.Context) error {
-	var req *podops.BuildRequest = new(podops.BuildRequest) // FIXME change this
+	var req *podops.BuildRequest = new(podops.BuildRequest)
 	ctx := platform.NewHttpContext(c.Request())
 
 	if err := c.Bind(req); err != nil {
@@ -54,11 +61,19 @@ func BuildFeedEndpoint(c echo.Context) error {
 	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionBuild, req.GUID); err != nil {
 		return api.ErrorResponse(c, http.StatusUnauthorized, err)
 	}
+	// the feed of an archived production is frozen
+	if err := AssertWritable(ctx, req.GUID); err != nil {
+		return api.ErrorResponse(c, http.StatusConflict, err)
+	}
 
 	validateOnly := false
 	if strings.ToLower(c.QueryParam("v")) == "true" {
 		validateOnly = true
 	}
+	strict := false
+	if strings.ToLower(c.QueryParam("s")) == "true" {
+		strict = true
+	}
 
 	p, err := backend.GetProduction(ctx, req.GUID)
 	if err != nil {
@@ -68,38 +83,289 @@ func BuildFeedEndpoint(c echo.Context) error {
 		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgResourceInvalidGUID, req.GUID))
 	}
 
-	if err := feed.Build(ctx, req.GUID, validateOnly); err != nil {
+	b, err := backend.CreateBuild(ctx, req.GUID, validateOnly, strict)
+	if err != nil {
 		return api.ErrorResponse(c, http.StatusBadRequest, err)
 	}
 
-	if !validateOnly {
-		// dispatch a request for background sync
-		ir := podops.SyncRequest{
-			GUID:   req.GUID,
-			Source: "feed.xml",
+	// dispatch the build to the background worker
+	if err := queueBuild(ctx, b); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.build", "production", p.GUID)
+
+	return api.StandardResponse(c, http.StatusAccepted, b)
+}
+
+// BuildTaskEndpoint runs a queued build. The outcome, including all issues found, is recorded with the build.
+func BuildTaskEndpoint(c echo.Context) error {
+	var req *podops.BuildRequest = new(podops.BuildRequest)
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if err := AuthorizeAccess(ctx, c, authentication.ScopeAPIAdmin); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	b, err := backend.GetBuild(ctx, req.GUID, req.ID)
+	if err != nil {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusInternalServerError) // the task will be retried
+	}
+	if b == nil || b.State != podops.BuildQueued {
+		return c.NoContent(http.StatusOK) // nothing to do, e.g. the task was delivered twice
+	}
+
+	b.State = podops.BuildRunning
+	b.Started = timestamp.Now()
+	if err := backend.UpdateBuild(ctx, b); err != nil {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	// the production may have been archived or moved to the trash since the build was queued
+	err = AssertWritable(ctx, b.GUID)
+	if err == nil {
+		// builds that run longer are failed by the scheduler anyways
+		buildCtx, cancel := context.WithTimeout(ctx, backend.BuildTimeout)
+		err = runBuild(buildCtx, b)
+		cancel()
+	}
+
+	if err != nil {
+		b.State = podops.BuildFailed
+		b.Message = err.Error()
+		if errors.Is(err, context.DeadlineExceeded) {
+			b.Message = errordef.ErrBuildTimeout.Error()
 		}
+	} else {
+		b.State = podops.BuildSucceeded
+	}
+	b.Finished = timestamp.Now()
+
+	if err := backend.UpdateBuild(ctx, b); err != nil {
+		platform.ReportError(err)
+	}
+
+	if b.State == podops.BuildSucceeded {
+		backend.Emit(ctx, b.GUID, podops.EventBuildSucceeded, b.ID, b)
+	} else {
+		backend.Emit(ctx, b.GUID, podops.EventBuildFailed, b.ID, b)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.build.task", "production", b.GUID, "state", b.State)
+
+	return c.NoContent(http.StatusOK)
+}
+
+// GetBuildEndpoint returns a build, including its issues
+func GetBuildEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	buildID := c.Param("id")
+	if prod == "" || buildID == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionRead, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	b, err := backend.GetBuild(ctx, prod, buildID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if b == nil {
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchBuild)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.build.get", "production", prod)
+
+	return api.StandardResponse(c, http.StatusOK, b)
+}
+
+// ListBuildsEndpoint returns the most recent builds of a production
+func ListBuildsEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionRead, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	l, err := backend.ListBuilds(ctx, prod, backend.DefaultBuildHistory)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.build.list", "production", prod)
+
+	return api.StandardResponse(c, http.StatusOK, &podops.BuildList{Builds: l})
+}
+
+// BuildArtifactEndpoint returns the feed.xml generated by a build
+func BuildArtifactEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	buildID := c.Param("id")
+	if prod == "" || buildID == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionRead, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
 
-		task := provider.HttpTask{
-			Method:  provider.HttpMethodPost,
-			Request: syncTaskEndpoint,
-			Token:   env.GetString("PODOPS_API_KEY", ""),
-			Payload: &ir,
+	b, err := backend.GetBuild(ctx, prod, buildID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if b == nil || b.Artifact == "" {
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchBuild)
+	}
+
+	data, err := backend.DefaultBlobStore().Read(ctx, b.Artifact)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusNotFound, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.build.artifact", "production", prod)
+
+	return c.Blob(http.StatusOK, "application/rss+xml", data)
+}
+
+// runBuild builds the feed, keeps a copy of it as the build's artifact and dispatches the sync to the CDN
+func runBuild(ctx context.Context, b *podops.Build) error {
+	v := validator.New(b.GUID)
+	data, err := feed.Build(ctx, b.GUID, b.ValidateOnly, b.Strict, v)
+
+	report := feed.NewLintReport(b.GUID, v)
+	b.Errors = report.Errors
+	b.Warnings = report.Warnings
+	b.Issues = make([]podops.LintIssue, len(report.Issues))
+	for i, issue := range report.Issues {
+		b.Issues[i] = *issue
+	}
+	if err != nil {
+		if b.Errors > 0 {
+			return errordef.ErrValidationFailed // the issues explain why
 		}
+		return err
+	}
+
+	location := backend.BuildArtifactLocation(b.GUID, b.ID)
+	if err := backend.DefaultBlobStore().Write(ctx, location, data); err != nil {
+		return err
+	}
+	b.Artifact = location
+
+	if b.ValidateOnly {
+		return nil
+	}
+
+	// keep the private feed and the production's metadata, a rollback restores both
+	private, err := backend.DefaultBlobStore().Read(ctx, fmt.Sprintf("%s/private.xml", b.GUID))
+	if err != nil {
+		return err
+	}
+	if err := backend.DefaultBlobStore().Write(ctx, backend.PrivateArtifactLocation(b.GUID, b.ID), private); err != nil {
+		return err
+	}
 
-		err := background().CreateHttpTask(ctx, task)
-		if err != nil {
-			return err
+	// the build updates the production's metadata
+	p, err := backend.GetProduction(ctx, b.GUID)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return errordef.ErrNoSuchProduction
+	}
+	b.Private = p.Private
+	b.PrivateAssets = p.PrivateAssets
+	b.BuildDate = p.BuildDate
+	b.LatestPublishDate = p.LatestPublishDate
+
+	return syncFeed(ctx, p)
+}
+
+// RollbackEndpoint republishes the feed of an earlier build. Without a build ID, the feed published before the live one is restored.
+func RollbackEndpoint(c echo.Context) error {
+	var req *podops.BuildRequest = new(podops.BuildRequest)
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionBuild, req.GUID); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	// the feed of an archived production is frozen
+	if err := AssertWritable(ctx, req.GUID); err != nil {
+		return api.ErrorResponse(c, http.StatusConflict, err)
+	}
+
+	b, err := feed.Rollback(ctx, req.GUID, req.ID)
+	if err != nil {
+		if err == errordef.ErrNoSuchBuild {
+			return api.ErrorResponse(c, http.StatusNotFound, err)
 		}
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	p, err := backend.GetProduction(ctx, req.GUID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if err := syncFeed(ctx, p); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
 	}
 
 	// track api access for billing etc
-	platform.Meter(ctx, "api.build", "production", p.GUID)
+	platform.Meter(ctx, "api.rollback", "production", req.GUID, "build", b.RollbackOf)
+
+	return api.StandardResponse(c, http.StatusCreated, b)
+}
+
+// queueBuild dispatches a recorded build to the background worker. The task authenticates with the API key,
+// i.e. it does not need any production scopes.
+func queueBuild(ctx context.Context, b *podops.Build) error {
+	task := provider.HttpTask{
+		Method:  provider.HttpMethodPost,
+		Request: buildJobEndpoint,
+		Token:   env.GetString("PODOPS_API_KEY", ""),
+		Payload: &podops.BuildRequest{GUID: b.GUID, ID: b.ID},
+	}
+	return background().CreateHttpTask(ctx, task)
+}
 
-	resp := podops.BuildRequest{
-		GUID:         req.GUID,
-		FeedURL:      fmt.Sprintf("%s/%s/feed.xml", podops.DefaultStorageEndpoint, req.GUID),
-		FeedAliasURL: fmt.Sprintf("%s/s/%s/feed.xml", podops.DefaultEndpoint, p.Name),
+// syncFeed dispatches a request to copy the production's feed.xml to the CDN
+func syncFeed(ctx context.Context, p *podops.Production) error {
+	if p == nil || p.Private {
+		return nil // members-only shows don't have a public feed
 	}
 
-	return api.StandardResponse(c, http.StatusCreated, &resp)
+	task := provider.HttpTask{
+		Method:  provider.HttpMethodPost,
+		Request: syncTaskEndpoint,
+		Token:   env.GetString("PODOPS_API_KEY", ""),
+		Payload: &podops.SyncRequest{
+			GUID:   p.GUID,
+			Source: "feed.xml",
+		},
+	}
+	return background().CreateHttpTask(ctx, task)
 }
@@ -0,0 +1,40 @@
+package apiv1
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/errordef"
+)
+
+func TestBuildTaskEndpointArchived(t *testing.T) {
+	setupAPI(t)
+	ctx := context.TODO()
+
+	p, err := backend.CreateProduction(ctx, "archived-podcast", "title", "summary", "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+	b, err := backend.CreateBuild(ctx, p.GUID, false, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the production is archived after the build was queued
+	_, err = backend.ArchiveProduction(ctx, p.GUID, true)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assertStatus(t, http.StatusOK, callEndpoint(BuildTaskEndpoint, http.MethodPost, adminToken, &podops.BuildRequest{GUID: p.GUID, ID: b.ID}))
+	b, err = backend.GetBuild(ctx, p.GUID, b.ID)
+	if assert.NoError(t, err) && assert.NotNil(t, b) {
+		assert.Equal(t, podops.BuildFailed, b.State)
+		assert.Equal(t, errordef.ErrProductionArchived.Error(), b.Message)
+	}
+}
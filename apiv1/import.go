@@ -0,0 +1,221 @@
+package apiv1
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/api"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/feed"
+	"github.com/podops/podops/internal/messagedef"
+)
+
+const (
+	// MaxFeedSize is the size limit of feeds that are imported
+	MaxFeedSize = 16 * 1024 * 1024
+
+	importTimeout = 30 * time.Second
+	// importConcurrency limits the number of assets of a feed that are checked at the same time
+	importConcurrency = 8
+)
+
+var (
+	// importClient fetches feeds on behalf of users, i.e. it must not reach into our own network
+	importClient = backend.NewPublicClient(importTimeout)
+)
+
+// ImportFeedEndpoint creates the show and all episodes of a production from an existing RSS feed
+func ImportFeedEndpoint(c echo.Context) error {
+	var req *podops.FeedImportRequest = new(podops.FeedImportRequest)
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeResourceWrite, req.GUID); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	if err := AssertWritable(ctx, req.GUID); err != nil {
+		return api.ErrorResponse(c, http.StatusConflict, err)
+	}
+
+	forceFlag := false
+	if strings.ToLower(c.QueryParam("f")) == "true" {
+		forceFlag = true
+	}
+
+	p, err := backend.GetProduction(ctx, req.GUID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusNotFound, err)
+	}
+	if p == nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgResourceInvalidGUID, req.GUID))
+	}
+
+	if err := backend.ValidatePublicURL(ctx, req.FeedURL); err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	resp, err := importClient.Get(req.FeedURL)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgResourceImportError, req.FeedURL))
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, MaxFeedSize+1))
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if len(data) > MaxFeedSize {
+		return api.ErrorResponse(c, http.StatusRequestEntityTooLarge, fmt.Errorf(messagedef.MsgResourceTooLarge, req.FeedURL, MaxFeedSize))
+	}
+
+	show, episodes, err := feed.TransformFromFeed(data, p.Name, p.GUID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// all assets are checked before anything is written, every asset only once
+	assets := feedAssets(show, episodes)
+	if err := checkAssets(ctx, p.GUID, assets); err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// imports are queued once their resource was written, every asset only once
+	queued := make(map[string]bool)
+	queueImports := func(assets ...*podops.Asset) error {
+		for _, a := range assets {
+			if a == nil || queued[assetKey(a)] {
+				continue
+			}
+			if err := backend.QueueImport(ctx, p.GUID, a); err != nil {
+				return err
+			}
+			queued[assetKey(a)] = true
+		}
+		return nil
+	}
+
+	// the show, the inventory follows the content that was written
+	clientID, _ := getClientID(ctx, c.Request())
+	location := fmt.Sprintf("%s/%s-%s.yaml", p.GUID, podops.ResourceShow, p.GUID)
+	if _, err := backend.WriteRevision(ctx, p.GUID, p.GUID, clientID, location, true, forceFlag, nil, show); err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if err := backend.UpdateShow(ctx, location, show); err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if err := queueImports(&show.Image); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	p.Title = show.Description.Title
+	p.Summary = show.Description.Summary
+	p.Updated = timestamp.Now()
+
+	if err := backend.UpdateProduction(ctx, p); err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// all episodes
+	for _, e := range episodes {
+		location := fmt.Sprintf("%s/%s-%s.yaml", p.GUID, podops.ResourceEpisode, e.GUID())
+		if _, err := backend.WriteRevision(ctx, p.GUID, e.GUID(), clientID, location, true, forceFlag, nil, e); err != nil {
+			return api.ErrorResponse(c, http.StatusBadRequest, err)
+		}
+		if err := backend.UpdateEpisode(ctx, location, e); err != nil {
+			return api.ErrorResponse(c, http.StatusBadRequest, err)
+		}
+		if err := queueImports(episodeAssets(e)...); err != nil {
+			return api.ErrorResponse(c, http.StatusInternalServerError, err)
+		}
+	}
+
+	if err := backend.UpdateSchedule(ctx, p.GUID); err != nil {
+		platform.ReportError(err)
+	}
+
+	l, err := backend.ListResources(ctx, p.GUID, podops.ResourceALL)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.import", "production", p.GUID, "episodes", fmt.Sprintf("%d", len(episodes)))
+
+	return api.StandardResponse(c, http.StatusCreated, &podops.ResourceList{Resources: l})
+}
+
+// feedAssets returns the assets of an imported show and its episodes, every asset only once
+func feedAssets(show *podops.Show, episodes []*podops.Episode) []*podops.Asset {
+	assets := make([]*podops.Asset, 0)
+	seen := make(map[string]bool)
+
+	all := []*podops.Asset{&show.Image}
+	for _, e := range episodes {
+		all = append(all, episodeAssets(e)...)
+	}
+	for _, a := range all {
+		if a == nil || seen[assetKey(a)] {
+			continue
+		}
+		seen[assetKey(a)] = true
+		assets = append(assets, a)
+	}
+	return assets
+}
+
+// episodeAssets returns the image, media file, transcripts and chapters of an episode. Chapters might be nil.
+func episodeAssets(e *podops.Episode) []*podops.Asset {
+	assets := []*podops.Asset{&e.Image, &e.Enclosure}
+	for i := range e.Transcripts {
+		assets = append(assets, &e.Transcripts[i])
+	}
+	return append(assets, e.Chapters)
+}
+
+// assetKey identifies the same asset referenced by several resources
+func assetKey(a *podops.Asset) string {
+	return a.Rel + " " + a.URI
+}
+
+// checkAssets validates the existence of assets, importConcurrency of them at the same time
+func checkAssets(ctx context.Context, production string, assets []*podops.Asset) error {
+	errs := make(chan error, len(assets))
+	sem := make(chan struct{}, importConcurrency)
+
+	var wg sync.WaitGroup
+	for _, a := range assets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(a *podops.Asset) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			errs <- backend.CheckAsset(ctx, production, a)
+		}(a)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package apiv1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/errordef"
+)
+
+func TestImportFeedPrivateURL(t *testing.T) {
+	setupAPI(t)
+	ctx := context.TODO()
+
+	fetched := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetched = true
+	}))
+	defer srv.Close()
+
+	p, err := backend.CreateProduction(ctx, "imported-podcast", "title", "summary", "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	req := &podops.FeedImportRequest{GUID: p.GUID, FeedURL: srv.URL + "/feed.xml"}
+	rec := callEndpoint(ImportFeedEndpoint, http.MethodPost, userToken, req)
+	if assertStatus(t, http.StatusBadRequest, rec) {
+		assert.Contains(t, rec.Body.String(), errordef.ErrPrivateURL.Error())
+	}
+	assert.False(t, fetched)
+}
+
+func TestFeedAssets(t *testing.T) {
+	show := podops.DefaultShow("imported-podcast", "title", "summary", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	show.Image = podops.Asset{URI: "https://example.com/cover.png", Rel: podops.ResourceTypeImport}
+
+	e1 := podops.DefaultEpisode("episode1", show.Metadata.Name, "episode-guid-1", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	e1.Image = show.Image
+	e1.Enclosure = podops.Asset{URI: "https://example.com/episode1.mp3", Rel: podops.ResourceTypeImport}
+	e2 := podops.DefaultEpisode("episode2", show.Metadata.Name, "episode-guid-2", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	e2.Image = show.Image
+	e2.Enclosure = podops.Asset{URI: "https://example.com/episode2.mp3", Rel: podops.ResourceTypeImport}
+	e2.Chapters = &podops.Asset{URI: "https://example.com/episode2.json", Rel: podops.ResourceTypeImport}
+
+	// the cover of the show is shared by all episodes and checked only once
+	assets := feedAssets(show, []*podops.Episode{e1, e2})
+	if assert.Equal(t, 4, len(assets)) {
+		assert.Equal(t, "https://example.com/cover.png", assets[0].URI)
+		assert.Equal(t, "https://example.com/episode2.json", assets[3].URI)
+	}
+}
@@ -0,0 +1,36 @@
+package apiv1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/api"
+
+	"github.com/podops/podops/feed"
+	"github.com/podops/podops/internal/errordef"
+)
+
+// LintEndpoint checks a production against the Apple Podcasts and RSS 2.0 compliance rules
+func LintEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+
+	if err := AuthorizeAccessProduction(ctx, c, ScopeResourceRead, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	v, err := feed.LintProduction(ctx, prod)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.lint", "production", prod)
+
+	return api.StandardResponse(c, http.StatusOK, feed.NewLintReport(prod, v))
+}
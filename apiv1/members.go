@@ -0,0 +1,163 @@
+package apiv1
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/account"
+	"github.com/txsvc/platform/v2/pkg/api"
+	"github.com/txsvc/platform/v2/pkg/apis/provider"
+	"github.com/txsvc/platform/v2/pkg/authentication"
+	"github.com/txsvc/platform/v2/pkg/validate"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/auth"
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/messagedef"
+)
+
+var (
+	ap provider.AuthenticationProvider
+)
+
+// implements lazy loading to give other parts of the code time to initialize the platform
+// before a first call to the authentication provider is made. This is why init() would not work.
+func authenticator() provider.AuthenticationProvider {
+	if ap != nil {
+		return ap
+	}
+	p, ok := platform.Provider(provider.TypeAuthentication)
+	if !ok {
+		err := fmt.Errorf(platform.MsgMissingProvider, provider.TypeAuthentication.String())
+		platform.ReportError(err)
+		log.Fatal(err) // this halts the process but there is no point because it would just crash later anyways
+	}
+	ap = p.(provider.AuthenticationProvider)
+
+	return ap
+}
+
+// AddMemberEndpoint invites an account to work on a production with a role. Users without an account get a pending invitation.
+func AddMemberEndpoint(c echo.Context) error {
+	var req *podops.Member = new(podops.Member)
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionWrite, req.GUID); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	userID := strings.ToLower(strings.TrimSpace(req.UserID))
+	if !validate.NotEmpty(req.Realm, userID) || !podops.ValidEmail(userID) {
+		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgParameterIsInvalid, userID))
+	}
+	if !backend.ValidRole(req.Role) {
+		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgParameterIsInvalid, req.Role))
+	}
+
+	p, err := backend.GetProduction(ctx, req.GUID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if p == nil {
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchProduction)
+	}
+
+	acc, err := inviteAccount(ctx, req.Realm, userID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	invitedBy := ""
+	token, _ := authentication.GetBearerToken(c.Request())
+	if a, _ := authentication.FindAuthorizationByToken(ctx, token); a != nil {
+		invitedBy = a.UserID
+	}
+
+	clientID := ""
+	if acc != nil {
+		clientID = acc.ClientID
+	}
+	m, err := backend.AddMember(ctx, p.GUID, req.Realm, userID, clientID, req.Role, invitedBy)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if err := auth.InvitationNotification(ctx, m.UserID, invitedBy, p.Title, m.Role); err != nil {
+		platform.ReportError(err) // the membership is valid anyways
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.member.add", "production", p.GUID, "role", m.Role)
+
+	return api.StandardResponse(c, http.StatusCreated, m)
+}
+
+// ListMembersEndpoint returns all members of a production
+func ListMembersEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionRead, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	l, err := backend.ListMembers(ctx, prod)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.member.list", "production", prod)
+
+	return api.StandardResponse(c, http.StatusOK, &podops.MemberList{Members: l})
+}
+
+// RemoveMemberEndpoint revokes the access of a member to a production
+func RemoveMemberEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	userID := strings.ToLower(c.Param("id"))
+	if !validate.NotEmpty(prod, userID) {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionWrite, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	if err := backend.RemoveMember(ctx, prod, userID); err != nil {
+		if err == errordef.ErrNoSuchMember {
+			return api.ErrorResponse(c, http.StatusNotFound, err)
+		}
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.member.remove", "production", prod)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// inviteAccount returns the account of userID, or nil if there is none yet. Invitees without an account
+// get a pending invitation that is bound to their account when they log in for the first time.
+func inviteAccount(ctx context.Context, realm, userID string) (*account.Account, error) {
+	acc, err := account.FindAccountByUserID(ctx, realm, userID)
+	if err != nil {
+		return nil, err
+	}
+	if acc != nil && acc.Status < account.AccountLoggedOut && acc.Status != account.AccountUnconfirmed {
+		return nil, errordef.ErrNoSuchAccount // deactivated or blocked
+	}
+	return acc, nil
+}
@@ -1,6 +1,7 @@
 package apiv1
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -8,11 +9,14 @@ import (
 	"github.com/labstack/echo/v4"
 
 	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/account"
 	"github.com/txsvc/platform/v2/pkg/api"
 	"github.com/txsvc/platform/v2/pkg/authentication"
+	"github.com/txsvc/platform/v2/pkg/validate"
 
 	"github.com/podops/podops"
 	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/errordef"
 	"github.com/podops/podops/internal/messagedef"
 )
 
@@ -36,7 +40,7 @@ func ProductionEndpoint(c echo.Context) error {
 		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgParameterIsInvalid, showName))
 	}
 	// create a new production
-	clientID, _ := authentication.GetClientID(ctx, c.Request())
+	clientID, _ := getClientID(ctx, c.Request())
 	p, err := backend.CreateProduction(ctx, showName, req.Title, req.Summary, clientID)
 	if err != nil {
 		return api.ErrorResponse(c, http.StatusBadRequest, err)
@@ -53,7 +57,7 @@ func ProductionEndpoint(c echo.Context) error {
 	return api.StandardResponse(c, http.StatusCreated, p)
 }
 
-// ListProductionsEndpoint list all available shows
+// ListProductionsEndpoint list all shows the client owns or is a member of
 func ListProductionsEndpoint(c echo.Context) error {
 	ctx := platform.NewHttpContext(c.Request())
 
@@ -61,15 +65,185 @@ func ListProductionsEndpoint(c echo.Context) error {
 		return api.ErrorResponse(c, http.StatusUnauthorized, err)
 	}
 
-	clientID, _ := authentication.GetClientID(ctx, c.Request())
+	clientID, _ := getClientID(ctx, c.Request())
 
-	productions, err := backend.FindProductionsByOwner(ctx, clientID)
+	token, _ := authentication.GetBearerToken(c.Request())
+	if a, _ := authentication.FindAuthorizationByToken(ctx, token); a != nil {
+		// accept invitations sent before the user had an account
+		if err := backend.ClaimInvitations(ctx, a.Realm, a.UserID, a.ClientID); err != nil {
+			return api.ErrorResponse(c, http.StatusBadRequest, err)
+		}
+	}
+
+	owned, err := backend.FindProductionsByOwner(ctx, clientID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// shows of other owners the client is a member of
+	shared, err := backend.FindProductionsByMember(ctx, clientID)
 	if err != nil {
 		return api.ErrorResponse(c, http.StatusBadRequest, err)
 	}
 
+	productions := make([]*podops.Production, 0, len(owned)+len(shared))
+	for _, p := range owned {
+		if p.Deleted == 0 {
+			productions = append(productions, p)
+		}
+	}
+	for _, p := range shared {
+		if p.Owner != clientID && p.Deleted == 0 {
+			productions = append(productions, p)
+		}
+	}
+
 	// track api access for billing etc
 	platform.Meter(ctx, "api.production.list", "owner", clientID)
 
 	return api.StandardResponse(c, http.StatusOK, &podops.ProductionList{Productions: productions})
 }
+
+// RenameProductionEndpoint changes the name of a show. The feed URL of the old name redirects to the new one.
+func RenameProductionEndpoint(c echo.Context) error {
+	var req *podops.ProductionRequest = new(podops.ProductionRequest)
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionWrite, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	if err := AssertWritable(ctx, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusConflict, err)
+	}
+
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	// validate and normalize the name
+	showName := strings.ToLower(strings.TrimSpace(req.Name))
+	if !podops.ValidResourceName(showName) {
+		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgParameterIsInvalid, showName))
+	}
+
+	p, err := backend.RenameProduction(ctx, prod, showName)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.production.rename", "production", prod)
+
+	return api.StandardResponse(c, http.StatusOK, p)
+}
+
+// ArchiveProductionEndpoint makes a show read-only and freezes its feed (POST) or makes it writable again (DELETE)
+func ArchiveProductionEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+	archived := c.Request().Method != "DELETE"
+	action := "api.production.archive"
+	if !archived {
+		action = "api.production.unarchive"
+	}
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionWrite, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	p, err := backend.ArchiveProduction(ctx, prod, archived)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, action, "production", prod)
+
+	return api.StandardResponse(c, http.StatusOK, p)
+}
+
+// TransferProductionEndpoint makes another account the owner of a show
+func TransferProductionEndpoint(c echo.Context) error {
+	var req *podops.ProductionRequest = new(podops.ProductionRequest)
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := AuthorizeOwnerProduction(ctx, c, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !validate.NotEmpty(req.Realm, req.UserID) {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidParameters)
+	}
+
+	acc, err := account.FindAccountByUserID(ctx, req.Realm, req.UserID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if acc == nil || acc.Status < account.AccountLoggedOut {
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchAccount)
+	}
+
+	p, err := backend.TransferProduction(ctx, prod, acc.ClientID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.production.transfer", "production", prod, "owner", acc.ClientID)
+
+	return api.StandardResponse(c, http.StatusOK, p)
+}
+
+// DeleteProductionEndpoint removes a show with all its resources, files and feeds
+func DeleteProductionEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := AuthorizeOwnerProduction(ctx, c, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	if _, err := backend.DeleteProduction(ctx, prod); err != nil {
+		if err == errordef.ErrNoSuchProduction {
+			return api.ErrorResponse(c, http.StatusNotFound, err)
+		}
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.production.delete", "production", prod)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// AssertWritable returns ErrProductionArchived if the production is read-only
+func AssertWritable(ctx context.Context, prod string) error {
+	p, err := backend.GetProduction(ctx, prod)
+	if err != nil {
+		return err
+	}
+	if p != nil && p.Deleted > 0 {
+		return errordef.ErrProductionDeleted
+	}
+	if p != nil && p.Archived {
+		return errordef.ErrProductionArchived
+	}
+	return nil
+}
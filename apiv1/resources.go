@@ -1,6 +1,7 @@
 package apiv1
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"github.com/podops/podops/backend"
 	"github.com/podops/podops/internal/errordef"
 	"github.com/podops/podops/internal/messagedef"
+	"github.com/podops/podops/internal/transport"
 )
 
 // FindResourceEndpoint returns a resource
@@ -37,6 +39,9 @@ func FindResourceEndpoint(c echo.Context) error {
 	if resource == nil {
 		return api.StandardResponse(c, http.StatusNotFound, nil)
 	}
+	if err := setVersion(ctx, c, guid); err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
 
 	// track api access for billing etc
 	platform.Meter(ctx, "api.resource.find", "resource", guid)
@@ -56,18 +61,29 @@ func GetResourceEndpoint(c echo.Context) error {
 		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
 	}
 
-	if err := AuthorizeAccessResource(ctx, c, ScopeResourceRead, guid); err != nil {
+	// the resource might not exist yet, e.g. 'po apply' asks before creating it, i.e. we only validate access to the production
+	if err := AuthorizeAccessProduction(ctx, c, ScopeResourceRead, prod); err != nil {
 		return api.ErrorResponse(c, http.StatusUnauthorized, err)
 	}
 
-	// FIXME prod, kind are ignored, assumption is that guid is globally unique ...
-	resource, err := backend.GetResourceContent(ctx, guid)
+	r, err := backend.GetResource(ctx, guid)
 	if err != nil {
 		return api.ErrorResponse(c, http.StatusBadRequest, err)
 	}
+	if r == nil || r.ParentGUID != prod {
+		// resources of other productions don't exist as far as the caller is concerned
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchResource)
+	}
 
+	resource, err := backend.GetResourceContent(ctx, guid)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
 	if resource == nil {
-		return api.StandardResponse(c, http.StatusNotFound, nil)
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchResource)
+	}
+	if err := setVersion(ctx, c, guid); err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
 	}
 
 	// track api access for billing etc
@@ -137,12 +153,25 @@ func UpdateResourceEndpoint(c echo.Context) error {
 			return api.ErrorResponse(c, http.StatusUnauthorized, err)
 		}
 	}
+	if err := AssertWritable(ctx, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusConflict, err)
+	}
+
+	// reject the update if the resource was changed since the client read it
+	match := ifMatch(c)
+	if ok, err := matchVersion(ctx, guid, match); err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	} else if !ok {
+		return api.ErrorResponse(c, http.StatusPreconditionFailed, errordef.ErrPreconditionFailed)
+	}
 
 	var payload interface{}
+	var show *podops.Show
+	var episode *podops.Episode
 	location := fmt.Sprintf("%s/%s-%s.yaml", prod, kind, guid)
 
 	if kind == podops.ResourceShow {
-		var show *podops.Show = new(podops.Show) // FIXME change this !
+		show = new(podops.Show) // FIXME change this !
 
 		if err := c.Bind(show); err != nil {
 			return api.ErrorResponse(c, http.StatusInternalServerError, err)
@@ -152,32 +181,12 @@ func UpdateResourceEndpoint(c echo.Context) error {
 		if prod != show.GUID() {
 			return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgParameterMismatch, prod, show.GUID()))
 		}
-
-		// update the PRODUCTION entry based on resource
-		p, err := backend.GetProduction(ctx, show.GUID())
-		if err != nil {
-			return api.ErrorResponse(c, http.StatusNotFound, err)
-		}
-
-		// the attributes we copy from the .yaml
-		p.Title = show.Description.Title
-		p.Summary = show.Description.Summary
-		p.Updated = timestamp.Now()
-
-		if err := backend.UpdateProduction(ctx, p); err != nil {
-			return api.ErrorResponse(c, http.StatusBadRequest, err)
-		}
-
-		if err := backend.EnsureAsset(ctx, show.GUID(), &show.Image); err != nil {
-			return api.ErrorResponse(c, http.StatusBadRequest, err)
-		}
-
-		if err := backend.UpdateShow(ctx, location, show); err != nil {
+		if err := ensureShowAssets(ctx, show); err != nil {
 			return api.ErrorResponse(c, http.StatusBadRequest, err)
 		}
 
 	} else if kind == podops.ResourceEpisode {
-		var episode *podops.Episode = new(podops.Episode) // FIXME change this !
+		episode = new(podops.Episode) // FIXME change this !
 
 		if err := c.Bind(episode); err != nil {
 			return api.ErrorResponse(c, http.StatusInternalServerError, err)
@@ -187,25 +196,43 @@ func UpdateResourceEndpoint(c echo.Context) error {
 		if prod != episode.Parent() {
 			return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgParameterMismatch, prod, episode.Parent()))
 		}
-
-		// ensure images and media files
-		if err := backend.EnsureAsset(ctx, episode.Parent(), &episode.Image); err != nil {
+		if err := ensureEpisodeAssets(ctx, episode); err != nil {
 			return api.ErrorResponse(c, http.StatusBadRequest, err)
 		}
+	} else {
+		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgResourceUnsupportedKind, kind))
+	}
 
-		if err := backend.EnsureAsset(ctx, episode.Parent(), &episode.Enclosure); err != nil {
-			return api.ErrorResponse(c, http.StatusBadRequest, err)
+	// every write is kept as a revision of the resource, the version is compared again when the revision is allocated
+	clientID, _ := getClientID(ctx, c.Request())
+	revision, err := backend.WriteRevision(ctx, prod, guid, clientID, location, createFlag, forceFlag, match, payload)
+	if err != nil {
+		if err == errordef.ErrPreconditionFailed {
+			return api.ErrorResponse(c, http.StatusPreconditionFailed, err)
 		}
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	c.Response().Header().Set(transport.HeaderETag, etag(revision.Hash))
 
-		if err := backend.UpdateEpisode(ctx, location, episode); err != nil {
+	// the inventory follows the content that was written
+	if show != nil {
+		if err := updateProduction(ctx, show); err != nil {
+			return api.ErrorResponse(c, http.StatusBadRequest, err)
+		}
+		if err := backend.UpdateShow(ctx, location, show); err != nil {
 			return api.ErrorResponse(c, http.StatusBadRequest, err)
 		}
 	} else {
-		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgResourceUnsupportedKind, kind))
+		if err := backend.UpdateEpisode(ctx, location, episode); err != nil {
+			return api.ErrorResponse(c, http.StatusBadRequest, err)
+		}
 	}
 
-	if err := backend.WriteResourceContent(ctx, location, createFlag, forceFlag, payload); err != nil {
-		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	if kind == podops.ResourceEpisode {
+		// the publish date might have changed
+		if err := backend.UpdateSchedule(ctx, prod); err != nil {
+			platform.ReportError(err)
+		}
 	}
 
 	// track api access for billing etc
@@ -214,7 +241,7 @@ func UpdateResourceEndpoint(c echo.Context) error {
 	return api.StandardResponse(c, http.StatusCreated, nil)
 }
 
-// DeleteResourceEndpoint deletes a resource and its .yaml file
+// DeleteResourceEndpoint moves a resource and its .yaml file to the trash
 // GITHUB_ISSUE #14
 func DeleteResourceEndpoint(c echo.Context) error {
 	ctx := platform.NewHttpContext(c.Request())
@@ -235,13 +262,124 @@ func DeleteResourceEndpoint(c echo.Context) error {
 	if err := AuthorizeAccessResource(ctx, c, ScopeResourceWrite, guid); err != nil {
 		return api.ErrorResponse(c, http.StatusUnauthorized, err)
 	}
+	if err := AssertWritable(ctx, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusConflict, err)
+	}
+
+	if ok, err := matchVersion(ctx, guid, ifMatch(c)); err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	} else if !ok {
+		return api.ErrorResponse(c, http.StatusPreconditionFailed, errordef.ErrPreconditionFailed)
+	}
 
-	if err := backend.DeleteResource(ctx, prod, kind, guid); err != nil {
+	clientID, _ := getClientID(ctx, c.Request())
+	if err := backend.DeleteResource(ctx, prod, kind, guid, clientID); err != nil {
 		return api.ErrorResponse(c, http.StatusBadRequest, err)
 	}
+	if err := backend.UpdateSchedule(ctx, prod); err != nil {
+		platform.ReportError(err)
+	}
 
 	// track api access for billing etc
 	platform.Meter(ctx, "api.resource.delete", "production", prod, "resource", guid, "kind", kind)
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// setVersion adds the current version of a show or episode as ETag to the response
+func setVersion(ctx context.Context, c echo.Context, guid string) error {
+	version, err := backend.ResourceVersion(ctx, guid)
+	if err != nil {
+		return err
+	}
+	if version != "" {
+		c.Response().Header().Set(transport.HeaderETag, etag(version))
+	}
+	return nil
+}
+
+// matchVersion compares the versions of an If-Match header with the current version of a show or episode.
+// Requests without If-Match always match.
+func matchVersion(ctx context.Context, guid string, match []string) (bool, error) {
+	if len(match) == 0 {
+		return true, nil
+	}
+
+	version, err := backend.ResourceVersion(ctx, guid)
+	if err != nil {
+		return false, err
+	}
+	if version == "" {
+		return false, nil // nothing to match against
+	}
+	return backend.MatchVersion(version, match), nil
+}
+
+// ifMatch returns the versions listed in the If-Match header of a request
+func ifMatch(c echo.Context) []string {
+	return transport.ParseETags(c.Request().Header.Get(transport.HeaderIfMatch))
+}
+
+func etag(version string) string {
+	return "\"" + version + "\""
+}
+
+// updateProduction copies the attributes of the show's .yaml to its PRODUCTION entry
+func updateProduction(ctx context.Context, show *podops.Show) error {
+	p, err := backend.GetProduction(ctx, show.GUID())
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return errordef.ErrNoSuchProduction
+	}
+
+	// the attributes we copy from the .yaml
+	p.Title = show.Description.Title
+	p.Summary = show.Description.Summary
+	p.Updated = timestamp.Now()
+
+	return backend.UpdateProduction(ctx, p)
+}
+
+// updateShow ensures the show's assets and updates its inventory entry
+func updateShow(ctx context.Context, location string, show *podops.Show) error {
+	if err := ensureShowAssets(ctx, show); err != nil {
+		return err
+	}
+	return backend.UpdateShow(ctx, location, show)
+}
+
+// updateEpisode ensures the episode's assets and updates its inventory entry
+func updateEpisode(ctx context.Context, location string, episode *podops.Episode) error {
+	if err := ensureEpisodeAssets(ctx, episode); err != nil {
+		return err
+	}
+	return backend.UpdateEpisode(ctx, location, episode)
+}
+
+// ensureShowAssets ensures the show's image
+func ensureShowAssets(ctx context.Context, show *podops.Show) error {
+	return backend.EnsureAsset(ctx, show.GUID(), &show.Image)
+}
+
+// ensureEpisodeAssets ensures the episode's images, media files, transcripts and chapters
+func ensureEpisodeAssets(ctx context.Context, episode *podops.Episode) error {
+	if err := backend.EnsureAsset(ctx, episode.Parent(), &episode.Image); err != nil {
+		return err
+	}
+	if err := backend.EnsureAsset(ctx, episode.Parent(), &episode.Enclosure); err != nil {
+		return err
+	}
+	for i := range episode.Transcripts {
+		if err := backend.EnsureAsset(ctx, episode.Parent(), &episode.Transcripts[i]); err != nil {
+			return err
+		}
+	}
+	if episode.Chapters != nil {
+		if err := backend.EnsureAsset(ctx, episode.Parent(), episode.Chapters); err != nil {
+			return err
+		}
+	}
+	return nil
+}
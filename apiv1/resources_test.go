@@ -0,0 +1,52 @@
+package apiv1
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+)
+
+func TestApplyNewEpisode(t *testing.T) {
+	setupAPI(t)
+	ctx := context.TODO()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	p, err := backend.CreateProduction(ctx, "applied-podcast", "title", "summary", "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+	other, err := backend.CreateProduction(ctx, "other-podcast", "title", "summary", "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+	foreign, err := backend.CreateProduction(ctx, "foreign-podcast", "title", "summary", "someone")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	episode := podops.DefaultEpisode("episode1", p.Name, "episode-guid", p.GUID, podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	episode.Image = podops.Asset{URI: srv.URL + "/episode.png", Rel: podops.ResourceTypeExternal}
+	episode.Enclosure = podops.Asset{URI: srv.URL + "/episode1.mp3", Type: "audio/mpeg", Rel: podops.ResourceTypeExternal}
+	params := []string{"prod", p.GUID, "kind", podops.ResourceEpisode, "id", "episode-guid"}
+
+	// 'po apply' asks for the episode first, a new one is created
+	assertStatus(t, http.StatusNotFound, callEndpoint(GetResourceEndpoint, http.MethodGet, userToken, nil, params...))
+	assertStatus(t, http.StatusCreated, callEndpoint(UpdateResourceEndpoint, http.MethodPost, userToken, episode, params...))
+
+	rec := callEndpoint(GetResourceEndpoint, http.MethodGet, userToken, nil, params...)
+	if assertStatus(t, http.StatusOK, rec) {
+		assert.NotEmpty(t, rec.Header().Get("ETag"))
+	}
+
+	// the episode doesn't belong to another production of the user, and other users' productions are off limits
+	assertStatus(t, http.StatusNotFound, callEndpoint(GetResourceEndpoint, http.MethodGet, userToken, nil, "prod", other.GUID, "kind", podops.ResourceEpisode, "id", "episode-guid"))
+	assertStatus(t, http.StatusUnauthorized, callEndpoint(GetResourceEndpoint, http.MethodGet, userToken, nil, "prod", foreign.GUID, "kind", podops.ResourceEpisode, "id", "episode-guid"))
+}
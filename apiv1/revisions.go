@@ -0,0 +1,174 @@
+package apiv1
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/api"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/loader"
+	"github.com/podops/podops/internal/messagedef"
+	"github.com/podops/podops/internal/transport"
+)
+
+// ListRevisionsEndpoint returns all revisions of a show or episode, latest first
+func ListRevisionsEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	guid := c.Param("id")
+	if guid == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+
+	if err := AuthorizeAccessResource(ctx, c, ScopeResourceRead, guid); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	l, err := backend.ListRevisions(ctx, guid)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.resource.history", "resource", guid)
+
+	return api.StandardResponse(c, http.StatusOK, &podops.RevisionList{Revisions: l})
+}
+
+// DiffRevisionsEndpoint returns the unified diff of two revisions of a show or episode
+func DiffRevisionsEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	guid := c.Param("id")
+	from, err1 := strconv.Atoi(c.Param("from"))
+	to, err2 := strconv.Atoi(c.Param("to"))
+	if guid == "" || err1 != nil || err2 != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+
+	if err := AuthorizeAccessResource(ctx, c, ScopeResourceRead, guid); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	a, status, err := readRevision(c, guid, from)
+	if err != nil {
+		return api.ErrorResponse(c, status, err)
+	}
+	b, status, err := readRevision(c, guid, to)
+	if err != nil {
+		return api.ErrorResponse(c, status, err)
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(a)),
+		B:        difflib.SplitLines(string(b)),
+		FromFile: fmt.Sprintf("%s@%d", guid, from),
+		ToFile:   fmt.Sprintf("%s@%d", guid, to),
+		Context:  3,
+	})
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.resource.diff", "resource", guid)
+
+	return api.StandardResponse(c, http.StatusOK, &podops.RevisionDiff{GUID: guid, From: from, To: to, Diff: diff})
+}
+
+// RestoreRevisionEndpoint writes the content of an earlier revision back to a show or episode. The result is a new revision.
+func RestoreRevisionEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	guid := c.Param("id")
+	rev, err := strconv.Atoi(c.Param("rev"))
+	if guid == "" || err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+
+	if err := AuthorizeAccessResource(ctx, c, ScopeResourceWrite, guid); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	r, err := backend.GetResource(ctx, guid)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if r == nil {
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchResource)
+	}
+	if err := AssertWritable(ctx, r.ParentGUID); err != nil {
+		return api.ErrorResponse(c, http.StatusConflict, err)
+	}
+
+	data, status, err := readRevision(c, guid, rev)
+	if err != nil {
+		return api.ErrorResponse(c, status, err)
+	}
+	rsrc, kind, _, err := loader.UnmarshalResource(data)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// the inventory has to match the restored content
+	switch kind {
+	case podops.ResourceShow:
+		show := rsrc.(*podops.Show)
+		if err := updateProduction(ctx, show); err != nil {
+			return api.ErrorResponse(c, http.StatusBadRequest, err)
+		}
+		if err := updateShow(ctx, r.Location, show); err != nil {
+			return api.ErrorResponse(c, http.StatusBadRequest, err)
+		}
+	case podops.ResourceEpisode:
+		if err := updateEpisode(ctx, r.Location, rsrc.(*podops.Episode)); err != nil {
+			return api.ErrorResponse(c, http.StatusBadRequest, err)
+		}
+	default:
+		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgResourceUnsupportedKind, kind))
+	}
+
+	clientID, _ := getClientID(ctx, c.Request())
+	revision, err := backend.WriteRevision(ctx, r.ParentGUID, guid, clientID, r.Location, false, true, nil, rsrc)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	c.Response().Header().Set(transport.HeaderETag, etag(revision.Hash))
+
+	if kind == podops.ResourceEpisode {
+		// the publish date might have changed
+		if err := backend.UpdateSchedule(ctx, r.ParentGUID); err != nil {
+			platform.ReportError(err)
+		}
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.resource.restore", "production", r.ParentGUID, "resource", guid, "revision", strconv.Itoa(rev))
+
+	return api.StandardResponse(c, http.StatusCreated, revision)
+}
+
+// readRevision returns the content of a revision and a http status in case of an error
+func readRevision(c echo.Context, guid string, rev int) ([]byte, int, error) {
+	ctx := platform.NewHttpContext(c.Request())
+
+	r, err := backend.GetRevision(ctx, guid, rev)
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if r == nil {
+		return nil, http.StatusNotFound, errordef.ErrNoSuchRevision
+	}
+	data, err := backend.ReadRevisionContent(ctx, r)
+	if err != nil {
+		return nil, http.StatusNotFound, err
+	}
+	return data, http.StatusOK, nil
+}
@@ -0,0 +1,87 @@
+package apiv1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/api"
+	"github.com/txsvc/platform/v2/pkg/authentication"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/errordef"
+)
+
+// ScheduleEndpoint returns the upcoming episodes of a production
+func ScheduleEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+
+	if err := AuthorizeAccessProduction(ctx, c, ScopeResourceRead, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	l, err := backend.ListScheduledEpisodes(ctx, prod, timestamp.Now())
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.schedule", "production", prod)
+
+	return api.StandardResponse(c, http.StatusOK, &podops.ResourceList{Resources: l})
+}
+
+// ScheduleTaskEndpoint is called periodically and queues a build for every production with an episode that went live since its last build.
+// It also fails builds that exceeded backend.BuildTimeout.
+// Like all tasks, the scheduler has to authenticate with the API key, see 'make scheduler'.
+func ScheduleTaskEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := AuthorizeAccess(ctx, c, authentication.ScopeAPIAdmin); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	// builds whose worker died never finish on their own
+	reaped, err := backend.ReapBuilds(ctx, timestamp.Now())
+	if err != nil {
+		platform.ReportError(err)
+	}
+	for _, b := range reaped {
+		backend.Emit(ctx, b.GUID, podops.EventBuildFailed, b.ID, b)
+	}
+
+	due, err := backend.ListDueProductions(ctx, timestamp.Now())
+	if err != nil {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	for _, p := range due {
+		b, err := backend.CreateBuild(ctx, p.GUID, false, false)
+		if err != nil {
+			platform.ReportError(err)
+			continue // try again with the next tick
+		}
+		if err := queueBuild(ctx, b); err != nil {
+			platform.ReportError(err)
+			continue // the build is reaped, the next tick records a new one
+		}
+
+		// the build is on its way, move on to the next scheduled episode, if any
+		if err := backend.UpdateSchedule(ctx, p.GUID); err != nil {
+			platform.ReportError(err)
+		}
+
+		// track api access for billing etc
+		platform.Meter(ctx, "api.schedule.build", "production", p.GUID)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,56 @@
+package apiv1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+)
+
+func TestScheduleTaskEndpoint(t *testing.T) {
+	queue := setupAPI(t)
+	ctx := context.TODO()
+	os.Setenv("PODOPS_API_KEY", adminToken)
+	defer os.Unsetenv("PODOPS_API_KEY")
+
+	p, err := backend.CreateProduction(ctx, "scheduled-podcast", "title", "summary", "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+	p.NextPublishDate = timestamp.Now() - 1
+	if !assert.NoError(t, backend.UpdateProduction(ctx, p)) {
+		return
+	}
+
+	// the schedule stays put if the build can't be queued
+	queue.err = errors.New("queue is down")
+	assertStatus(t, http.StatusOK, callEndpoint(ScheduleTaskEndpoint, http.MethodGet, adminToken, nil))
+	p, _ = backend.GetProduction(ctx, p.GUID)
+	assert.NotZero(t, p.NextPublishDate)
+
+	queue.err = nil
+	assertStatus(t, http.StatusOK, callEndpoint(ScheduleTaskEndpoint, http.MethodGet, adminToken, nil))
+	if !assert.Equal(t, 1, len(queue.tasks)) {
+		return
+	}
+	task := queue.tasks[0]
+	assert.Equal(t, buildJobEndpoint, task.Request)
+	p, _ = backend.GetProduction(ctx, p.GUID)
+	assert.Zero(t, p.NextPublishDate)
+
+	// the worker runs the build with nothing but the admin token
+	req := task.Payload.(*podops.BuildRequest)
+	assertStatus(t, http.StatusOK, callEndpoint(BuildTaskEndpoint, http.MethodPost, task.Token, req))
+	b, err := backend.GetBuild(ctx, p.GUID, req.ID)
+	if assert.NoError(t, err) && assert.NotNil(t, b) {
+		assert.NotEqual(t, podops.BuildQueued, b.State)
+		assert.NotZero(t, b.Finished)
+	}
+}
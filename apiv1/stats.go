@@ -0,0 +1,75 @@
+package apiv1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/api"
+	"github.com/txsvc/platform/v2/pkg/authentication"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/analytics"
+	"github.com/podops/podops/internal/errordef"
+)
+
+const (
+	// the default period of the statistics
+	defaultStatsDays = 30
+)
+
+// StatsEndpoint returns the daily downloads of a production or one of its episodes
+func StatsEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionRead, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	days := defaultStatsDays
+	if d := c.QueryParam("days"); d != "" {
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 1 {
+			return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidParameters)
+		}
+		days = n
+	}
+	l, err := backend.ListStats(ctx, prod, c.QueryParam("episode"), analytics.Since(days))
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.stats", "production", prod)
+
+	return api.StandardResponse(c, http.StatusOK, &podops.DownloadStatsList{Stats: l})
+}
+
+// StatsTaskEndpoint is called periodically. It ranks the productions by their downloads and removes the
+// de-duplication state of listeners that is no longer needed.
+// Like all tasks, the scheduler has to authenticate with the API key, see 'make scheduler'.
+func StatsTaskEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := AuthorizeAccess(ctx, c, authentication.ScopeAPIAdmin); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	if err := backend.RollupPopularity(ctx, timestamp.Now()); err != nil {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+	if _, err := backend.PurgeListeners(ctx, timestamp.Now()); err != nil {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
@@ -0,0 +1,83 @@
+package apiv1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/api"
+	"github.com/txsvc/platform/v2/pkg/validate"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/errordef"
+)
+
+// CreateSubscriptionEndpoint issues a new subscriber token and returns the subscriber's private feed
+func CreateSubscriptionEndpoint(c echo.Context) error {
+	var req *podops.Subscription = new(podops.Subscription)
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionWrite, req.GUID); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	s, err := backend.CreateSubscription(ctx, req.GUID, req.Name)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.subscription.create", "production", req.GUID)
+
+	return api.StandardResponse(c, http.StatusCreated, s)
+}
+
+// ListSubscriptionsEndpoint returns all subscribers of a production
+func ListSubscriptionsEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionRead, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	l, err := backend.ListSubscriptions(ctx, prod)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.subscription.list", "production", prod)
+
+	return api.StandardResponse(c, http.StatusOK, &podops.SubscriptionList{Subscriptions: l})
+}
+
+// RevokeSubscriptionEndpoint deletes a subscriber token
+func RevokeSubscriptionEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	token := c.Param("token")
+	if !validate.NotEmpty(prod, token) {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionWrite, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	if err := backend.RevokeSubscription(ctx, prod, token); err != nil {
+		return api.ErrorResponse(c, http.StatusNotFound, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.subscription.revoke", "production", prod)
+
+	return c.NoContent(http.StatusNoContent)
+}
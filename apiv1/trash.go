@@ -0,0 +1,181 @@
+package apiv1
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/api"
+	"github.com/txsvc/platform/v2/pkg/authentication"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+	"github.com/txsvc/platform/v2/pkg/validate"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/errordef"
+)
+
+// ListTrashEndpoint returns the deleted resources of a production
+func ListTrashEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeResourceRead, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	l, err := backend.ListTrash(ctx, prod)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// a deleted production is listed with its resources, restoring it restores everything
+	p, err := backend.GetProduction(ctx, prod)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if p != nil && p.Deleted > 0 {
+		l = append([]*podops.TrashItem{productionTrashItem(p)}, l...)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.trash.list", "production", prod)
+
+	return api.StandardResponse(c, http.StatusOK, &podops.TrashList{Items: l})
+}
+
+// UndeleteResourceEndpoint restores a deleted resource from the trash
+func UndeleteResourceEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	guid := c.Param("id")
+	if !validate.NotEmpty(prod, guid) {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	// the resource is gone, i.e. we can only validate access to the production
+	if err := AuthorizeAccessProduction(ctx, c, ScopeResourceWrite, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	if guid == prod {
+		p, err := backend.GetProduction(ctx, prod)
+		if err != nil {
+			return api.ErrorResponse(c, http.StatusBadRequest, err)
+		}
+		if p != nil && p.Deleted > 0 {
+			return restoreProduction(c, p)
+		}
+	}
+
+	if err := AssertWritable(ctx, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusConflict, err)
+	}
+
+	t, err := backend.GetTrashItem(ctx, guid)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if t == nil || t.ParentGUID != prod {
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNotInTrash)
+	}
+
+	r, err := backend.UndeleteResource(ctx, guid)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if r.Kind == podops.ResourceEpisode {
+		// the episode might be scheduled
+		if err := backend.UpdateSchedule(ctx, prod); err != nil {
+			platform.ReportError(err)
+		}
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.trash.undelete", "production", prod, "resource", guid, "kind", r.Kind)
+
+	return api.StandardResponse(c, http.StatusOK, r)
+}
+
+// PurgeTrashTaskEndpoint is called periodically and removes all resources whose retention period in the trash has ended.
+// Like all tasks, the scheduler has to authenticate with the API key, see 'make scheduler'.
+func PurgeTrashTaskEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := AuthorizeAccess(ctx, c, authentication.ScopeAPIAdmin); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	expired, err := backend.ListExpiredTrash(ctx, timestamp.Now())
+	if err != nil {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	for _, t := range expired {
+		if err := backend.PurgeResource(ctx, t.GUID); err != nil {
+			platform.ReportError(err)
+			continue // try again with the next tick
+		}
+
+		// track api access for billing etc
+		platform.Meter(ctx, "api.trash.purge", "production", t.ParentGUID, "resource", t.GUID, "kind", t.Kind)
+	}
+
+	productions, err := backend.ListExpiredProductions(ctx, timestamp.Now())
+	if err != nil {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusInternalServerError)
+	}
+
+	for _, p := range productions {
+		if err := backend.PurgeProduction(ctx, p.GUID); err != nil {
+			platform.ReportError(err)
+			continue // try again with the next tick
+		}
+
+		// track api access for billing etc
+		platform.Meter(ctx, "api.trash.purge", "production", p.GUID, "kind", podops.ResourceProduction)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// restoreProduction takes a deleted production out of the trash
+func restoreProduction(c echo.Context, p *podops.Production) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := AuthorizeOwnerProduction(ctx, c, p.GUID); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	p, err := backend.RestoreProduction(ctx, p.GUID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.trash.undelete", "production", p.GUID, "kind", podops.ResourceProduction)
+
+	return api.StandardResponse(c, http.StatusOK, &podops.Resource{
+		Name:       p.Name,
+		GUID:       p.GUID,
+		Kind:       podops.ResourceProduction,
+		ParentGUID: p.GUID,
+	})
+}
+
+// productionTrashItem lists a deleted production in the trash
+func productionTrashItem(p *podops.Production) *podops.TrashItem {
+	return &podops.TrashItem{
+		GUID:       p.GUID,
+		ParentGUID: p.GUID,
+		Kind:       podops.ResourceProduction,
+		Name:       p.Name,
+		Deleted:    p.Deleted,
+		Expires:    p.Expires,
+	}
+}
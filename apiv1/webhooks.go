@@ -0,0 +1,154 @@
+package apiv1
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/api"
+	"github.com/txsvc/platform/v2/pkg/authentication"
+	"github.com/txsvc/platform/v2/pkg/validate"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/errordef"
+)
+
+// CreateWebhookEndpoint subscribes a URL to the events of a production. The response includes the secret that signs the payloads.
+func CreateWebhookEndpoint(c echo.Context) error {
+	var req *podops.Webhook = new(podops.Webhook)
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := c.Bind(req); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionWrite, req.GUID); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	w, err := backend.CreateWebhook(ctx, req.GUID, req.URL, req.Events)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.webhook.create", "production", req.GUID)
+
+	return api.StandardResponse(c, http.StatusCreated, w)
+}
+
+// ListWebhooksEndpoint returns all webhooks of a production, without their secrets
+func ListWebhooksEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionRead, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	l, err := backend.ListWebhooks(ctx, prod)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+	for _, w := range l {
+		w.Secret = ""
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.webhook.list", "production", prod)
+
+	return api.StandardResponse(c, http.StatusOK, &podops.WebhookList{Webhooks: l})
+}
+
+// DeleteWebhookEndpoint removes a webhook
+func DeleteWebhookEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	webhookID := c.Param("id")
+	if !validate.NotEmpty(prod, webhookID) {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionWrite, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	if err := backend.DeleteWebhook(ctx, prod, webhookID); err != nil {
+		if err == errordef.ErrNoSuchWebhook {
+			return api.ErrorResponse(c, http.StatusNotFound, err)
+		}
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.webhook.delete", "production", prod)
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListDeliveriesEndpoint returns the most recent attempts to deliver events of a production to its webhooks
+func ListDeliveriesEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := AuthorizeAccessProduction(ctx, c, ScopeProductionRead, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	l, err := backend.ListDeliveries(ctx, prod, backend.DefaultDeliveryHistory)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.webhook.deliveries", "production", prod)
+
+	return api.StandardResponse(c, http.StatusOK, &podops.WebhookDeliveryList{Deliveries: l})
+}
+
+// DeliverTaskEndpoint delivers a queued event to a webhook. Failed deliveries are retried by the task queue
+// until MaxDeliveryAttempts is reached.
+func DeliverTaskEndpoint(c echo.Context) error {
+	var req *podops.WebhookDeliveryRequest = new(podops.WebhookDeliveryRequest)
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := c.Bind(req); err != nil {
+		// just report and return, resending will not change anything
+		platform.ReportError(err)
+		return c.NoContent(http.StatusOK)
+	}
+	if err := AuthorizeAccess(ctx, c, authentication.ScopeAPIAdmin); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	if req.WebhookID == "" || req.Event == nil {
+		return c.NoContent(http.StatusOK)
+	}
+
+	// Cloud Tasks counts the retries, the first attempt is retry 0
+	attempt := 1
+	if n, err := strconv.Atoi(c.Request().Header.Get("X-CloudTasks-TaskRetryCount")); err == nil {
+		attempt = n + 1
+	}
+
+	d, err := backend.DeliverEvent(ctx, req, attempt)
+	if err != nil {
+		if d == nil {
+			platform.ReportError(err)
+		}
+		if attempt < backend.MaxDeliveryAttempts {
+			return c.NoContent(http.StatusServiceUnavailable) // the task will be retried
+		}
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.webhook.deliver", "production", req.Event.GUID, "event", req.Event.Event)
+
+	return c.NoContent(http.StatusOK)
+}
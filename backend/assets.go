@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"net/url"
+	"time"
 
 	"github.com/txsvc/platform/v2"
 	"github.com/txsvc/platform/v2/pkg/apis/provider"
@@ -18,8 +19,16 @@ import (
 	"github.com/podops/podops/internal/transport"
 )
 
+const (
+	// assetTimeout limits how long a HEAD request to an asset may take
+	assetTimeout = 10 * time.Second
+)
+
 var (
 	tp provider.HttpTaskProvider
+
+	// assetClient checks assets at their origin or on the CDN
+	assetClient = &http.Client{Timeout: assetTimeout}
 )
 
 // implements lazy loading to give other parts of the code time to initialize the platform
@@ -51,10 +60,13 @@ func UpdateAsset(ctx context.Context, meta *metadata.Metadata, production, locat
 		r.Location = location
 		r.Updated = timestamp.Now()
 
-		if meta.IsImage() {
+		switch meta.MediaClass() {
+		case metadata.MediaClassImage:
 			r.ImageURI = fmt.Sprintf("%s/%s", podops.DefaultStorageEndpoint, location)
 			r.ImageRel = rel
-		} else {
+		case metadata.MediaClassText:
+			// transcripts and chapters are only tracked by their location
+		default:
 			r.EnclosureURI = fmt.Sprintf("%s/%s", podops.DefaultStorageEndpoint, location)
 			r.EnclosureRel = rel
 		}
@@ -77,10 +89,12 @@ func UpdateAsset(ctx context.Context, meta *metadata.Metadata, production, locat
 		Updated:    now,
 	}
 
-	if meta.IsImage() {
+	switch meta.MediaClass() {
+	case metadata.MediaClassImage:
 		rsrc.ImageURI = fmt.Sprintf("%s/%s", podops.DefaultStorageEndpoint, location)
 		rsrc.ImageRel = rel
-	} else {
+	case metadata.MediaClassText:
+	default:
 		rsrc.EnclosureURI = fmt.Sprintf("%s/%s", podops.DefaultStorageEndpoint, location)
 		rsrc.EnclosureRel = rel
 	}
@@ -107,8 +121,27 @@ func RemoveAsset(ctx context.Context, prod, location string) error {
 	return err
 }
 
+// RemoveProductionAssets removes all files of a production from the CDN
+func RemoveProductionAssets(ctx context.Context, prod string) error {
+	task := provider.HttpTask{
+		Method:  provider.HttpMethodDelete,
+		Request: fmt.Sprintf("%s/%s", purgeTaskEndpoint, prod),
+		Token:   env.GetString("PODOPS_API_KEY", ""),
+		Payload: nil,
+	}
+	return background().CreateHttpTask(ctx, task)
+}
+
 // EnsureAsset validates the existence of the asset and imports it if necessary
 func EnsureAsset(ctx context.Context, production string, rsrc *podops.Asset) error {
+	if err := CheckAsset(ctx, production, rsrc); err != nil {
+		return err
+	}
+	return QueueImport(ctx, production, rsrc)
+}
+
+// CheckAsset validates the existence of the asset at its origin or on the CDN
+func CheckAsset(ctx context.Context, production string, rsrc *podops.Asset) error {
 	if rsrc.Rel == podops.ResourceTypeExternal {
 		_, err := pingURL(rsrc.URI)
 		return err
@@ -117,60 +150,69 @@ func EnsureAsset(ctx context.Context, production string, rsrc *podops.Asset) err
 		// FIXME replace later with checking of the ResourceMetadata entries ...
 		path := fmt.Sprintf("%s/%s/%s", podops.DefaultStorageEndpoint, production, rsrc.URI)
 		_, err := pingURL(path) // ping the CDN
-		if err != nil {
-			return err
-		}
-		return nil
+		return err
 	}
 	if rsrc.Rel == podops.ResourceTypeImport {
 		_, err := pingURL(rsrc.URI) // ping the URL already here to avoid queueing a request that will fail later anyways
-		if err != nil {
-			return err
-		}
+		return err
+	}
+	return nil
+}
 
-		// FIXME compare to ResourceMetadata first ...
+// QueueImport dispatches a request for the background import of an asset into the CDN. Assets that are not imported are ignored.
+func QueueImport(ctx context.Context, production string, rsrc *podops.Asset) error {
+	if rsrc.Rel != podops.ResourceTypeImport {
+		return nil
+	}
 
-		// dispatch a request for background import
-		ir := podops.SyncRequest{
-			GUID:   production,
-			Source: rsrc.URI,
-		}
+	// FIXME compare to ResourceMetadata first ...
 
-		task := provider.HttpTask{
-			Method:  provider.HttpMethodPost,
-			Request: importTaskEndpoint,
-			Token:   env.GetString("PODOPS_API_KEY", ""),
-			Payload: &ir,
-		}
+	ir := podops.SyncRequest{
+		GUID:   production,
+		Source: rsrc.URI,
+	}
 
-		err = background().CreateHttpTask(ctx, task)
-		if err != nil {
-			return err
-		}
+	task := provider.HttpTask{
+		Method:  provider.HttpMethodPost,
+		Request: importTaskEndpoint,
+		Token:   env.GetString("PODOPS_API_KEY", ""),
+		Payload: &ir,
 	}
-	return nil
+	return background().CreateHttpTask(ctx, task)
 }
 
 // pingURL tries a HEAD or GET request to verify that 'url' exists and is reachable
 func pingURL(url string) (http.Header, error) {
+	status, header, err := HeadURL(url)
+	if err != nil {
+		return nil, err
+	}
+	if !Reachable(status) {
+		return nil, fmt.Errorf(messagedef.MsgResourceIsInvalid, url)
+	}
+	return header, nil
+}
+
+// Reachable returns true if a request was answered with a 2xx status code. Redirects are followed by HeadURL,
+// anything else, e.g. 401 or 403 of an asset that requires a token, means the asset can't be downloaded.
+func Reachable(status int) bool {
+	return status >= http.StatusOK && status < http.StatusMultipleChoices
+}
+
+// HeadURL sends a HEAD request to 'url' and returns the status code and headers of the response
+func HeadURL(url string) (int, http.Header, error) {
 
 	req, err := http.NewRequest("HEAD", url, nil)
 	if err != nil {
-		return nil, err
+		return 0, nil, err
 	}
 	req.Header.Set("User-Agent", transport.UserAgentString)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := assetClient.Do(req)
 	if err != nil {
-		return nil, err
+		return 0, nil, err
 	}
-	if resp != nil {
-		defer resp.Body.Close()
-		// anything other than OK, Created, Accepted, NoContent is treated as an error
-		if resp.StatusCode > http.StatusNoContent {
-			return nil, fmt.Errorf(messagedef.MsgResourceIsInvalid, url)
-		}
-	}
-	return resp.Header.Clone(), nil
+	defer resp.Body.Close()
+
+	return resp.StatusCode, resp.Header.Clone(), nil
 }
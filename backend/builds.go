@@ -0,0 +1,160 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/txsvc/platform/v2/pkg/id"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+const (
+	// DatastoreBuilds collection BUILDS
+	datastoreBuilds = "BUILDS"
+
+	// DefaultBuildHistory is the number of builds returned by ListBuilds if no limit is given
+	DefaultBuildHistory = 20
+
+	// BuildTimeout is the time a build may run before it is considered failed
+	BuildTimeout = 10 * time.Minute
+)
+
+// CreateBuild queues a new build of a production's feed. Strict builds verify all assets before publishing the feed.
+func CreateBuild(ctx context.Context, production string, validateOnly, strict bool) (*podops.Build, error) {
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	p, err := GetProduction(ctx, production)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errordef.ErrNoSuchProduction
+	}
+
+	buildID, err := id.ShortUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	b := podops.Build{
+		ID:           buildID,
+		GUID:         production,
+		State:        podops.BuildQueued,
+		ValidateOnly: validateOnly,
+		Strict:       strict,
+		Issues:       make([]podops.LintIssue, 0),
+		FeedURL:      fmt.Sprintf("%s/%s/feed.xml", podops.DefaultStorageEndpoint, production),
+		FeedAliasURL: FeedAliasURL(p.Name),
+		Created:      timestamp.Now(),
+	}
+	if err := DefaultRepository().Put(ctx, datastoreBuilds, buildID, &b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// GetBuild returns a build of a production, or nil if it does not exist
+func GetBuild(ctx context.Context, production, buildID string) (*podops.Build, error) {
+	var b podops.Build
+
+	if err := DefaultRepository().Get(ctx, datastoreBuilds, buildID, &b); err != nil {
+		if err == errordef.ErrNoSuchEntity {
+			return nil, nil // not found is not an error
+		}
+		return nil, err
+	}
+	if b.GUID != production {
+		return nil, nil
+	}
+	return &b, nil
+}
+
+// UpdateBuild persists the state of a build
+func UpdateBuild(ctx context.Context, b *podops.Build) error {
+	return DefaultRepository().Put(ctx, datastoreBuilds, b.ID, b)
+}
+
+// ListBuilds returns the most recent builds of a production, latest first
+func ListBuilds(ctx context.Context, production string, limit int) ([]*podops.Build, error) {
+	var builds []*podops.Build
+
+	if limit <= 0 {
+		limit = DefaultBuildHistory
+	}
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreBuilds).Filter("GUID =", production).Order("-Created").Limit(limit), &builds); err != nil {
+		return nil, err
+	}
+	return builds, nil
+}
+
+// ReapBuilds marks builds that are running for longer than BuildTimeout as failed and returns them.
+// Their worker crashed or was stopped before it could record the outcome.
+func ReapBuilds(ctx context.Context, now int64) ([]*podops.Build, error) {
+	var builds []*podops.Build
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreBuilds).Filter("State =", podops.BuildRunning).Filter("Started <", now-int64(BuildTimeout.Seconds())), &builds); err != nil {
+		return nil, err
+	}
+	for _, b := range builds {
+		b.State = podops.BuildFailed
+		b.Message = errordef.ErrBuildTimeout.Error()
+		b.Finished = now
+		if err := UpdateBuild(ctx, b); err != nil {
+			return nil, err
+		}
+	}
+	return builds, nil
+}
+
+// BuildArtifactLocation returns the location of the feed.xml generated by a build in the production bucket
+func BuildArtifactLocation(production, buildID string) string {
+	return fmt.Sprintf("%s/builds/%s.xml", production, buildID)
+}
+
+// PrivateArtifactLocation returns the location of the private feed generated by a build in the production bucket
+func PrivateArtifactLocation(production, buildID string) string {
+	return fmt.Sprintf("%s/builds/%s-private.xml", production, buildID)
+}
+
+// FindRollbackTarget returns the build that was published before the currently live feed, or nil if there is none
+func FindRollbackTarget(ctx context.Context, production string) (*podops.Build, error) {
+	latest, err := listPublishedBuilds(ctx, production, false, 0, 1)
+	if err != nil || len(latest) == 0 {
+		return nil, err
+	}
+
+	live := latest[0]
+	if live.RollbackOf != "" {
+		// the live feed is the one of the build that was rolled back to
+		if live, err = GetBuild(ctx, production, live.RollbackOf); err != nil || live == nil {
+			return nil, err
+		}
+	}
+
+	before, err := listPublishedBuilds(ctx, production, true, live.Created, 1)
+	if err != nil || len(before) == 0 {
+		return nil, err
+	}
+	return before[0], nil
+}
+
+// listPublishedBuilds returns the most recent builds that published a feed, latest first.
+// With buildsOnly, rollbacks are excluded and only builds created before the given time are returned.
+func listPublishedBuilds(ctx context.Context, production string, buildsOnly bool, before int64, limit int) ([]*podops.Build, error) {
+	var builds []*podops.Build
+
+	q := NewQuery(datastoreBuilds).Filter("GUID =", production).Filter("State =", podops.BuildSucceeded).Filter("ValidateOnly =", false)
+	if buildsOnly {
+		q = q.Filter("RollbackOf =", "").Filter("Created <", before)
+	}
+	if err := DefaultRepository().GetAll(ctx, q.Order("-Created").Limit(limit), &builds); err != nil {
+		return nil, err
+	}
+	return builds, nil
+}
@@ -0,0 +1,125 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+func TestLocalBuilds(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	p, err := CreateProduction(ctx, "built-podcast", "title", "summary", "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = CreateBuild(ctx, "unknown", false, false)
+	assert.Equal(t, errordef.ErrNoSuchProduction, err)
+
+	b1, err := CreateBuild(ctx, p.GUID, false, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, podops.BuildQueued, b1.State)
+
+	b2, err := CreateBuild(ctx, p.GUID, true, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	b2.Created = b1.Created + 1
+	b2.State = podops.BuildFailed
+	b2.Issues = append(b2.Issues, podops.LintIssue{Rule: "schema", Severity: podops.SeverityError, Message: "no published episodes"})
+	assert.NoError(t, UpdateBuild(ctx, b2))
+
+	b, err := GetBuild(ctx, p.GUID, b2.ID)
+	if assert.NoError(t, err) && assert.NotNil(t, b) {
+		assert.Equal(t, podops.BuildFailed, b.State)
+		assert.Equal(t, 1, len(b.Issues))
+	}
+	b, err = GetBuild(ctx, "other", b2.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, b)
+
+	l, err := ListBuilds(ctx, p.GUID, 0)
+	if assert.NoError(t, err) && assert.Equal(t, 2, len(l)) {
+		assert.Equal(t, b2.ID, l[0].ID)
+		assert.Equal(t, b1.ID, l[1].ID)
+	}
+
+	// a build whose worker died is failed once it exceeds the timeout
+	b1.State = podops.BuildRunning
+	b1.Started = b1.Created
+	assert.NoError(t, UpdateBuild(ctx, b1))
+	reaped, err := ReapBuilds(ctx, b1.Started+60)
+	if assert.NoError(t, err) {
+		assert.Empty(t, reaped)
+	}
+	reaped, err = ReapBuilds(ctx, b1.Started+int64(BuildTimeout.Seconds())+1)
+	if assert.NoError(t, err) && assert.Equal(t, 1, len(reaped)) {
+		assert.Equal(t, b1.ID, reaped[0].ID)
+	}
+	b, err = GetBuild(ctx, p.GUID, b1.ID)
+	if assert.NoError(t, err) && assert.NotNil(t, b) {
+		assert.Equal(t, podops.BuildFailed, b.State)
+		assert.Equal(t, errordef.ErrBuildTimeout.Error(), b.Message)
+	}
+}
+
+func TestLocalRollbackTarget(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	p, err := CreateProduction(ctx, "rollback-podcast", "title", "summary", "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// two published builds, a failed one and a validation
+	builds := make([]*podops.Build, 4)
+	states := []string{podops.BuildSucceeded, podops.BuildSucceeded, podops.BuildFailed, podops.BuildSucceeded}
+	for i := range builds {
+		b, err := CreateBuild(ctx, p.GUID, i == 3, false)
+		if !assert.NoError(t, err) {
+			return
+		}
+		b.Created = int64(i + 1)
+		b.State = states[i]
+		assert.NoError(t, UpdateBuild(ctx, b))
+		builds[i] = b
+	}
+
+	// many validations since do not hide the published builds
+	for i := 0; i < DefaultBuildHistory+5; i++ {
+		v, err := CreateBuild(ctx, p.GUID, true, false)
+		if assert.NoError(t, err) {
+			v.Created = int64(10 + i)
+			v.State = podops.BuildSucceeded
+			assert.NoError(t, UpdateBuild(ctx, v))
+		}
+	}
+
+	target, err := FindRollbackTarget(ctx, p.GUID)
+	if assert.NoError(t, err) && assert.NotNil(t, target) {
+		assert.Equal(t, builds[0].ID, target.ID)
+	}
+
+	// after rolling back to the first build, there is nothing older to go back to
+	r, err := CreateBuild(ctx, p.GUID, false, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	r.Created = 5
+	r.State = podops.BuildSucceeded
+	r.RollbackOf = builds[0].ID
+	assert.NoError(t, UpdateBuild(ctx, r))
+
+	target, err = FindRollbackTarget(ctx, p.GUID)
+	assert.NoError(t, err)
+	assert.Nil(t, target)
+}
@@ -0,0 +1,165 @@
+package backend
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/podops/podops/internal/errordef"
+)
+
+var (
+	// networks that callbacks must never reach, in addition to loopback, link-local and multicast addresses
+	privateNetworks = mustParseCIDRs(
+		"0.0.0.0/8",
+		"10.0.0.0/8",
+		"100.64.0.0/10",
+		"172.16.0.0/12",
+		"192.168.0.0/16",
+		"198.18.0.0/15",
+		"fc00::/7",
+	)
+
+	// the cloud metadata services
+	metadataHosts = []string{
+		"metadata",
+		"metadata.google.internal",
+		"instance-data",
+	}
+
+	// allowPrivateCallbacks disables the checks of ValidateCallback, in tests only
+	allowPrivateCallbacks = false
+
+	// callbackClient sends requests to URLs registered by users. It never follows redirects and
+	// refuses to connect to private addresses, even if the DNS of the host changed since it was validated.
+	callbackClient = &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: publicTransport(),
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+)
+
+// ValidateCallback verifies that uri is a https URL of a public host. URLs of loopback,
+// link-local, private or cloud metadata hosts are rejected with ErrPrivateCallback.
+func ValidateCallback(ctx context.Context, uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return errordef.ErrInvalidParameters
+	}
+	if allowPrivateCallbacks {
+		return nil
+	}
+	if u.Scheme != "https" {
+		return errordef.ErrPrivateCallback
+	}
+	if ok, err := publicHost(ctx, u.Hostname()); err != nil {
+		return err
+	} else if !ok {
+		return errordef.ErrPrivateCallback
+	}
+	return nil
+}
+
+// ValidatePublicURL verifies that uri is a http(s) URL of a public host, e.g. a feed that is imported.
+// URLs of loopback, link-local, private or cloud metadata hosts are rejected with ErrPrivateURL.
+func ValidatePublicURL(ctx context.Context, uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return errordef.ErrInvalidParameters
+	}
+	if allowPrivateCallbacks {
+		return nil
+	}
+	if ok, err := publicHost(ctx, u.Hostname()); err != nil {
+		return err
+	} else if !ok {
+		return errordef.ErrPrivateURL
+	}
+	return nil
+}
+
+// NewPublicClient returns a client for URLs submitted by users. It refuses to connect to private addresses,
+// also when following redirects.
+func NewPublicClient(timeout time.Duration) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: publicTransport(),
+	}
+}
+
+// publicTransport only dials public addresses, even if the DNS of a host changed since it was validated
+func publicTransport() *http.Transport {
+	return &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: checkDialAddress,
+		}).DialContext,
+		TLSHandshakeTimeout: 5 * time.Second,
+	}
+}
+
+// publicHost returns true if host is not a cloud metadata service and all its addresses are public
+func publicHost(ctx context.Context, host string) (bool, error) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, h := range metadataHosts {
+		if host == h {
+			return false, nil
+		}
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return false, err
+	}
+	for _, ip := range ips {
+		if !publicIP(ip.IP) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// checkDialAddress rejects connections to private addresses once the host name of a callback is resolved
+func checkDialAddress(network, address string, c syscall.RawConn) error {
+	if allowPrivateCallbacks {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !publicIP(ip) {
+		return errordef.ErrPrivateCallback
+	}
+	return nil
+}
+
+func publicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return false
+	}
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks[i] = n
+	}
+	return networks
+}
@@ -0,0 +1,32 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops/internal/errordef"
+)
+
+func TestValidateCallback(t *testing.T) {
+	ctx := context.TODO()
+
+	assert.Error(t, ValidateCallback(ctx, "ftp://example.com"))
+	assert.Equal(t, errordef.ErrPrivateCallback, ValidateCallback(ctx, "http://example.com/hook"))
+	assert.Equal(t, errordef.ErrPrivateCallback, ValidateCallback(ctx, "https://127.0.0.1/hook"))
+	assert.Equal(t, errordef.ErrPrivateCallback, ValidateCallback(ctx, "https://[::1]/hook"))
+	assert.Equal(t, errordef.ErrPrivateCallback, ValidateCallback(ctx, "https://169.254.169.254/computeMetadata/v1/"))
+	assert.Equal(t, errordef.ErrPrivateCallback, ValidateCallback(ctx, "https://metadata.google.internal/"))
+	assert.Equal(t, errordef.ErrPrivateCallback, ValidateCallback(ctx, "https://10.1.2.3:8443/hook"))
+	assert.NoError(t, ValidateCallback(ctx, "https://8.8.8.8/hook"))
+
+	// feeds are imported over http, too
+	assert.NoError(t, ValidatePublicURL(ctx, "http://8.8.8.8/feed.xml"))
+	assert.Equal(t, errordef.ErrPrivateURL, ValidatePublicURL(ctx, "http://127.0.0.1:8080/feed.xml"))
+	assert.Equal(t, errordef.ErrPrivateURL, ValidatePublicURL(ctx, "http://metadata.google.internal/computeMetadata/v1/"))
+	assert.Equal(t, errordef.ErrInvalidParameters, ValidatePublicURL(ctx, "file:///etc/passwd"))
+
+	assert.Equal(t, errordef.ErrPrivateCallback, checkDialAddress("tcp", "192.168.1.1:443", nil))
+	assert.NoError(t, checkDialAddress("tcp", "8.8.8.8:443", nil))
+}
@@ -0,0 +1,71 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/txsvc/platform/v2/pkg/apis/provider"
+	"github.com/txsvc/platform/v2/pkg/env"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+const (
+	// CheckVerify re-hashes the files of a production on the CDN
+	CheckVerify = "verify"
+	// CheckRepair reconciles the inventory, the production bucket and the CDN and repairs the issues
+	CheckRepair = "fsck"
+)
+
+var (
+	verifyTaskEndpoint string = podops.DefaultCDNEndpoint + "/_w/verify"
+	repairTaskEndpoint string = podops.DefaultCDNEndpoint + "/_w/fsck"
+)
+
+// QueueVerification queues a task that re-hashes the files of a production on the CDN
+func QueueVerification(ctx context.Context, production string, created int64) error {
+	return queueCheck(ctx, verifyTaskEndpoint, production, created)
+}
+
+// QueueRepair queues a task that repairs the inventory, the production bucket and the CDN of a production
+func QueueRepair(ctx context.Context, production string, created int64) error {
+	return queueCheck(ctx, repairTaskEndpoint, production, created)
+}
+
+// WriteReport stores the latest report of a check in the production bucket
+func WriteReport(ctx context.Context, production, check string, report interface{}) error {
+	data, err := json.Marshal(report)
+	if err != nil {
+		return err
+	}
+	return DefaultBlobStore().Write(ctx, reportLocation(production, check), data)
+}
+
+// ReadReport reads the latest report of a check. It returns false if the check never ran.
+func ReadReport(ctx context.Context, production, check string, report interface{}) (bool, error) {
+	data, err := DefaultBlobStore().Read(ctx, reportLocation(production, check))
+	if err != nil {
+		if err == errordef.ErrNoSuchObject {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, json.Unmarshal(data, report)
+}
+
+func queueCheck(ctx context.Context, endpoint, production string, created int64) error {
+	task := provider.HttpTask{
+		Method:  provider.HttpMethodPost,
+		Request: endpoint,
+		Token:   env.GetString("PODOPS_API_KEY", ""),
+		Payload: &podops.CheckRequest{GUID: production, Created: created},
+	}
+	return background().CreateHttpTask(ctx, task)
+}
+
+// reportLocation returns the location of a report in the production bucket
+func reportLocation(production, check string) string {
+	return fmt.Sprintf("%s/reports/%s.json", production, check)
+}
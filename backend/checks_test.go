@@ -0,0 +1,27 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+)
+
+func TestLocalReports(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	var report podops.IntegrityReport
+	found, err := ReadReport(ctx, "prod", CheckVerify, &report)
+	assert.NoError(t, err)
+	assert.False(t, found)
+
+	assert.NoError(t, WriteReport(ctx, "prod", CheckVerify, &podops.IntegrityReport{GUID: "prod", Created: 1, Finished: 2, Checked: 3}))
+	found, err = ReadReport(ctx, "prod", CheckVerify, &report)
+	if assert.NoError(t, err) && assert.True(t, found) {
+		assert.Equal(t, int64(2), report.Finished)
+		assert.Equal(t, 3, report.Checked)
+	}
+}
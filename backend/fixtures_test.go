@@ -0,0 +1,18 @@
+package backend
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setupLocalStore registers an empty local repository and blob store for a test
+func setupLocalStore(t *testing.T) {
+	dir := t.TempDir()
+
+	r, err := NewLocalRepository(filepath.Join(dir, "inventory.db"))
+	if assert.NoError(t, err) {
+		RegisterStore(r, NewLocalBlobStore(filepath.Join(dir, "bucket")))
+	}
+}
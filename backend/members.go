@@ -0,0 +1,167 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+const (
+	// DatastoreMembers collection MEMBERS
+	datastoreMembers = "MEMBERS"
+)
+
+// ValidRole returns true if role is one of the known member roles
+func ValidRole(role string) bool {
+	switch role {
+	case podops.RoleOwner, podops.RolePublisher, podops.RoleEditor, podops.RoleViewer:
+		return true
+	}
+	return false
+}
+
+// AddMember grants the account clientID access to a production. An existing member gets the new role.
+// Without a clientID, the membership is a pending invitation until userID logs in, see ClaimInvitations.
+func AddMember(ctx context.Context, production, realm, userID, clientID, role, invitedBy string) (*podops.Member, error) {
+	if production == "" || userID == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+	if !ValidRole(role) {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	p, err := GetProduction(ctx, production)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errordef.ErrNoSuchProduction
+	}
+
+	m, err := GetMember(ctx, production, userID)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		m = &podops.Member{
+			GUID:      production,
+			UserID:    userID,
+			Realm:     realm,
+			InvitedBy: invitedBy,
+			Created:   timestamp.Now(),
+		}
+	}
+	if clientID != "" {
+		m.ClientID = clientID
+	}
+	m.Role = role
+
+	if err := DefaultRepository().Put(ctx, datastoreMembers, memberKey(production, userID), m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GetMember returns the member of a production with userID, or nil if there is none
+func GetMember(ctx context.Context, production, userID string) (*podops.Member, error) {
+	var m podops.Member
+
+	if err := DefaultRepository().Get(ctx, datastoreMembers, memberKey(production, userID), &m); err != nil {
+		if err == errordef.ErrNoSuchEntity {
+			return nil, nil // not found is not an error
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+// FindMember returns the membership of the account clientID in a production, or nil if there is none
+func FindMember(ctx context.Context, production, clientID string) (*podops.Member, error) {
+	var m []*podops.Member
+
+	if clientID == "" {
+		return nil, nil // never match pending invitations
+	}
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreMembers).Filter("GUID =", production).Filter("ClientID =", clientID), &m); err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return nil, nil
+	}
+	return m[0], nil
+}
+
+// ListMembers returns all members of a production
+func ListMembers(ctx context.Context, production string) ([]*podops.Member, error) {
+	var m []*podops.Member
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreMembers).Filter("GUID =", production).Order("Created"), &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// RemoveMember revokes the access of a member to a production
+func RemoveMember(ctx context.Context, production, userID string) error {
+	m, err := GetMember(ctx, production, userID)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		return errordef.ErrNoSuchMember
+	}
+	return DefaultRepository().Delete(ctx, datastoreMembers, memberKey(production, userID))
+}
+
+// ClaimInvitations binds the pending invitations of userID to the account clientID once the invitee has logged in
+func ClaimInvitations(ctx context.Context, realm, userID, clientID string) error {
+	var m []*podops.Member
+
+	if userID == "" || clientID == "" {
+		return nil
+	}
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreMembers).Filter("UserID =", userID).Filter("ClientID =", ""), &m); err != nil {
+		return err
+	}
+	for _, member := range m {
+		if member.Realm != realm {
+			continue
+		}
+		member.ClientID = clientID
+		if err := DefaultRepository().Put(ctx, datastoreMembers, memberKey(member.GUID, member.UserID), member); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindProductionsByMember returns all productions the account clientID is a member of
+func FindProductionsByMember(ctx context.Context, clientID string) ([]*podops.Production, error) {
+	var m []*podops.Member
+	if clientID == "" {
+		return nil, nil
+	}
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreMembers).Filter("ClientID =", clientID), &m); err != nil {
+		return nil, err
+	}
+
+	var productions []*podops.Production
+	for _, member := range m {
+		p, err := GetProduction(ctx, member.GUID)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil {
+			productions = append(productions, p)
+		}
+	}
+	return productions, nil
+}
+
+func memberKey(production, userID string) string {
+	return fmt.Sprintf("%s.%s", production, userID)
+}
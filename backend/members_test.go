@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+func TestLocalMembers(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	p, err := CreateProduction(ctx, "team-podcast", "title", "summary", "owner-client")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = AddMember(ctx, p.GUID, "podops", "jane@example.com", "jane-client", "producer", "owner@example.com")
+	assert.Error(t, err)
+
+	m, err := AddMember(ctx, p.GUID, "podops", "jane@example.com", "jane-client", podops.RoleEditor, "owner@example.com")
+	if assert.NoError(t, err) {
+		assert.Equal(t, podops.RoleEditor, m.Role)
+	}
+	// adding again changes the role
+	_, err = AddMember(ctx, p.GUID, "podops", "jane@example.com", "jane-client", podops.RolePublisher, "owner@example.com")
+	assert.NoError(t, err)
+
+	m, err = FindMember(ctx, p.GUID, "jane-client")
+	if assert.NoError(t, err) && assert.NotNil(t, m) {
+		assert.Equal(t, podops.RolePublisher, m.Role)
+		assert.Equal(t, "owner@example.com", m.InvitedBy)
+	}
+	l, err := ListMembers(ctx, p.GUID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, len(l))
+	}
+	shared, err := FindProductionsByMember(ctx, "jane-client")
+	if assert.NoError(t, err) && assert.Equal(t, 1, len(shared)) {
+		assert.Equal(t, p.GUID, shared[0].GUID)
+	}
+
+	assert.NoError(t, RemoveMember(ctx, p.GUID, "jane@example.com"))
+	assert.Equal(t, errordef.ErrNoSuchMember, RemoveMember(ctx, p.GUID, "jane@example.com"))
+	m, err = FindMember(ctx, p.GUID, "jane-client")
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+
+	// invitations of users without an account are pending until they log in
+	_, err = AddMember(ctx, p.GUID, "podops", "joe@example.com", "", podops.RoleViewer, "owner@example.com")
+	assert.NoError(t, err)
+	shared, err = FindProductionsByMember(ctx, "")
+	assert.NoError(t, err)
+	assert.Empty(t, shared)
+
+	assert.NoError(t, ClaimInvitations(ctx, "podops", "joe@example.com", "joe-client"))
+	m, err = FindMember(ctx, p.GUID, "joe-client")
+	if assert.NoError(t, err) && assert.NotNil(t, m) {
+		assert.Equal(t, podops.RoleViewer, m.Role)
+	}
+}
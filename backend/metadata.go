@@ -4,9 +4,6 @@ import (
 	"context"
 	"strings"
 
-	"cloud.google.com/go/datastore"
-	ds "github.com/txsvc/platform/v2/pkg/datastore"
-
 	"github.com/podops/podops"
 	"github.com/podops/podops/internal/errordef"
 	"github.com/podops/podops/internal/metadata"
@@ -21,8 +18,8 @@ const (
 func GetMetadata(ctx context.Context, guid string) (*metadata.Metadata, error) {
 	var m metadata.Metadata
 
-	if err := ds.DataStore().Get(ctx, metadataKey(guid), &m); err != nil {
-		if err == datastore.ErrNoSuchEntity {
+	if err := DefaultRepository().Get(ctx, datastoreMetadata, guid, &m); err != nil {
+		if err == errordef.ErrNoSuchEntity {
 			return nil, nil // not found is not an error
 		}
 		return nil, err
@@ -52,9 +49,43 @@ func GetMetadataForResource(ctx context.Context, guid string) (*metadata.Metadat
 	return GetMetadata(ctx, metaGUID)
 }
 
+// GetAssetMetadata retrieves the metadata of an asset in the CDN based on its URI, nil if the asset is external
+func GetAssetMetadata(ctx context.Context, production, uri, rel string) (*metadata.Metadata, error) {
+	switch rel {
+	case podops.ResourceTypeLocal:
+		return GetMetadata(ctx, metadata.FingerprintURI(production, metadata.LocalNamePart(uri)))
+	case podops.ResourceTypeImport:
+		return GetMetadata(ctx, strings.Split(metadata.LocalNamePart(uri), ".")[0])
+	}
+	return nil, nil
+}
+
+// ListMetadata returns the metadata of all files of a production in the CDN
+func ListMetadata(ctx context.Context, production string) ([]*metadata.Metadata, error) {
+	var meta []*metadata.Metadata
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreMetadata).Filter("ParentGUID =", production), &meta); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// FindAssetMetadata retrieves the metadata of a file in the CDN, uploaded or imported. Returns nil if the file is not in the inventory.
+func FindAssetMetadata(ctx context.Context, production, name string) (*metadata.Metadata, error) {
+	m, err := GetMetadata(ctx, metadata.FingerprintURI(production, name))
+	if err != nil || m != nil {
+		return m, err
+	}
+	// imported files are named after their GUID
+	m, err = GetMetadata(ctx, strings.Split(name, ".")[0])
+	if err != nil || m == nil || m.ParentGUID != production {
+		return nil, err
+	}
+	return m, nil
+}
+
 // UpdateMetadata does what the name suggests
 func UpdateMetadata(ctx context.Context, m *metadata.Metadata) error {
-	if _, err := ds.DataStore().Put(ctx, metadataKey(m.GUID), m); err != nil {
+	if err := DefaultRepository().Put(ctx, datastoreMetadata, m.GUID, m); err != nil {
 		return err
 	}
 	return nil
@@ -70,12 +101,8 @@ func DeleteMetadata(ctx context.Context, guid string) error {
 		return errordef.ErrNoSuchResource
 	}
 
-	if err := ds.DataStore().Delete(ctx, metadataKey(m.GUID)); err != nil {
+	if err := DefaultRepository().Delete(ctx, datastoreMetadata, m.GUID); err != nil {
 		return err
 	}
 	return nil
 }
-
-func metadataKey(guid string) *datastore.Key {
-	return datastore.NameKey(datastoreMetadata, guid, nil)
-}
@@ -5,9 +5,6 @@ import (
 	"fmt"
 	"strconv"
 
-	"cloud.google.com/go/datastore"
-
-	ds "github.com/txsvc/platform/v2/pkg/datastore"
 	"github.com/txsvc/platform/v2/pkg/timestamp"
 
 	"github.com/podops/podops"
@@ -31,7 +28,7 @@ func UpdateShow(ctx context.Context, location string, show *podops.Show) error {
 		r.ImageRel = show.Image.Rel
 		r.Updated = timestamp.Now()
 
-		return updateResource(ctx, r)
+		return updatePodcastResource(ctx, r)
 	}
 
 	// create a new inventory entry
@@ -49,7 +46,7 @@ func UpdateShow(ctx context.Context, location string, show *podops.Show) error {
 		Created:    now,
 		Updated:    now,
 	}
-	return updateResource(ctx, &rsrc)
+	return updatePodcastResource(ctx, &rsrc)
 }
 
 // UpdateEpisode is a helper function to update a episode resource
@@ -88,9 +85,11 @@ func UpdateEpisode(ctx context.Context, location string, episode *podops.Episode
 		r.EnclosureRel = episode.Enclosure.Rel
 		r.ImageURI = episode.Image.ResolveURI(podops.DefaultStorageEndpoint, episode.Parent())
 		r.ImageRel = episode.Image.Rel
+		r.TranscriptURIs = episode.TranscriptURIs()
+		r.ChaptersURI = episode.ChaptersURI()
 		r.Updated = timestamp.Now()
 
-		return updateResource(ctx, r)
+		return updatePodcastResource(ctx, r)
 	}
 
 	// create a new inventory entry
@@ -98,29 +97,40 @@ func UpdateEpisode(ctx context.Context, location string, episode *podops.Episode
 	index, _ := strconv.ParseInt(episode.Metadata.Labels[podops.LabelEpisode], 10, 64)
 
 	rsrc := podops.Resource{
-		Name:         episode.Metadata.Name,
-		GUID:         episode.GUID(),
-		Kind:         podops.ResourceEpisode,
-		ParentGUID:   episode.Metadata.Labels[podops.LabelParentGUID],
-		Location:     location,
-		Title:        episode.Description.Title,
-		Summary:      episode.Description.Summary,
-		Published:    episode.PublishDateTimestamp(),
-		Index:        int(index), // episode number
-		EnclosureURI: episode.Enclosure.ResolveURI(podops.DefaultStorageEndpoint, episode.Parent()),
-		EnclosureRel: episode.Enclosure.Rel,
-		ImageURI:     episode.Image.ResolveURI(podops.DefaultStorageEndpoint, episode.Parent()),
-		ImageRel:     episode.Image.Rel,
-		Created:      now,
-		Updated:      now,
+		Name:           episode.Metadata.Name,
+		GUID:           episode.GUID(),
+		Kind:           podops.ResourceEpisode,
+		ParentGUID:     episode.Metadata.Labels[podops.LabelParentGUID],
+		Location:       location,
+		Title:          episode.Description.Title,
+		Summary:        episode.Description.Summary,
+		Published:      episode.PublishDateTimestamp(),
+		Index:          int(index), // episode number
+		EnclosureURI:   episode.Enclosure.ResolveURI(podops.DefaultStorageEndpoint, episode.Parent()),
+		EnclosureRel:   episode.Enclosure.Rel,
+		ImageURI:       episode.Image.ResolveURI(podops.DefaultStorageEndpoint, episode.Parent()),
+		ImageRel:       episode.Image.Rel,
+		TranscriptURIs: episode.TranscriptURIs(),
+		ChaptersURI:    episode.ChaptersURI(),
+		Created:        now,
+		Updated:        now,
+	}
+	return updatePodcastResource(ctx, &rsrc)
+}
+
+// updatePodcastResource updates the inventory entry of a show or episode and lets the production's webhooks know
+func updatePodcastResource(ctx context.Context, r *podops.Resource) error {
+	if err := updateResource(ctx, r); err != nil {
+		return err
 	}
-	return updateResource(ctx, &rsrc)
+	Emit(ctx, r.ParentGUID, podops.EventResourceUpdated, r.GUID, r)
+	return nil
 }
 
 func ListPublishedEpisodes(ctx context.Context, production string, published int64, limit int) ([]*podops.Resource, error) {
 	var episodes []*podops.Resource
 
-	if _, err := ds.DataStore().GetAll(ctx, datastore.NewQuery(datastoreResources).Filter("ParentGUID =", production).Filter("Kind =", podops.ResourceEpisode).Filter("Published <", published).Filter("Published >", 0).Order("-Published").Limit(limit), &episodes); err != nil {
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreResources).Filter("ParentGUID =", production).Filter("Kind =", podops.ResourceEpisode).Filter("Published <", published).Filter("Published >", 0).Order("-Published").Limit(limit), &episodes); err != nil {
 		// FIXME filter for other flags, e.g. Block = true
 		return nil, err
 	}
@@ -130,12 +140,16 @@ func ListPublishedEpisodes(ctx context.Context, production string, published int
 func ListRecentProductions(ctx context.Context, limit int) ([]*podops.Production, error) {
 	var shows []*podops.Production
 
-	if _, err := ds.DataStore().GetAll(ctx, datastore.NewQuery(datastoreProductions).Filter("BuildDate >", 0).Order("-BuildDate").Limit(limit), &shows); err != nil {
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreProductions).Filter("BuildDate >", 0).Order("-BuildDate").Limit(limit), &shows); err != nil {
 		return nil, err
 	}
-	return shows, nil
-}
 
-func ListPopularProductions(ctx context.Context, limit int) ([]*podops.Production, error) {
-	return ListRecentProductions(ctx, limit) // FIXME this is just a placeholder, we don't have usage data at the moment to return a real answer
+	// productions in the trash are not listed
+	recent := make([]*podops.Production, 0, len(shows))
+	for _, p := range shows {
+		if p.Deleted == 0 {
+			recent = append(recent, p)
+		}
+	}
+	return recent, nil
 }
@@ -5,15 +5,14 @@ import (
 	"fmt"
 	"strings"
 
-	"cloud.google.com/go/datastore"
-
-	ds "github.com/txsvc/platform/v2/pkg/datastore"
+	"github.com/txsvc/platform/v2/pkg/env"
 	"github.com/txsvc/platform/v2/pkg/id"
 	"github.com/txsvc/platform/v2/pkg/timestamp"
 
 	"github.com/podops/podops"
 	"github.com/podops/podops/internal/errordef"
 	"github.com/podops/podops/internal/messagedef"
+	"github.com/podops/podops/internal/validator"
 )
 
 const (
@@ -38,6 +37,14 @@ func CreateProduction(ctx context.Context, name, title, summary, clientID string
 		}
 		return p, nil
 	}
+	// the earlier names of renamed productions are still in use
+	p, err = FindProductionByAlias(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if p != nil {
+		return nil, fmt.Errorf(messagedef.MsgResourceAlreadyExists, name)
+	}
 
 	// create a new production
 	id, _ := id.ShortUUID()
@@ -66,8 +73,8 @@ func CreateProduction(ctx context.Context, name, title, summary, clientID string
 func GetProduction(ctx context.Context, production string) (*podops.Production, error) {
 	var p podops.Production
 
-	if err := ds.DataStore().Get(ctx, productionKey(production), &p); err != nil {
-		if err == datastore.ErrNoSuchEntity {
+	if err := DefaultRepository().Get(ctx, datastoreProductions, production, &p); err != nil {
+		if err == errordef.ErrNoSuchEntity {
 			return nil, nil // not found is not an error
 		}
 		return nil, err
@@ -75,14 +82,15 @@ func GetProduction(ctx context.Context, production string) (*podops.Production,
 	return &p, nil
 }
 
-// ValidateProduction checks the integrity of a production and fixes issues if possible
-func ValidateProduction(ctx context.Context, production string) error {
+// ValidateProduction checks the integrity of a production and fixes issues if possible.
+// Issues with the artwork of the show and its episodes are reported to v, if not nil.
+func ValidateProduction(ctx context.Context, production string, v *validator.Validator) error {
 	var p podops.Production
 	episodes := 0
 	show := 0
 	assets := 0
 
-	err := ds.DataStore().Get(ctx, productionKey(production), &p)
+	err := DefaultRepository().Get(ctx, datastoreProductions, production, &p)
 	if err != nil {
 		return err
 	}
@@ -111,12 +119,40 @@ func ValidateProduction(ctx context.Context, production string) error {
 	if episodes == 0 {
 		return errordef.ErrNoSuchEpisode
 	}
+
+	if v != nil {
+		return validateArtwork(ctx, production, rsrc, v)
+	}
+	return nil
+}
+
+// validateArtwork verifies the artwork of the show and its episodes, based on the metadata extracted at upload or import time
+func validateArtwork(ctx context.Context, production string, rsrc []*podops.Resource, v *validator.Validator) error {
+	verified := make(map[string]bool)
+
+	for _, r := range rsrc {
+		if r.Kind == podops.ResourceAsset || r.ImageURI == "" || verified[r.ImageURI] {
+			continue
+		}
+		verified[r.ImageURI] = true // episodes often re-use the show's artwork
+
+		name := fmt.Sprintf("%s-%s.Image", r.Kind, r.GUID)
+		m, err := GetAssetMetadata(ctx, production, r.ImageURI, r.ImageRel)
+		if err != nil {
+			return err
+		}
+		if m == nil {
+			v.AssertWarning(fmt.Sprintf(messagedef.MsgArtworkNotVerified, name, r.ImageURI))
+			continue
+		}
+		v.AssertArtwork(m.Width, m.Height, m.Format, m.ColorModel, name)
+	}
 	return nil
 }
 
 // UpdateProduction does what the name suggests
 func UpdateProduction(ctx context.Context, p *podops.Production) error {
-	if _, err := ds.DataStore().Put(ctx, productionKey(p.GUID), p); err != nil {
+	if err := DefaultRepository().Put(ctx, datastoreProductions, p.GUID, p); err != nil {
 		return err
 	}
 	return nil
@@ -125,7 +161,19 @@ func UpdateProduction(ctx context.Context, p *podops.Production) error {
 // FindProductionByName does a lookup using the productions name instead of its key
 func FindProductionByName(ctx context.Context, name string) (*podops.Production, error) {
 	var p []*podops.Production
-	if _, err := ds.DataStore().GetAll(ctx, datastore.NewQuery(datastoreProductions).Filter("Name =", name), &p); err != nil {
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreProductions).Filter("Name =", name), &p); err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, nil
+	}
+	return p[0], nil
+}
+
+// FindProductionByAlias returns the production that had name before it was renamed
+func FindProductionByAlias(ctx context.Context, name string) (*podops.Production, error) {
+	var p []*podops.Production
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreProductions).Filter("Aliases =", name), &p); err != nil {
 		return nil, err
 	}
 	if p == nil {
@@ -134,10 +182,307 @@ func FindProductionByName(ctx context.Context, name string) (*podops.Production,
 	return p[0], nil
 }
 
+// RenameProduction changes the name of a production. The old name is kept as an alias so that its feed URL keeps working.
+func RenameProduction(ctx context.Context, production, name string) (*podops.Production, error) {
+	p, err := GetProduction(ctx, production)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errordef.ErrNoSuchProduction
+	}
+	if p.Name == name {
+		return p, nil // nothing to do
+	}
+
+	// the name must not be in use, except as an alias of the same production
+	other, err := FindProductionByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if other == nil {
+		if other, err = FindProductionByAlias(ctx, name); err != nil {
+			return nil, err
+		}
+	}
+	if other != nil && other.GUID != p.GUID {
+		return nil, fmt.Errorf(messagedef.MsgResourceAlreadyExists, name)
+	}
+
+	aliases := []string{p.Name}
+	for _, a := range p.Aliases {
+		if a != name && a != p.Name {
+			aliases = append(aliases, a)
+		}
+	}
+	p.Aliases = aliases
+	p.Name = name
+	p.Updated = timestamp.Now()
+
+	// the show's inventory entry carries the name too
+	r, err := GetResource(ctx, p.GUID)
+	if err != nil {
+		return nil, err
+	}
+	if r != nil {
+		r.Name = name
+		r.Updated = p.Updated
+		if err := updateResource(ctx, r); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := UpdateProduction(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// ArchiveProduction makes a production read-only and stops its schedule, or makes it writable again.
+// Productions in the trash can't be archived or unarchived.
+func ArchiveProduction(ctx context.Context, production string, archived bool) (*podops.Production, error) {
+	p, err := GetProduction(ctx, production)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errordef.ErrNoSuchProduction
+	}
+	if p.Deleted > 0 {
+		return nil, errordef.ErrProductionDeleted // restore it first
+	}
+
+	p.Archived = archived
+	p.Updated = timestamp.Now()
+	if archived {
+		p.NextPublishDate = 0 // the feed is frozen, nothing gets published
+	}
+	if err := UpdateProduction(ctx, p); err != nil {
+		return nil, err
+	}
+
+	if !archived {
+		// pick up the scheduled episodes again
+		if err := UpdateSchedule(ctx, production); err != nil {
+			return nil, err
+		}
+		return GetProduction(ctx, production)
+	}
+	return p, nil
+}
+
+// TransferProduction makes owner the new owner of a production
+func TransferProduction(ctx context.Context, production, owner string) (*podops.Production, error) {
+	p, err := GetProduction(ctx, production)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errordef.ErrNoSuchProduction
+	}
+
+	p.Owner = owner
+	p.Updated = timestamp.Now()
+	if err := UpdateProduction(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// DeleteProduction moves a production to the trash. It is read-only, its schedule stops and it is no longer listed
+// until it is restored with RestoreProduction, or purged once the retention period of the trash has ended.
+func DeleteProduction(ctx context.Context, production string) (*podops.Production, error) {
+	p, err := GetProduction(ctx, production)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errordef.ErrNoSuchProduction
+	}
+	if p.Deleted > 0 {
+		return p, nil // already in the trash
+	}
+
+	now := timestamp.Now()
+	p.Deleted = now
+	p.Expires = now + env.GetInt("PODOPS_TRASH_RETENTION", DefaultTrashRetention)*86400
+	p.NextPublishDate = 0 // nothing gets published from the trash
+	p.Updated = now
+	if err := UpdateProduction(ctx, p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// RestoreProduction takes a production out of the trash
+func RestoreProduction(ctx context.Context, production string) (*podops.Production, error) {
+	p, err := GetProduction(ctx, production)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errordef.ErrNoSuchProduction
+	}
+	if p.Deleted == 0 {
+		return nil, errordef.ErrNotInTrash
+	}
+
+	p.Deleted = 0
+	p.Expires = 0
+	p.Updated = timestamp.Now()
+	if err := UpdateProduction(ctx, p); err != nil {
+		return nil, err
+	}
+
+	if !p.Archived {
+		// pick up the scheduled episodes again
+		if err := UpdateSchedule(ctx, production); err != nil {
+			return nil, err
+		}
+		return GetProduction(ctx, production)
+	}
+	return p, nil
+}
+
+// ListExpiredProductions returns all deleted productions whose retention period ended before now
+func ListExpiredProductions(ctx context.Context, now int64) ([]*podops.Production, error) {
+	var p []*podops.Production
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreProductions).Filter("Expires >", 0).Filter("Expires <", now), &p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// PurgeProduction removes a production for good, together with its resources, revisions, builds, members, subscriptions,
+// metadata and all files in the production bucket and on the CDN. Download statistics are kept.
+func PurgeProduction(ctx context.Context, production string) error {
+	p, err := GetProduction(ctx, production)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return errordef.ErrNoSuchProduction
+	}
+
+	// resources and deleted resources, with their revisions
+	rsrc, err := ListResources(ctx, production, podops.ResourceALL)
+	if err != nil {
+		return err
+	}
+	for _, r := range rsrc {
+		if err := deleteRevisions(ctx, r.GUID); err != nil {
+			return err
+		}
+		if err := DefaultRepository().Delete(ctx, datastoreResources, r.GUID); err != nil {
+			return err
+		}
+	}
+	trash, err := ListTrash(ctx, production)
+	if err != nil {
+		return err
+	}
+	for _, t := range trash {
+		if err := deleteRevisions(ctx, t.GUID); err != nil {
+			return err
+		}
+		if err := DefaultRepository().Delete(ctx, datastoreTrash, t.GUID); err != nil {
+			return err
+		}
+	}
+
+	// metadata of all assets, including the ones in the trash
+	meta, err := ListMetadata(ctx, production)
+	if err != nil {
+		return err
+	}
+	for _, m := range meta {
+		if err := DefaultRepository().Delete(ctx, datastoreMetadata, m.GUID); err != nil {
+			return err
+		}
+	}
+
+	var builds []*podops.Build
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreBuilds).Filter("GUID =", production), &builds); err != nil {
+		return err
+	}
+	for _, b := range builds {
+		if err := DefaultRepository().Delete(ctx, datastoreBuilds, b.ID); err != nil {
+			return err
+		}
+	}
+
+	members, err := ListMembers(ctx, production)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		if err := RemoveMember(ctx, production, m.UserID); err != nil {
+			return err
+		}
+	}
+
+	webhooks, err := ListWebhooks(ctx, production)
+	if err != nil {
+		return err
+	}
+	for _, w := range webhooks {
+		if err := DefaultRepository().Delete(ctx, datastoreWebhooks, w.ID); err != nil {
+			return err
+		}
+	}
+	deliveries, err := ListDeliveries(ctx, production, 0)
+	if err != nil {
+		return err
+	}
+	for _, d := range deliveries {
+		if err := DefaultRepository().Delete(ctx, datastoreWebhookDeliveries, d.ID); err != nil {
+			return err
+		}
+	}
+
+	hubSubscriptions, err := ListHubSubscriptions(ctx, production)
+	if err != nil {
+		return err
+	}
+	for _, s := range hubSubscriptions {
+		if err := DefaultRepository().Delete(ctx, datastoreHubSubscriptions, s.ID); err != nil {
+			return err
+		}
+	}
+
+	subscriptions, err := ListSubscriptions(ctx, production)
+	if err != nil {
+		return err
+	}
+	for _, s := range subscriptions {
+		if err := DefaultRepository().Delete(ctx, datastoreSubscriptions, s.Token); err != nil {
+			return err
+		}
+	}
+
+	// the .yaml, revisions, build artifacts and feeds in the production bucket
+	files, err := DefaultBlobStore().List(ctx, production+"/")
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := DefaultBlobStore().Remove(ctx, f); err != nil && err != errordef.ErrNoSuchObject {
+			return err
+		}
+	}
+
+	// the production goes last, a failed deletion can simply be repeated
+	if err := RemoveProductionAssets(ctx, production); err != nil {
+		return err
+	}
+	return DefaultRepository().Delete(ctx, datastoreProductions, production)
+}
+
 // FindProductionsByOwner returns all productions belonging to the same owner
 func FindProductionsByOwner(ctx context.Context, owner string) ([]*podops.Production, error) {
 	var p []*podops.Production
-	if _, err := ds.DataStore().GetAll(ctx, datastore.NewQuery(datastoreProductions).Filter("Owner =", owner), &p); err != nil {
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreProductions).Filter("Owner =", owner), &p); err != nil {
 		return nil, err
 	}
 	if p == nil {
@@ -146,6 +491,7 @@ func FindProductionsByOwner(ctx context.Context, owner string) ([]*podops.Produc
 	return p, nil
 }
 
-func productionKey(production string) *datastore.Key {
-	return datastore.NameKey(datastoreProductions, production, nil)
+// FeedAliasURL returns the location of a production's feed that does not change when the feed is moved
+func FeedAliasURL(name string) string {
+	return fmt.Sprintf("%s/s/%s/feed.xml", podops.DefaultEndpoint, name)
 }
@@ -0,0 +1,96 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+func TestLocalProductionLifecycle(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	p, err := CreateProduction(ctx, "old-name", "title", "summary", "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, UpdateResource(ctx, p.Name, p.GUID, podops.ResourceShow, p.GUID, fmt.Sprintf("%s/show-%s.yaml", p.GUID, p.GUID)))
+
+	// the old name becomes an alias and can't be taken by another production
+	p, err = RenameProduction(ctx, p.GUID, "new-name")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "new-name", p.Name)
+		assert.Equal(t, []string{"old-name"}, p.Aliases)
+	}
+	alias, err := FindProductionByAlias(ctx, "old-name")
+	if assert.NoError(t, err) && assert.NotNil(t, alias) {
+		assert.Equal(t, p.GUID, alias.GUID)
+	}
+	r, err := GetResource(ctx, p.GUID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "new-name", r.Name)
+	}
+	_, err = CreateProduction(ctx, "old-name", "title", "summary", "someone")
+	assert.Error(t, err)
+
+	// renaming back drops the alias
+	p, err = RenameProduction(ctx, p.GUID, "old-name")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"new-name"}, p.Aliases)
+	}
+
+	// archived productions have no schedule
+	episode := podops.Resource{GUID: "future-episode", Kind: podops.ResourceEpisode, ParentGUID: p.GUID, Published: time.Now().Unix() + 3600}
+	assert.NoError(t, DefaultRepository().Put(ctx, datastoreResources, episode.GUID, &episode))
+	assert.NoError(t, UpdateSchedule(ctx, p.GUID))
+	p, err = ArchiveProduction(ctx, p.GUID, true)
+	if assert.NoError(t, err) {
+		assert.True(t, p.Archived)
+		assert.Equal(t, int64(0), p.NextPublishDate)
+	}
+	assert.NoError(t, UpdateSchedule(ctx, p.GUID))
+	p, err = ArchiveProduction(ctx, p.GUID, false)
+	if assert.NoError(t, err) {
+		assert.False(t, p.Archived)
+		assert.Equal(t, episode.Published, p.NextPublishDate)
+	}
+
+	p, err = TransferProduction(ctx, p.GUID, "other-client")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "other-client", p.Owner)
+	}
+	l, err := FindProductionsByOwner(ctx, "client")
+	if assert.NoError(t, err) {
+		assert.Empty(t, l)
+	}
+
+	// deleted productions wait in the trash until their retention period has ended
+	p, err = DeleteProduction(ctx, p.GUID)
+	if assert.NoError(t, err) {
+		assert.True(t, p.Deleted > 0)
+		assert.Equal(t, int64(0), p.NextPublishDate)
+	}
+	_, err = ArchiveProduction(ctx, p.GUID, false) // would resume the schedule
+	assert.Equal(t, errordef.ErrProductionDeleted, err)
+	expired, err := ListExpiredProductions(ctx, time.Now().Unix())
+	if assert.NoError(t, err) {
+		assert.Empty(t, expired)
+	}
+	expired, err = ListExpiredProductions(ctx, p.Expires+1)
+	if assert.NoError(t, err) && assert.Equal(t, 1, len(expired)) {
+		assert.Equal(t, p.GUID, expired[0].GUID)
+	}
+	p, err = RestoreProduction(ctx, p.GUID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(0), p.Deleted)
+		assert.Equal(t, episode.Published, p.NextPublishDate)
+	}
+	_, err = RestoreProduction(ctx, p.GUID)
+	assert.Equal(t, errordef.ErrNotInTrash, err)
+}
@@ -3,21 +3,17 @@ package backend
 import (
 	"context"
 	"fmt"
-	"io/ioutil"
 	"strings"
 
-	"cloud.google.com/go/datastore"
-	"cloud.google.com/go/storage"
-
 	"gopkg.in/yaml.v2"
 
-	ds "github.com/txsvc/platform/v2/pkg/datastore"
 	"github.com/txsvc/platform/v2/pkg/timestamp"
 
 	"github.com/podops/podops"
 	"github.com/podops/podops/internal/errordef"
 	"github.com/podops/podops/internal/loader"
 	"github.com/podops/podops/internal/messagedef"
+	"github.com/podops/podops/internal/metadata"
 )
 
 const (
@@ -29,6 +25,7 @@ var (
 	// full canonical route
 	importTaskEndpoint string = podops.DefaultCDNEndpoint + "/_w/import"
 	syncTaskEndpoint   string = podops.DefaultCDNEndpoint + "/_w/sync"
+	purgeTaskEndpoint  string = podops.DefaultCDNEndpoint + "/_w/purge"
 	// mapping of resource names and aliases
 	resourceMap map[string]string
 )
@@ -56,8 +53,8 @@ func NormalizeKind(kind string) (string, error) {
 func GetResource(ctx context.Context, guid string) (*podops.Resource, error) {
 	var r podops.Resource
 
-	if err := ds.DataStore().Get(ctx, resourceKey(guid), &r); err != nil {
-		if err == datastore.ErrNoSuchEntity {
+	if err := DefaultRepository().Get(ctx, datastoreResources, guid, &r); err != nil {
+		if err == errordef.ErrNoSuchEntity {
 			return nil, nil // not found is not an error
 		}
 		return nil, err
@@ -69,7 +66,7 @@ func GetResource(ctx context.Context, guid string) (*podops.Resource, error) {
 func FindResource(ctx context.Context, production, name string) (*podops.Resource, error) {
 	var r []*podops.Resource
 
-	if _, err := ds.DataStore().GetAll(ctx, datastore.NewQuery(datastoreResources).Filter("ParentGUID =", production).Filter("Name =", name), &r); err != nil {
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreResources).Filter("ParentGUID =", production).Filter("Name =", name), &r); err != nil {
 		return nil, err
 	}
 	if r == nil {
@@ -118,8 +115,9 @@ func UpdateResource(ctx context.Context, name, guid, kind, production, location
 	return updateResource(ctx, &rsrc)
 }
 
-// DeleteResource deletes a resource and it's backing .yaml file
-func DeleteResource(ctx context.Context, prod, kind, guid string) error {
+// DeleteResource moves a resource and it's backing .yaml file to the trash. It can be restored with UndeleteResource
+// until it is purged.
+func DeleteResource(ctx context.Context, prod, kind, guid, author string) error {
 	r, err := GetResource(ctx, guid)
 	if err != nil {
 		return err
@@ -128,12 +126,18 @@ func DeleteResource(ctx context.Context, prod, kind, guid string) error {
 		return errordef.ErrNoSuchResource
 	}
 
-	if err := ds.DataStore().Delete(ctx, resourceKey(r.GUID)); err != nil {
+	if err := trashResource(ctx, r, author); err != nil {
+		return err
+	}
+	if err := DefaultRepository().Delete(ctx, datastoreResources, r.GUID); err != nil {
 		return err
 	}
+	if r.Kind == podops.ResourceAsset {
+		Emit(ctx, r.ParentGUID, podops.EventAssetDeleted, r.GUID, r)
+	}
 
 	// validate the production after deleting a resource
-	if err = ValidateProduction(ctx, prod); err != nil {
+	if err = ValidateProduction(ctx, prod, nil); err != nil {
 		p, err := GetProduction(ctx, prod)
 		if err != nil {
 			return err
@@ -143,14 +147,7 @@ func DeleteResource(ctx context.Context, prod, kind, guid string) error {
 		p.LatestPublishDate = 0
 		UpdateProduction(ctx, p)
 	}
-
-	if r.Kind == podops.ResourceAsset {
-		if err := DeleteMetadata(ctx, guid); err != nil {
-			return err
-		}
-		return RemoveAsset(ctx, prod, r.Location)
-	}
-	return RemoveResourceContent(ctx, r.Location)
+	return nil
 }
 
 // ListResources returns all resources of type kind belonging to parentID
@@ -163,7 +160,7 @@ func ListResources(ctx context.Context, production, kind string) ([]*podops.Reso
 	}
 
 	if _kind == podops.ResourceALL {
-		if _, err := ds.DataStore().GetAll(ctx, datastore.NewQuery(datastoreResources).Filter("ParentGUID =", production).Order("-Created"), &r); err != nil {
+		if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreResources).Filter("ParentGUID =", production).Order("-Created"), &r); err != nil {
 			return nil, err
 		}
 	} else if _kind == podops.ResourceShow {
@@ -173,7 +170,7 @@ func ListResources(ctx context.Context, production, kind string) ([]*podops.Reso
 			r = append(r, show)
 		}
 	} else {
-		if _, err := ds.DataStore().GetAll(ctx, datastore.NewQuery(datastoreResources).Filter("ParentGUID =", production).Filter("Kind =", _kind).Order("-Created"), &r); err != nil {
+		if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreResources).Filter("ParentGUID =", production).Filter("Kind =", _kind).Order("-Created"), &r); err != nil {
 			return nil, err
 		}
 	}
@@ -228,8 +225,14 @@ func GetResourceContent(ctx context.Context, guid string) (interface{}, error) {
 		}
 		episode := rsrc.(*podops.Episode)
 		episode.Enclosure.URI = r.EnclosureURI
-		episode.Enclosure.Size = int(meta.Size) // GITHUB_ISSUE #10
-		episode.Description.Duration = int(meta.Duration)
+		if meta != nil { // external enclosures are not in the inventory
+			episode.Enclosure.Size = int(meta.Size) // GITHUB_ISSUE #10
+			episode.Description.Duration = int(meta.Duration)
+		}
+		resolveTranscripts(episode, r.TranscriptURIs)
+		if episode.Chapters != nil {
+			episode.Chapters.URI = r.ChaptersURI
+		}
 
 		return episode, nil
 	}
@@ -237,17 +240,54 @@ func GetResourceContent(ctx context.Context, guid string) (interface{}, error) {
 	return nil, errordef.ErrNoSuchResource
 }
 
+// resolveTranscripts replaces the URIs of the episode's transcripts with the resolved URIs from the inventory.
+// Transcripts are matched by their resolved URI first, then by their MIME type. Transcripts without a match keep the URI from the .yaml.
+func resolveTranscripts(episode *podops.Episode, uris []string) {
+	used := make([]bool, len(uris))
+	matched := make([]bool, len(episode.Transcripts))
+
+	resolve := func(matches func(t *podops.Asset, uri string) bool) {
+		for i := range episode.Transcripts {
+			if matched[i] {
+				continue
+			}
+			for j, uri := range uris {
+				if !used[j] && matches(&episode.Transcripts[i], uri) {
+					episode.Transcripts[i].URI = uri
+					matched[i], used[j] = true, true
+					break
+				}
+			}
+		}
+	}
+
+	resolve(func(t *podops.Asset, uri string) bool {
+		return t.ResolveURI(podops.DefaultStorageEndpoint, episode.Parent()) == uri
+	})
+	resolve(func(t *podops.Asset, uri string) bool {
+		typ := t.Type
+		if typ == "" {
+			typ = metadata.TextTypeOf(t.URI)
+		}
+		return typ != "" && typ == metadata.TextTypeOf(uri)
+	})
+}
+
 // WriteResourceContent creates a resource .yaml file. An existing resource will be overwritten if force==true
 func WriteResourceContent(ctx context.Context, path string, create, force bool, rsrc interface{}) error {
+	data, err := yaml.Marshal(rsrc)
+	if err != nil {
+		return err
+	}
+	return writeResourceData(ctx, path, create, force, data)
+}
 
-	exists := true
-
-	bkt := ds.Storage().Bucket(podops.BucketProduction)
-	obj := bkt.Object(path)
+// writeResourceData writes the .yaml of a resource, following the same rules as WriteResourceContent
+func writeResourceData(ctx context.Context, path string, create, force bool, data []byte) error {
 
-	_, err := obj.Attrs(ctx)
-	if err == storage.ErrObjectNotExist {
-		exists = false
+	exists, err := DefaultBlobStore().Exists(ctx, path)
+	if err != nil {
+		return err
 	}
 
 	// some logic mangling here ...
@@ -258,29 +298,13 @@ func WriteResourceContent(ctx context.Context, path string, create, force bool,
 		return fmt.Errorf(messagedef.MsgResourceNotFound, path)
 	}
 
-	data, err := yaml.Marshal(rsrc)
-	if err != nil {
-		return err
-	}
-
-	writer := obj.NewWriter(ctx)
-	defer writer.Close()
-	if _, err := writer.Write(data); err != nil {
-		return err
-	}
-
-	return nil
+	return DefaultBlobStore().Write(ctx, path, data)
 }
 
-// ReadResourceContent reads a resource from the Cloud Storage
+// ReadResourceContent reads a resource from the production bucket
 func ReadResourceContent(ctx context.Context, path string) (interface{}, string, string, error) {
 
-	bkt := ds.Storage().Bucket(podops.BucketProduction)
-	reader, err := bkt.Object(path).NewReader(ctx)
-	if err != nil {
-		return nil, "", "", err
-	}
-	data, err := ioutil.ReadAll(reader)
+	data, err := DefaultBlobStore().Read(ctx, path)
 	if err != nil {
 		return nil, "", "", err
 	}
@@ -288,27 +312,21 @@ func ReadResourceContent(ctx context.Context, path string) (interface{}, string,
 	return loader.UnmarshalResource(data)
 }
 
-// RemoveResourceContent removes a resource from Cloud Storage
+// RemoveResourceContent removes a resource from the production bucket
 func RemoveResourceContent(ctx context.Context, location string) error {
-	bkt := ds.Storage().Bucket(podops.BucketProduction)
-
-	obj := bkt.Object(location)
-	_, err := obj.Attrs(ctx)
-	if err == storage.ErrObjectNotExist {
-		return errordef.ErrNoSuchResource
+	if err := DefaultBlobStore().Remove(ctx, location); err != nil {
+		if err == errordef.ErrNoSuchObject {
+			return errordef.ErrNoSuchResource
+		}
+		return err
 	}
-
-	return bkt.Object(location).Delete(ctx)
+	return nil
 }
 
 // updateResource does what the name suggests
 func updateResource(ctx context.Context, r *podops.Resource) error {
-	if _, err := ds.DataStore().Put(ctx, resourceKey(r.GUID), r); err != nil {
+	if err := DefaultRepository().Put(ctx, datastoreResources, r.GUID, r); err != nil {
 		return err
 	}
 	return nil
 }
-
-func resourceKey(guid string) *datastore.Key {
-	return datastore.NameKey(datastoreResources, guid, nil)
-}
@@ -0,0 +1,28 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+)
+
+func TestResolveTranscripts(t *testing.T) {
+	episode := podops.DefaultEpisode("episode1", "simple-podcast", "episode-guid", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	episode.Transcripts = []podops.Asset{
+		{URI: "episode1.srt", Rel: podops.ResourceTypeLocal},
+		{URI: "episode1.vtt", Type: podops.TranscriptTypeVTT, Rel: podops.ResourceTypeLocal},
+		{URI: "https://example.com/episode1.html", Rel: podops.ResourceTypeExternal},
+	}
+
+	// the inventory only knows about a renamed .vtt file and the .srt file, in a different order
+	resolveTranscripts(episode, []string{
+		podops.DefaultStorageEndpoint + "/show-guid/captions.vtt",
+		podops.DefaultStorageEndpoint + "/show-guid/episode1.srt",
+	})
+
+	assert.Equal(t, podops.DefaultStorageEndpoint+"/show-guid/episode1.srt", episode.Transcripts[0].URI) // same resolved URI
+	assert.Equal(t, podops.DefaultStorageEndpoint+"/show-guid/captions.vtt", episode.Transcripts[1].URI) // same MIME type
+	assert.Equal(t, "https://example.com/episode1.html", episode.Transcripts[2].URI)                     // no match
+}
@@ -0,0 +1,241 @@
+package backend
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+	"gopkg.in/yaml.v2"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+const (
+	// DatastoreRevisions collection REVISIONS
+	datastoreRevisions = "REVISIONS"
+
+	// maxRevisionAttempts limits the retries of concurrent writers to claim a revision number
+	maxRevisionAttempts = 5
+)
+
+// WriteRevision writes the .yaml of a show or episode like WriteResourceContent and keeps a copy of it as a new revision.
+// Writing the same content again does not create a new revision.
+//
+// If match is not empty, the write only succeeds if the latest revision has one of the versions in match, or if match
+// contains "*" and the resource has a revision. The comparison and the allocation of the new revision happen in
+// one transaction, errordef.ErrPreconditionFailed is returned if another write got in between.
+func WriteRevision(ctx context.Context, production, guid, author, path string, create, force bool, match []string, rsrc interface{}) (*podops.Revision, error) {
+	data, err := yaml.Marshal(rsrc)
+	if err != nil {
+		return nil, err
+	}
+
+	// resources written before revisions were kept get their current content as the base revision
+	if err := snapshotRevision(ctx, production, guid, path); err != nil {
+		return nil, err
+	}
+
+	r, created, err := allocateRevision(ctx, production, guid, author, match, data)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeResourceData(ctx, path, create, force, data); err != nil {
+		if created {
+			removeRevision(ctx, r)
+		}
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetRevision returns a revision of a resource, or nil if it does not exist
+func GetRevision(ctx context.Context, guid string, revision int) (*podops.Revision, error) {
+	var r podops.Revision
+
+	if err := DefaultRepository().Get(ctx, datastoreRevisions, revisionKey(guid, revision), &r); err != nil {
+		if err == errordef.ErrNoSuchEntity {
+			return nil, nil // not found is not an error
+		}
+		return nil, err
+	}
+	return &r, nil
+}
+
+// ListRevisions returns all revisions of a resource, latest first
+func ListRevisions(ctx context.Context, guid string) ([]*podops.Revision, error) {
+	var r []*podops.Revision
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreRevisions).Filter("GUID =", guid).Order("-Revision"), &r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// ReadRevisionContent returns the .yaml of a revision
+func ReadRevisionContent(ctx context.Context, r *podops.Revision) ([]byte, error) {
+	data, err := DefaultBlobStore().Read(ctx, r.Location)
+	if err != nil {
+		if err == errordef.ErrNoSuchObject {
+			return nil, errordef.ErrNoSuchRevision
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// MatchVersion returns true if version is one of the versions in match. "*" matches any version.
+func MatchVersion(version string, match []string) bool {
+	for _, m := range match {
+		if m == "*" || m == version {
+			return true
+		}
+	}
+	return false
+}
+
+// ResourceVersion returns the hash of the current .yaml of a show or episode, the same value as the hash of its latest revision.
+// It returns an empty string if the resource does not exist or has no .yaml.
+func ResourceVersion(ctx context.Context, guid string) (string, error) {
+	r, err := GetResource(ctx, guid)
+	if err != nil {
+		return "", err
+	}
+	if r == nil || (r.Kind != podops.ResourceShow && r.Kind != podops.ResourceEpisode) {
+		return "", nil
+	}
+
+	data, err := DefaultBlobStore().Read(ctx, r.Location)
+	if err != nil {
+		if err == errordef.ErrNoSuchObject {
+			return "", nil
+		}
+		return "", err
+	}
+	return contentHash(data), nil
+}
+
+// allocateRevision claims the next revision number of a resource and stores data as its content.
+// The number is claimed in a transaction, concurrent writers get consecutive revisions unless
+// they expect a version, see WriteRevision. The latest revision is returned if it has the same content, created is false then.
+func allocateRevision(ctx context.Context, production, guid, author string, match []string, data []byte) (*podops.Revision, bool, error) {
+	hash := contentHash(data)
+
+	for attempt := 0; attempt < maxRevisionAttempts; attempt++ {
+		latest, err := ListRevisions(ctx, guid)
+		if err != nil {
+			return nil, false, err
+		}
+
+		r := podops.Revision{
+			GUID:       guid,
+			ParentGUID: production,
+			Revision:   1,
+			Author:     author,
+			Hash:       hash,
+			Created:    timestamp.Now(),
+		}
+		if len(match) > 0 && (len(latest) == 0 || !MatchVersion(latest[0].Hash, match)) {
+			return nil, false, errordef.ErrPreconditionFailed
+		}
+		if len(latest) > 0 {
+			if latest[0].Hash == hash {
+				return latest[0], false, nil // nothing changed
+			}
+			r.Revision = latest[0].Revision + 1
+		}
+
+		if err := insertRevision(ctx, &r, data); err != nil {
+			if err == errordef.ErrEntityExists {
+				if len(match) > 0 {
+					return nil, false, errordef.ErrPreconditionFailed // the version changed in the meantime
+				}
+				continue // someone else was faster, try the next number
+			}
+			return nil, false, err
+		}
+		return &r, true, nil
+	}
+	return nil, false, errordef.ErrPreconditionFailed
+}
+
+// snapshotRevision keeps the content of a resource that has no revisions yet as its first revision
+func snapshotRevision(ctx context.Context, production, guid, path string) error {
+	l, err := ListRevisions(ctx, guid)
+	if err != nil || len(l) > 0 {
+		return err
+	}
+
+	data, err := DefaultBlobStore().Read(ctx, path)
+	if err != nil {
+		if err == errordef.ErrNoSuchObject {
+			return nil // a new resource
+		}
+		return err
+	}
+
+	r := podops.Revision{
+		GUID:       guid,
+		ParentGUID: production,
+		Revision:   1,
+		Hash:       contentHash(data),
+		Created:    timestamp.Now(),
+	}
+	if err := insertRevision(ctx, &r, data); err != nil && err != errordef.ErrEntityExists {
+		return err
+	}
+	return nil
+}
+
+// insertRevision stores a revision unless its number is already taken
+func insertRevision(ctx context.Context, r *podops.Revision, data []byte) error {
+	r.Location = fmt.Sprintf("%s/revisions/%s/%d.yaml", r.ParentGUID, r.GUID, r.Revision)
+
+	if err := DefaultRepository().Insert(ctx, datastoreRevisions, revisionKey(r.GUID, r.Revision), r); err != nil {
+		return err
+	}
+	if err := DefaultBlobStore().Write(ctx, r.Location, data); err != nil {
+		DefaultRepository().Delete(ctx, datastoreRevisions, revisionKey(r.GUID, r.Revision))
+		return err
+	}
+	return nil
+}
+
+// removeRevision removes a revision that was allocated for a write that failed
+func removeRevision(ctx context.Context, r *podops.Revision) {
+	if err := DefaultBlobStore().Remove(ctx, r.Location); err != nil && err != errordef.ErrNoSuchObject {
+		platform.ReportError(err)
+	}
+	if err := DefaultRepository().Delete(ctx, datastoreRevisions, revisionKey(r.GUID, r.Revision)); err != nil {
+		platform.ReportError(err)
+	}
+}
+
+// deleteRevisions removes all revisions of a resource
+func deleteRevisions(ctx context.Context, guid string) error {
+	l, err := ListRevisions(ctx, guid)
+	if err != nil {
+		return err
+	}
+	for _, r := range l {
+		if err := DefaultBlobStore().Remove(ctx, r.Location); err != nil && err != errordef.ErrNoSuchObject {
+			return err
+		}
+		if err := DefaultRepository().Delete(ctx, datastoreRevisions, revisionKey(guid, r.Revision)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func contentHash(data []byte) string {
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+func revisionKey(guid string, revision int) string {
+	return fmt.Sprintf("%s.%d", guid, revision)
+}
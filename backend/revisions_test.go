@@ -0,0 +1,107 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+func TestLocalRevisions(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	episode := podops.DefaultEpisode("episode1", "revised-podcast", "episode-guid", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	location := "show-guid/episode-episode-guid.yaml"
+
+	r1, err := WriteRevision(ctx, "show-guid", "episode-guid", "jane", location, true, false, nil, episode)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 1, r1.Revision)
+
+	// the same content again is not a new revision, creating it again is not allowed
+	r, err := WriteRevision(ctx, "show-guid", "episode-guid", "john", location, false, false, nil, episode)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, r.Revision)
+		assert.Equal(t, "jane", r.Author)
+	}
+	_, err = WriteRevision(ctx, "show-guid", "episode-guid", "john", location, true, false, nil, episode)
+	assert.Error(t, err)
+
+	episode.Description.Summary = "new show notes"
+	r2, err := WriteRevision(ctx, "show-guid", "episode-guid", "john", location, false, false, nil, episode)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, r2.Revision)
+	assert.NotEqual(t, r1.Hash, r2.Hash)
+
+	// writes that expect a version fail once someone else wrote a revision
+	episode.Description.Summary = "stale show notes"
+	_, err = WriteRevision(ctx, "show-guid", "episode-guid", "jane", location, false, false, []string{r1.Hash}, episode)
+	assert.Equal(t, errordef.ErrPreconditionFailed, err)
+	episode.Description.Summary = "new show notes"
+	r, err = WriteRevision(ctx, "show-guid", "episode-guid", "jane", location, false, false, []string{"*"}, episode)
+	if assert.NoError(t, err) {
+		assert.Equal(t, r2.Revision, r.Revision)
+	}
+
+	l, err := ListRevisions(ctx, "episode-guid")
+	if assert.NoError(t, err) && assert.Equal(t, 2, len(l)) {
+		assert.Equal(t, 2, l[0].Revision)
+		assert.Equal(t, "john", l[0].Author)
+	}
+
+	r, err = GetRevision(ctx, "episode-guid", 1)
+	if assert.NoError(t, err) && assert.NotNil(t, r) {
+		data, err := ReadRevisionContent(ctx, r)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "new show notes")
+	}
+	r, err = GetRevision(ctx, "episode-guid", 3)
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+
+	// the version of a resource is the hash of its latest revision
+	version, err := ResourceVersion(ctx, "episode-guid")
+	if assert.NoError(t, err) {
+		assert.Empty(t, version) // not in the inventory yet
+	}
+	assert.NoError(t, UpdateResource(ctx, "episode1", "episode-guid", podops.ResourceEpisode, "show-guid", location))
+	version, err = ResourceVersion(ctx, "episode-guid")
+	if assert.NoError(t, err) {
+		assert.Equal(t, r2.Hash, version)
+	}
+}
+
+func TestLocalBaseRevision(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	// revision numbers are claimed in a transaction
+	r := podops.Revision{GUID: "claimed-guid", Revision: 1}
+	assert.NoError(t, DefaultRepository().Insert(ctx, datastoreRevisions, revisionKey(r.GUID, r.Revision), &r))
+	assert.Equal(t, errordef.ErrEntityExists, DefaultRepository().Insert(ctx, datastoreRevisions, revisionKey(r.GUID, r.Revision), &r))
+
+	// a resource written before revisions were kept
+	location := "show-guid/episode-legacy-guid.yaml"
+	assert.NoError(t, DefaultBlobStore().Write(ctx, location, []byte("legacy: true\n")))
+
+	episode := podops.DefaultEpisode("legacy", "revised-podcast", "legacy-guid", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	r2, err := WriteRevision(ctx, "show-guid", "legacy-guid", "jane", location, false, false, nil, episode)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 2, r2.Revision)
+
+	base, err := GetRevision(ctx, "legacy-guid", 1)
+	if assert.NoError(t, err) && assert.NotNil(t, base) {
+		data, err := ReadRevisionContent(ctx, base)
+		assert.NoError(t, err)
+		assert.Equal(t, "legacy: true\n", string(data))
+	}
+}
@@ -0,0 +1,69 @@
+package backend
+
+import (
+	"context"
+	"sort"
+
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+// ListScheduledEpisodes returns all episodes of a production with a publish date after now, in ascending order
+func ListScheduledEpisodes(ctx context.Context, production string, now int64) ([]*podops.Resource, error) {
+	l, err := ListResources(ctx, production, podops.ResourceEpisode)
+	if err != nil {
+		return nil, err
+	}
+
+	var episodes []*podops.Resource
+	for _, r := range l {
+		if r.Published > now {
+			episodes = append(episodes, r)
+		}
+	}
+	sort.SliceStable(episodes, func(i, j int) bool {
+		return episodes[i].Published < episodes[j].Published
+	})
+
+	return episodes, nil
+}
+
+// UpdateSchedule sets the production's NextPublishDate to the publish date of its next scheduled episode, or 0 if there is none.
+// Archived productions have no schedule.
+func UpdateSchedule(ctx context.Context, production string) error {
+	p, err := GetProduction(ctx, production)
+	if err != nil {
+		return err
+	}
+	if p == nil {
+		return errordef.ErrNoSuchProduction
+	}
+
+	episodes, err := ListScheduledEpisodes(ctx, production, timestamp.Now())
+	if err != nil {
+		return err
+	}
+
+	next := int64(0)
+	if len(episodes) > 0 && !p.Archived {
+		next = episodes[0].Published
+	}
+	if next == p.NextPublishDate {
+		return nil // nothing changed
+	}
+
+	p.NextPublishDate = next
+	return UpdateProduction(ctx, p)
+}
+
+// ListDueProductions returns all productions with a scheduled episode that should have been published by now
+func ListDueProductions(ctx context.Context, now int64) ([]*podops.Production, error) {
+	var p []*podops.Production
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreProductions).Filter("NextPublishDate >", 0).Filter("NextPublishDate <=", now), &p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
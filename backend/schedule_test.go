@@ -0,0 +1,52 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+)
+
+func TestLocalSchedule(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	p, err := CreateProduction(ctx, "scheduled-podcast", "title", "summary", "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// one published, two scheduled episodes
+	now := time.Now()
+	for i, d := range []time.Duration{-time.Hour, 2 * time.Hour, time.Hour} {
+		guid := podops.CreateGUID()
+		episode := podops.DefaultEpisode(fmt.Sprintf("episode%d", i), p.Name, guid, p.GUID, podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+		episode.Metadata.Labels[podops.LabelDate] = now.Add(d).Format(time.RFC1123Z)
+		assert.NoError(t, UpdateEpisode(ctx, fmt.Sprintf("%s/episode-%s.yaml", p.GUID, guid), episode))
+	}
+
+	episodes, err := ListScheduledEpisodes(ctx, p.GUID, now.Unix())
+	if assert.NoError(t, err) && assert.Equal(t, 2, len(episodes)) {
+		assert.Equal(t, "episode2", episodes[0].Name)
+		assert.Equal(t, "episode1", episodes[1].Name)
+	}
+
+	assert.NoError(t, UpdateSchedule(ctx, p.GUID))
+	p, err = GetProduction(ctx, p.GUID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, episodes[0].Published, p.NextPublishDate)
+	}
+
+	due, err := ListDueProductions(ctx, now.Unix())
+	if assert.NoError(t, err) {
+		assert.Empty(t, due)
+	}
+	due, err = ListDueProductions(ctx, now.Add(90*time.Minute).Unix())
+	if assert.NoError(t, err) && assert.Equal(t, 1, len(due)) {
+		assert.Equal(t, p.GUID, due[0].GUID)
+	}
+}
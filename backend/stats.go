@@ -0,0 +1,242 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/analytics"
+	"github.com/podops/podops/internal/errordef"
+)
+
+const (
+	// DatastoreListeners collection LISTENERS, the de-duplication state per listener and episode
+	datastoreListeners = "LISTENERS"
+	// DatastoreStats collection STATS, downloads per episode and day
+	datastoreStats = "STATS"
+	// DatastorePopularity collection POPULARITY, the downloads per production in the last PopularityDays days
+	datastorePopularity = "POPULARITY"
+
+	// PopularityDays popularity is based on the downloads of the last PopularityDays days
+	PopularityDays = 30
+)
+
+type (
+	// listener tracks the bytes a listener downloaded of an episode within the IAB de-duplication window
+	listener struct {
+		Key     string
+		Start   int64 // beginning of the window
+		Expires int64 // end of the window, the listener can be removed afterwards
+		Bytes   int64
+		Counted bool // true once the download was counted
+	}
+
+	// popularity is the rollup of a production's downloads used to rank the productions
+	popularity struct {
+		GUID      string
+		Downloads int64
+		Updated   int64
+	}
+)
+
+// RecordRequest adds a request of an episode's enclosure to the download statistics.
+//
+// Requests are counted as downloads following the IAB v2 guidelines: bots are ignored, and
+// all requests of the same listener within 24h count as one download once enough of the file was served.
+func RecordRequest(ctx context.Context, r *analytics.Request) error {
+	stats, err := getStats(ctx, r.Production, r.Asset, r.Day())
+	if err != nil {
+		return err
+	}
+	if stats == nil {
+		return nil // not the enclosure of an episode
+	}
+
+	stats.Requests++
+	if analytics.IsBot(r.UserAgent) {
+		stats.Bots++
+	}
+
+	if r.IsCandidate() {
+		stats.Bytes += r.Bytes
+
+		key := r.Listener()
+		var l listener
+		if err := DefaultRepository().Get(ctx, datastoreListeners, key, &l); err != nil && err != errordef.ErrNoSuchEntity {
+			return err
+		}
+		if l.Start == 0 || r.Timestamp >= l.Expires {
+			l = listener{Key: key, Start: r.Timestamp, Expires: r.Timestamp + int64(analytics.DedupWindow.Seconds())} // a new window
+		}
+		if !l.Counted {
+			l.Bytes += r.Bytes
+			if l.Bytes >= r.Threshold() {
+				l.Counted = true
+				stats.Downloads++
+			}
+		}
+		if err := DefaultRepository().Put(ctx, datastoreListeners, key, &l); err != nil {
+			return err
+		}
+	}
+
+	return DefaultRepository().Put(ctx, datastoreStats, statsKey(r.Production, r.Asset, stats.Day), stats)
+}
+
+// ListStats returns the downloads of a production, or one of its episodes, since a day, e.g. '2021-03-01', in ascending order
+func ListStats(ctx context.Context, production, episode, since string) ([]*podops.DownloadStats, error) {
+	var stats []*podops.DownloadStats
+
+	q := NewQuery(datastoreStats).Filter("GUID =", production)
+	if episode != "" {
+		q = q.Filter("Episode =", episode)
+	}
+	if err := DefaultRepository().GetAll(ctx, q.Filter("Day >=", since).Order("Day"), &stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// CountDownloads returns the downloads of a production, or one of its episodes, since a day
+func CountDownloads(ctx context.Context, production, episode, since string) (int64, error) {
+	stats, err := ListStats(ctx, production, episode, since)
+	if err != nil {
+		return 0, err
+	}
+
+	n := int64(0)
+	for _, s := range stats {
+		n += s.Downloads
+	}
+	return n, nil
+}
+
+// PurgeListeners removes the de-duplication state of all listeners whose window ended before now
+func PurgeListeners(ctx context.Context, now int64) (int, error) {
+	var listeners []*listener
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreListeners).Filter("Expires <", now), &listeners); err != nil {
+		return 0, err
+	}
+	for _, l := range listeners {
+		if err := DefaultRepository().Delete(ctx, datastoreListeners, l.Key); err != nil {
+			return 0, err
+		}
+	}
+	return len(listeners), nil
+}
+
+// RollupPopularity sums up the downloads of each production in the last PopularityDays days.
+// Productions without downloads are removed from the rollup.
+func RollupPopularity(ctx context.Context, now int64) error {
+	var stats []*podops.DownloadStats
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreStats).Filter("Day >=", analytics.Since(PopularityDays)), &stats); err != nil {
+		return err
+	}
+	downloads := make(map[string]int64)
+	for _, s := range stats {
+		downloads[s.GUID] += s.Downloads
+	}
+
+	var rollup []*popularity
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastorePopularity), &rollup); err != nil {
+		return err
+	}
+	for _, r := range rollup {
+		if downloads[r.GUID] == 0 {
+			if err := DefaultRepository().Delete(ctx, datastorePopularity, r.GUID); err != nil {
+				return err
+			}
+		}
+	}
+
+	for guid, n := range downloads {
+		if n == 0 {
+			continue
+		}
+		if err := DefaultRepository().Put(ctx, datastorePopularity, guid, &popularity{GUID: guid, Downloads: n, Updated: now}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListPopularProductions returns the published productions with the most downloads in the last 30 days, see RollupPopularity.
+// Recently built productions fill up the list if there are not enough downloads yet.
+func ListPopularProductions(ctx context.Context, limit int) ([]*podops.Production, error) {
+	var rollup []*popularity
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastorePopularity).Order("-Downloads"), &rollup); err != nil {
+		return nil, err
+	}
+	guids := make([]string, len(rollup))
+	for i, r := range rollup {
+		guids[i] = r.GUID
+	}
+
+	shows := make([]*podops.Production, 0, limit)
+	included := make(map[string]bool)
+	for _, guid := range guids {
+		if len(shows) == limit {
+			return shows, nil
+		}
+		p, err := GetProduction(ctx, guid)
+		if err != nil {
+			return nil, err
+		}
+		if p != nil && p.Published && !p.Private && p.Deleted == 0 {
+			shows = append(shows, p)
+			included[guid] = true
+		}
+	}
+
+	recent, err := ListRecentProductions(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range recent {
+		if len(shows) == limit {
+			break
+		}
+		if !included[p.GUID] && !p.Private {
+			shows = append(shows, p)
+		}
+	}
+	return shows, nil
+}
+
+// getStats returns the statistics of an asset on a day. It returns nil if the asset is not the enclosure of an episode.
+func getStats(ctx context.Context, production, asset, day string) (*podops.DownloadStats, error) {
+	var stats podops.DownloadStats
+
+	err := DefaultRepository().Get(ctx, datastoreStats, statsKey(production, asset, day), &stats)
+	if err == nil {
+		return &stats, nil
+	}
+	if err != errordef.ErrNoSuchEntity {
+		return nil, err
+	}
+
+	// first request of the day, find the episode
+	var episodes []*podops.Resource
+	uri := fmt.Sprintf("%s/%s/%s", podops.DefaultStorageEndpoint, production, asset)
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreResources).Filter("ParentGUID =", production).Filter("Kind =", podops.ResourceEpisode).Filter("EnclosureURI =", uri), &episodes); err != nil {
+		return nil, err
+	}
+	if len(episodes) == 0 {
+		return nil, nil
+	}
+
+	return &podops.DownloadStats{
+		GUID:    production,
+		Episode: episodes[0].GUID,
+		Name:    episodes[0].Name,
+		Asset:   asset,
+		Day:     day,
+	}, nil
+}
+
+func statsKey(production, asset, day string) string {
+	return fmt.Sprintf("%s/%s/%s", production, asset, day)
+}
@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/analytics"
+)
+
+func TestLocalStats(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	p, err := CreateProduction(ctx, "popular-podcast", "title", "summary", "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+	e := podops.Resource{
+		Name:         "episode1",
+		GUID:         "e1",
+		Kind:         podops.ResourceEpisode,
+		ParentGUID:   p.GUID,
+		EnclosureURI: fmt.Sprintf("%s/%s/episode1.mp3", podops.DefaultStorageEndpoint, p.GUID),
+	}
+	assert.NoError(t, DefaultRepository().Put(ctx, datastoreResources, e.GUID, &e))
+
+	now := time.Now().Unix()
+	requests := []*analytics.Request{
+		{UserAgent: "curl/7.64.1", Status: 200, Bytes: 2 * analytics.MinDownloadBytes},                  // bot
+		{UserAgent: "Podcasts/1.0", Range: "bytes=0-1", Status: 206, Bytes: 2},                          // probe
+		{UserAgent: "Podcasts/1.0", Status: 206, Bytes: analytics.MinDownloadBytes / 2},                 // not enough yet
+		{UserAgent: "Podcasts/1.0", Status: 206, Bytes: analytics.MinDownloadBytes / 2},                 // counted
+		{UserAgent: "Podcasts/1.0", Status: 200, Bytes: analytics.MinDownloadBytes},                     // same listener
+		{UserAgent: "Overcast/3.0", Status: 200, Bytes: analytics.MinDownloadBytes},                     // another listener
+		{UserAgent: "Overcast/3.0", Status: 200, Bytes: analytics.MinDownloadBytes, Asset: "cover.png"}, // not an enclosure
+	}
+	for _, r := range requests {
+		r.Production = p.GUID
+		r.RemoteAddr = "10.0.0.1:4321"
+		r.Timestamp = now
+		if r.Asset == "" {
+			r.Asset = "episode1.mp3"
+		}
+		assert.NoError(t, RecordRequest(ctx, r))
+	}
+
+	stats, err := ListStats(ctx, p.GUID, e.GUID, analytics.Since(1))
+	if assert.NoError(t, err) && assert.Equal(t, 1, len(stats)) {
+		assert.Equal(t, int64(2), stats[0].Downloads)
+		assert.Equal(t, int64(6), stats[0].Requests)
+		assert.Equal(t, int64(1), stats[0].Bots)
+	}
+
+	n, err := CountDownloads(ctx, p.GUID, "", analytics.Since(PopularityDays))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+
+	p.Published = true
+	assert.NoError(t, UpdateProduction(ctx, p))
+	assert.NoError(t, RollupPopularity(ctx, now))
+	popular, err := ListPopularProductions(ctx, 10)
+	if assert.NoError(t, err) && assert.NotEmpty(t, popular) {
+		assert.Equal(t, p.GUID, popular[0].GUID)
+	}
+
+	// listeners are kept for the de-duplication window only
+	purged, err := PurgeListeners(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, purged)
+	purged, err = PurgeListeners(ctx, now+int64(analytics.DedupWindow.Seconds())+1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, purged)
+}
@@ -0,0 +1,141 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	"github.com/txsvc/platform/v2"
+
+	"github.com/podops/podops"
+)
+
+const (
+	// StoreGoogle uses Google Cloud Datastore and Cloud Storage
+	StoreGoogle = "google"
+	// StoreLocal uses an embedded BoltDB and the local filesystem
+	StoreLocal = "local"
+)
+
+type (
+	// Repository persists the inventory of productions, resources and asset metadata.
+	//
+	// Entities are addressed by kind (e.g. PRODUCTIONS, RESOURCES, METADATA) and a unique key.
+	// Get returns errordef.ErrNoSuchEntity if the entity does not exist.
+	Repository interface {
+		Get(ctx context.Context, kind, key string, dst interface{}) error
+		Put(ctx context.Context, kind, key string, src interface{}) error
+		// Insert stores a new entity in a transaction. It returns errordef.ErrEntityExists if the key is taken.
+		Insert(ctx context.Context, kind, key string, src interface{}) error
+		Delete(ctx context.Context, kind, key string) error
+		// GetAll runs the query and appends the results to dst, a pointer to a slice of structs or struct pointers
+		GetAll(ctx context.Context, q *Query, dst interface{}) error
+		Close() error
+	}
+
+	// BlobStore persists the .yaml resources and the generated feed.xml.
+	//
+	// Read returns errordef.ErrNoSuchObject if the object does not exist.
+	BlobStore interface {
+		Read(ctx context.Context, path string) ([]byte, error)
+		Write(ctx context.Context, path string, data []byte) error
+		Exists(ctx context.Context, path string) (bool, error)
+		Remove(ctx context.Context, path string) error
+		List(ctx context.Context, prefix string) ([]string, error)
+	}
+
+	// Query describes a lookup of entities of one kind. Its methods mimic the Cloud Datastore query API.
+	Query struct {
+		Kind    string
+		Filters []Filter
+		Orders  []string
+		Max     int
+	}
+
+	// Filter restricts the result of a query, e.g. 'Published >' 0
+	Filter struct {
+		Field string
+		Op    string
+		Value interface{}
+	}
+)
+
+var (
+	repo  Repository
+	blobs BlobStore
+)
+
+// NewQuery creates a new query for entities of kind
+func NewQuery(kind string) *Query {
+	return &Query{Kind: kind}
+}
+
+// Filter adds a field-based filter, e.g. Filter("ParentGUID =", guid). Supported operators are =, <, <=, >, >=.
+func (q *Query) Filter(filterStr string, value interface{}) *Query {
+	parts := strings.Fields(filterStr)
+	f := Filter{Field: parts[0], Op: "=", Value: value}
+	if len(parts) > 1 {
+		f.Op = parts[1]
+	}
+	q.Filters = append(q.Filters, f)
+	return q
+}
+
+// Order sorts the result by fieldName. Prefix the name with '-' for descending order.
+func (q *Query) Order(fieldName string) *Query {
+	q.Orders = append(q.Orders, fieldName)
+	return q
+}
+
+// Limit sets the maximum number of results. Zero means no limit.
+func (q *Query) Limit(limit int) *Query {
+	q.Max = limit
+	return q
+}
+
+// RegisterStore replaces the default repository and blob store, e.g. for testing
+func RegisterStore(r Repository, b BlobStore) {
+	if repo != nil && repo != r {
+		repo.Close()
+	}
+	repo = r
+	blobs = b
+}
+
+// implements lazy loading, see background() for the rationale
+
+// DefaultRepository returns the repository selected by STORAGE_BACKEND
+func DefaultRepository() Repository {
+	if repo == nil {
+		initStore()
+	}
+	return repo
+}
+
+// DefaultBlobStore returns the blob store selected by STORAGE_BACKEND
+func DefaultBlobStore() BlobStore {
+	if blobs == nil {
+		initStore()
+	}
+	return blobs
+}
+
+func initStore() {
+	switch podops.StorageBackend {
+	case StoreGoogle:
+		RegisterStore(NewGoogleRepository(), NewGoogleBlobStore(podops.BucketProduction))
+	case StoreLocal:
+		r, err := NewLocalRepository(filepath.Join(podops.LocalStoreLocation, "inventory.db"))
+		if err != nil {
+			platform.ReportError(err)
+			log.Fatal(err) // same as with a missing provider, nothing will work without an inventory
+		}
+		RegisterStore(r, NewLocalBlobStore(filepath.Join(podops.LocalStoreLocation, podops.BucketProduction)))
+	default:
+		err := fmt.Errorf(platform.MsgMissingProvider, podops.StorageBackend)
+		platform.ReportError(err)
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,153 @@
+package backend
+
+import (
+	"context"
+	"io/ioutil"
+
+	"cloud.google.com/go/datastore"
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+
+	ds "github.com/txsvc/platform/v2/pkg/datastore"
+
+	"github.com/podops/podops/internal/errordef"
+)
+
+type (
+	// googleRepository implements Repository on top of Cloud Datastore
+	googleRepository struct {
+	}
+
+	// googleBlobStore implements BlobStore on top of a Cloud Storage bucket
+	googleBlobStore struct {
+		bucket string
+	}
+)
+
+var (
+	// Interface guards
+	_ Repository = (*googleRepository)(nil)
+	_ BlobStore  = (*googleBlobStore)(nil)
+)
+
+// NewGoogleRepository returns a Repository backed by Cloud Datastore
+func NewGoogleRepository() Repository {
+	return &googleRepository{}
+}
+
+// NewGoogleBlobStore returns a BlobStore backed by the Cloud Storage bucket
+func NewGoogleBlobStore(bucket string) BlobStore {
+	return &googleBlobStore{bucket: bucket}
+}
+
+func (r *googleRepository) Get(ctx context.Context, kind, key string, dst interface{}) error {
+	if err := ds.DataStore().Get(ctx, datastore.NameKey(kind, key, nil), dst); err != nil {
+		if err == datastore.ErrNoSuchEntity {
+			return errordef.ErrNoSuchEntity
+		}
+		return err
+	}
+	return nil
+}
+
+func (r *googleRepository) Put(ctx context.Context, kind, key string, src interface{}) error {
+	_, err := ds.DataStore().Put(ctx, datastore.NameKey(kind, key, nil), src)
+	return err
+}
+
+func (r *googleRepository) Insert(ctx context.Context, kind, key string, src interface{}) error {
+	_, err := ds.DataStore().RunInTransaction(ctx, func(tx *datastore.Transaction) error {
+		k := datastore.NameKey(kind, key, nil)
+
+		var existing datastore.PropertyList
+		if err := tx.Get(k, &existing); err == nil {
+			return errordef.ErrEntityExists
+		} else if err != datastore.ErrNoSuchEntity {
+			return err
+		}
+		_, err := tx.Put(k, src)
+		return err
+	})
+	return err
+}
+
+func (r *googleRepository) Delete(ctx context.Context, kind, key string) error {
+	return ds.DataStore().Delete(ctx, datastore.NameKey(kind, key, nil))
+}
+
+func (r *googleRepository) GetAll(ctx context.Context, q *Query, dst interface{}) error {
+	dq := datastore.NewQuery(q.Kind)
+	for _, f := range q.Filters {
+		dq = dq.Filter(f.Field+" "+f.Op, f.Value)
+	}
+	for _, o := range q.Orders {
+		dq = dq.Order(o)
+	}
+	if q.Max > 0 {
+		dq = dq.Limit(q.Max)
+	}
+	_, err := ds.DataStore().GetAll(ctx, dq, dst)
+	return err
+}
+
+func (r *googleRepository) Close() error {
+	return nil // the datastore client is owned by the platform
+}
+
+func (b *googleBlobStore) Read(ctx context.Context, path string) ([]byte, error) {
+	reader, err := ds.Storage().Bucket(b.bucket).Object(path).NewReader(ctx)
+	if err != nil {
+		if err == storage.ErrObjectNotExist {
+			return nil, errordef.ErrNoSuchObject
+		}
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ioutil.ReadAll(reader)
+}
+
+func (b *googleBlobStore) Write(ctx context.Context, path string, data []byte) error {
+	writer := ds.Storage().Bucket(b.bucket).Object(path).NewWriter(ctx)
+	if _, err := writer.Write(data); err != nil {
+		writer.Close()
+		return err
+	}
+	return writer.Close()
+}
+
+func (b *googleBlobStore) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := ds.Storage().Bucket(b.bucket).Object(path).Attrs(ctx)
+	if err == storage.ErrObjectNotExist {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (b *googleBlobStore) Remove(ctx context.Context, path string) error {
+	err := ds.Storage().Bucket(b.bucket).Object(path).Delete(ctx)
+	if err == storage.ErrObjectNotExist {
+		return errordef.ErrNoSuchObject
+	}
+	return err
+}
+
+func (b *googleBlobStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+
+	it := ds.Storage().Bucket(b.bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, attrs.Name)
+	}
+	return names, nil
+}
@@ -0,0 +1,396 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/messagedef"
+)
+
+const (
+	// wait this long for the file lock of the embedded database
+	localLockTimeout = 5 * time.Second
+)
+
+type (
+	// localRepository implements Repository using an embedded BoltDB.
+	//
+	// The database is opened for every transaction and closed right after. This allows the API
+	// and the CDN service to share the same inventory when running on a single machine.
+	localRepository struct {
+		path string
+	}
+
+	// localBlobStore implements BlobStore on the local filesystem
+	localBlobStore struct {
+		root string
+	}
+)
+
+var (
+	// Interface guards
+	_ Repository = (*localRepository)(nil)
+	_ BlobStore  = (*localBlobStore)(nil)
+)
+
+// NewLocalRepository returns a Repository backed by the BoltDB file at path
+func NewLocalRepository(path string) (Repository, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+	// create the database file if it does not exist yet
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: localLockTimeout})
+	if err != nil {
+		return nil, err
+	}
+	if err := db.Close(); err != nil {
+		return nil, err
+	}
+	return &localRepository{path: path}, nil
+}
+
+// NewLocalBlobStore returns a BlobStore that keeps its objects below root
+func NewLocalBlobStore(root string) BlobStore {
+	return &localBlobStore{root: root}
+}
+
+func (r *localRepository) Get(ctx context.Context, kind, key string, dst interface{}) error {
+	return r.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(kind))
+		if b == nil {
+			return errordef.ErrNoSuchEntity
+		}
+		data := b.Get([]byte(key))
+		if data == nil {
+			return errordef.ErrNoSuchEntity
+		}
+		return gob.NewDecoder(bytes.NewReader(data)).Decode(dst)
+	})
+}
+
+func (r *localRepository) Put(ctx context.Context, kind, key string, src interface{}) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(src); err != nil {
+		return err
+	}
+
+	return r.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(kind))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), buf.Bytes())
+	})
+}
+
+func (r *localRepository) Insert(ctx context.Context, kind, key string, src interface{}) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(src); err != nil {
+		return err
+	}
+
+	return r.update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(kind))
+		if err != nil {
+			return err
+		}
+		if b.Get([]byte(key)) != nil {
+			return errordef.ErrEntityExists
+		}
+		return b.Put([]byte(key), buf.Bytes())
+	})
+}
+
+func (r *localRepository) Delete(ctx context.Context, kind, key string) error {
+	return r.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(kind))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+func (r *localRepository) GetAll(ctx context.Context, q *Query, dst interface{}) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.Elem().Kind() != reflect.Slice {
+		return errordef.ErrInvalidParameters
+	}
+	slice := dv.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	var result []reflect.Value
+
+	err := r.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(q.Kind))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, data []byte) error {
+			e := reflect.New(elemType)
+			if err := gob.NewDecoder(bytes.NewReader(data)).DecodeValue(e); err != nil {
+				return err
+			}
+			ok, err := matchFilters(e.Elem(), q.Filters)
+			if err != nil {
+				return err
+			}
+			if ok {
+				result = append(result, e)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(q.Orders) > 0 {
+		var sortErr error
+		sort.SliceStable(result, func(i, j int) bool {
+			for _, o := range q.Orders {
+				field := strings.TrimPrefix(o, "-")
+				c, err := compareValues(result[i].Elem().FieldByName(field), result[j].Elem().FieldByName(field).Interface())
+				if err != nil {
+					sortErr = err
+					return false
+				}
+				if c != 0 {
+					if strings.HasPrefix(o, "-") {
+						return c > 0
+					}
+					return c < 0
+				}
+			}
+			return false
+		})
+		if sortErr != nil {
+			return sortErr
+		}
+	}
+
+	if q.Max > 0 && len(result) > q.Max {
+		result = result[:q.Max]
+	}
+
+	for _, e := range result {
+		if isPtr {
+			slice = reflect.Append(slice, e)
+		} else {
+			slice = reflect.Append(slice, e.Elem())
+		}
+	}
+	dv.Elem().Set(slice)
+
+	return nil
+}
+
+func (r *localRepository) Close() error {
+	return nil // nothing to do, the database is only opened for the duration of a transaction
+}
+
+func (r *localRepository) view(fn func(*bolt.Tx) error) error {
+	db, err := bolt.Open(r.path, 0600, &bolt.Options{Timeout: localLockTimeout, ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.View(fn)
+}
+
+func (r *localRepository) update(fn func(*bolt.Tx) error) error {
+	db, err := bolt.Open(r.path, 0600, &bolt.Options{Timeout: localLockTimeout})
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return db.Update(fn)
+}
+
+// matchFilters evaluates all filters against struct value v
+func matchFilters(v reflect.Value, filters []Filter) (bool, error) {
+	for _, f := range filters {
+		fv := v.FieldByName(f.Field)
+		if !fv.IsValid() {
+			return false, fmt.Errorf(messagedef.MsgUnknownField, f.Field, v.Type().Name())
+		}
+		ok, err := matchFilter(fv, f)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// matchFilter evaluates a filter against field value fv. Like in Datastore, a filter on a list matches if any of its elements matches.
+func matchFilter(fv reflect.Value, f Filter) (bool, error) {
+	if fv.Kind() == reflect.Slice {
+		for i := 0; i < fv.Len(); i++ {
+			ok, err := matchFilter(fv.Index(i), f)
+			if err != nil || ok {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+
+	c, err := compareValues(fv, f.Value)
+	if err != nil {
+		return false, err
+	}
+
+	switch f.Op {
+	case "=":
+		return c == 0, nil
+	case "<":
+		return c < 0, nil
+	case "<=":
+		return c <= 0, nil
+	case ">":
+		return c > 0, nil
+	case ">=":
+		return c >= 0, nil
+	}
+	return false, fmt.Errorf(messagedef.MsgUnsupportedOperator, f.Op)
+}
+
+// compareValues returns -1, 0 or 1 if a is less, equal or greater than b
+func compareValues(a reflect.Value, b interface{}) (int, error) {
+	bv := reflect.ValueOf(b)
+
+	switch a.Kind() {
+	case reflect.String:
+		if bv.Kind() != reflect.String {
+			break
+		}
+		return strings.Compare(a.String(), bv.String()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var y int64
+		switch bv.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			y = bv.Int()
+		default:
+			return 0, fmt.Errorf(messagedef.MsgTypeMismatch, a.Kind(), bv.Kind())
+		}
+		x := a.Int()
+		if x < y {
+			return -1, nil
+		} else if x > y {
+			return 1, nil
+		}
+		return 0, nil
+	case reflect.Bool:
+		if bv.Kind() != reflect.Bool {
+			break
+		}
+		if a.Bool() == bv.Bool() {
+			return 0, nil
+		}
+		if !a.Bool() {
+			return -1, nil
+		}
+		return 1, nil
+	}
+	return 0, fmt.Errorf(messagedef.MsgTypeMismatch, a.Kind(), bv.Kind())
+}
+
+func (b *localBlobStore) Read(ctx context.Context, path string) ([]byte, error) {
+	data, err := ioutil.ReadFile(b.location(path))
+	if os.IsNotExist(err) {
+		return nil, errordef.ErrNoSuchObject
+	}
+	return data, err
+}
+
+// Write replaces the object atomically by writing to a temporary file first
+func (b *localBlobStore) Write(ctx context.Context, path string, data []byte) error {
+	location := b.location(path)
+	if err := os.MkdirAll(filepath.Dir(location), os.ModePerm); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(location), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), location)
+}
+
+func (b *localBlobStore) Exists(ctx context.Context, path string) (bool, error) {
+	_, err := os.Stat(b.location(path))
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (b *localBlobStore) Remove(ctx context.Context, path string) error {
+	err := os.Remove(b.location(path))
+	if os.IsNotExist(err) {
+		return errordef.ErrNoSuchObject
+	}
+	return err
+}
+
+func (b *localBlobStore) List(ctx context.Context, prefix string) ([]string, error) {
+	var names []string
+
+	err := filepath.Walk(b.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if info.IsDir() || strings.HasPrefix(info.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(b.root, path)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+		return nil
+	})
+	return names, err
+}
+
+func (b *localBlobStore) location(path string) string {
+	return filepath.Join(b.root, filepath.FromSlash(path))
+}
@@ -0,0 +1,136 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/metadata"
+	"github.com/podops/podops/internal/validator"
+)
+
+func TestLocalRepository(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	for i := 1; i <= 5; i++ {
+		r := podops.Resource{
+			GUID:       fmt.Sprintf("guid-%d", i),
+			Kind:       podops.ResourceEpisode,
+			ParentGUID: "parent",
+			Published:  int64(i * 100),
+			Created:    int64(i),
+		}
+		assert.NoError(t, DefaultRepository().Put(ctx, datastoreResources, r.GUID, &r))
+	}
+
+	var r podops.Resource
+	assert.NoError(t, DefaultRepository().Get(ctx, datastoreResources, "guid-3", &r))
+	assert.Equal(t, int64(300), r.Published)
+	assert.Equal(t, int64(3), r.Created) // not part of the JSON representation
+	assert.Equal(t, errordef.ErrNoSuchEntity, DefaultRepository().Get(ctx, datastoreResources, "guid-0", &r))
+
+	var l []*podops.Resource
+	q := NewQuery(datastoreResources).Filter("ParentGUID =", "parent").Filter("Published <", 400).Order("-Published").Limit(2)
+	if assert.NoError(t, DefaultRepository().GetAll(ctx, q, &l)) {
+		assert.Equal(t, 2, len(l))
+		assert.Equal(t, "guid-3", l[0].GUID)
+		assert.Equal(t, "guid-2", l[1].GUID)
+	}
+
+	var empty []*podops.Resource
+	assert.NoError(t, DefaultRepository().GetAll(ctx, NewQuery(datastoreResources).Filter("ParentGUID =", "nope"), &empty))
+	assert.Nil(t, empty)
+
+	assert.NoError(t, DefaultRepository().Delete(ctx, datastoreResources, "guid-3"))
+	assert.Equal(t, errordef.ErrNoSuchEntity, DefaultRepository().Get(ctx, datastoreResources, "guid-3", &r))
+}
+
+func TestLocalBlobStore(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	exists, err := DefaultBlobStore().Exists(ctx, "prod/feed.xml")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+
+	assert.NoError(t, DefaultBlobStore().Write(ctx, "prod/feed.xml", []byte("<rss/>")))
+	data, err := DefaultBlobStore().Read(ctx, "prod/feed.xml")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "<rss/>", string(data))
+	}
+
+	names, err := DefaultBlobStore().List(ctx, "prod/")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"prod/feed.xml"}, names)
+	}
+
+	assert.NoError(t, DefaultBlobStore().Remove(ctx, "prod/feed.xml"))
+	_, err = DefaultBlobStore().Read(ctx, "prod/feed.xml")
+	assert.Equal(t, errordef.ErrNoSuchObject, err)
+}
+
+func TestLocalProduction(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	p, err := CreateProduction(ctx, "simple-podcast", "title", "summary", "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	found, err := FindProductionByName(ctx, "simple-podcast")
+	if assert.NoError(t, err) && assert.NotNil(t, found) {
+		assert.Equal(t, p.GUID, found.GUID)
+	}
+
+	location := fmt.Sprintf("%s/show-%s.yaml", p.GUID, p.GUID)
+	show := podops.DefaultShow(p.Name, p.Title, p.Summary, p.GUID, podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	show.Image = podops.Asset{URI: "cover.png", Rel: podops.ResourceTypeLocal}
+	assert.NoError(t, UpdateShow(ctx, location, show))
+	assert.NoError(t, WriteResourceContent(ctx, location, true, false, show))
+	assert.Error(t, WriteResourceContent(ctx, location, true, false, show))
+
+	guid := podops.CreateGUID()
+	episode := podops.DefaultEpisode("episode1", p.Name, guid, p.GUID, podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	episode.Image = show.Image
+	episode.Transcripts = []podops.Asset{{URI: "episode1.vtt", Type: podops.TranscriptTypeVTT, Rel: podops.ResourceTypeLocal}}
+	assert.NoError(t, UpdateEpisode(ctx, fmt.Sprintf("%s/episode-%s.yaml", p.GUID, guid), episode))
+
+	episodes, err := ListPublishedEpisodes(ctx, p.GUID, episode.PublishDateTimestamp()+1, 10)
+	if assert.NoError(t, err) && assert.Equal(t, 1, len(episodes)) {
+		assert.Equal(t, guid, episodes[0].GUID)
+		assert.Equal(t, []string{fmt.Sprintf("%s/%s/episode1.vtt", podops.DefaultStorageEndpoint, p.GUID)}, episodes[0].TranscriptURIs)
+	}
+
+	assert.NoError(t, ValidateProduction(ctx, p.GUID, nil))
+
+	// the artwork is not in the CDN yet
+	v := validator.New(p.GUID)
+	assert.NoError(t, ValidateProduction(ctx, p.GUID, v))
+	assert.True(t, v.IsValid())
+	assert.Equal(t, 1, v.NWarnings())
+
+	// the artwork is too small
+	meta := metadata.Metadata{
+		GUID:       metadata.FingerprintURI(p.GUID, show.Image.AssetName()),
+		Width:      1000,
+		Height:     1000,
+		Format:     "png",
+		ColorModel: metadata.ColorModelRGB,
+	}
+	assert.NoError(t, UpdateMetadata(ctx, &meta))
+	v = validator.New(p.GUID)
+	assert.NoError(t, ValidateProduction(ctx, p.GUID, v))
+	assert.False(t, v.IsValid())
+
+	rsrc, kind, _, err := ReadResourceContent(ctx, location)
+	if assert.NoError(t, err) {
+		assert.Equal(t, podops.ResourceShow, kind)
+		assert.Equal(t, p.Name, rsrc.(*podops.Show).Metadata.Name)
+	}
+}
@@ -0,0 +1,118 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/txsvc/platform/v2/pkg/id"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+const (
+	// DatastoreSubscriptions collection SUBSCRIPTIONS
+	datastoreSubscriptions = "SUBSCRIPTIONS"
+)
+
+// CreateSubscription issues a new subscriber token for a production
+func CreateSubscription(ctx context.Context, production, name string) (*podops.Subscription, error) {
+	if production == "" || name == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	p, err := GetProduction(ctx, production)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errordef.ErrNoSuchProduction
+	}
+
+	token, err := id.SimpleUUID()
+	if err != nil {
+		return nil, err
+	}
+
+	s := podops.Subscription{
+		Token:   token,
+		GUID:    production,
+		Name:    name,
+		FeedURL: PrivateFeedURL(p.Name, token),
+		Created: timestamp.Now(),
+	}
+	if err := DefaultRepository().Put(ctx, datastoreSubscriptions, token, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetSubscription returns the subscription of a token, or nil if the token does not exist
+func GetSubscription(ctx context.Context, token string) (*podops.Subscription, error) {
+	var s podops.Subscription
+
+	if err := DefaultRepository().Get(ctx, datastoreSubscriptions, token, &s); err != nil {
+		if err == errordef.ErrNoSuchEntity {
+			return nil, nil // not found is not an error
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// ListSubscriptions returns all subscriptions of a production
+func ListSubscriptions(ctx context.Context, production string) ([]*podops.Subscription, error) {
+	var s []*podops.Subscription
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreSubscriptions).Filter("GUID =", production).Order("Created"), &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RevokeSubscription deletes a subscriber token. Its private feed and enclosures are no longer accessible.
+func RevokeSubscription(ctx context.Context, production, token string) error {
+	s, err := GetSubscription(ctx, token)
+	if err != nil {
+		return err
+	}
+	if s == nil || s.GUID != production {
+		return errordef.ErrNoSuchSubscription
+	}
+	return DefaultRepository().Delete(ctx, datastoreSubscriptions, token)
+}
+
+// ValidSubscription returns true if token is a subscriber token of the production
+func ValidSubscription(ctx context.Context, production, token string) (bool, error) {
+	if token == "" {
+		return false, nil
+	}
+	s, err := GetSubscription(ctx, token)
+	if err != nil {
+		return false, err
+	}
+	return s != nil && s.GUID == production, nil
+}
+
+// IsPrivateAsset returns true if an asset of a production may only be accessed with a subscriber token
+func IsPrivateAsset(ctx context.Context, production, asset string) (bool, error) {
+	p, err := GetProduction(ctx, production)
+	if err != nil {
+		return false, err
+	}
+	if p == nil {
+		return false, nil
+	}
+	for _, a := range p.PrivateAssets {
+		if a == asset {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// PrivateFeedURL returns the location of a subscriber's feed
+func PrivateFeedURL(name, token string) string {
+	return fmt.Sprintf("%s/s/%s/%s/feed.xml", podops.DefaultEndpoint, name, token)
+}
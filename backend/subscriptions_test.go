@@ -0,0 +1,51 @@
+package backend
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops/internal/errordef"
+)
+
+func TestLocalSubscriptions(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	p, err := CreateProduction(ctx, "private-podcast", "title", "summary", "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+	p.PrivateAssets = []string{"bonus.mp3"}
+	assert.NoError(t, UpdateProduction(ctx, p))
+
+	s, err := CreateSubscription(ctx, p.GUID, "jane@example.com")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, PrivateFeedURL(p.Name, s.Token), s.FeedURL)
+
+	l, err := ListSubscriptions(ctx, p.GUID)
+	if assert.NoError(t, err) && assert.Equal(t, 1, len(l)) {
+		assert.Equal(t, s.Token, l[0].Token)
+	}
+
+	private, err := IsPrivateAsset(ctx, p.GUID, "bonus.mp3")
+	assert.NoError(t, err)
+	assert.True(t, private)
+	private, err = IsPrivateAsset(ctx, p.GUID, "episode.mp3")
+	assert.NoError(t, err)
+	assert.False(t, private)
+
+	valid, err := ValidSubscription(ctx, p.GUID, s.Token)
+	assert.NoError(t, err)
+	assert.True(t, valid)
+	valid, _ = ValidSubscription(ctx, "other", s.Token)
+	assert.False(t, valid)
+
+	assert.Equal(t, errordef.ErrNoSuchSubscription, RevokeSubscription(ctx, "other", s.Token))
+	assert.NoError(t, RevokeSubscription(ctx, p.GUID, s.Token))
+	valid, _ = ValidSubscription(ctx, p.GUID, s.Token)
+	assert.False(t, valid)
+}
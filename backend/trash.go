@@ -0,0 +1,168 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/txsvc/platform/v2/pkg/env"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/messagedef"
+)
+
+const (
+	// DatastoreTrash collection TRASH
+	datastoreTrash = "TRASH"
+
+	// DefaultTrashRetention is the number of days a deleted resource is kept in the trash
+	DefaultTrashRetention = 30
+)
+
+// GetTrashItem returns a deleted resource, or nil if it is not in the trash
+func GetTrashItem(ctx context.Context, guid string) (*podops.TrashItem, error) {
+	var t podops.TrashItem
+
+	if err := DefaultRepository().Get(ctx, datastoreTrash, guid, &t); err != nil {
+		if err == errordef.ErrNoSuchEntity {
+			return nil, nil // not found is not an error
+		}
+		return nil, err
+	}
+	return &t, nil
+}
+
+// ListTrash returns all deleted resources of a production, latest first
+func ListTrash(ctx context.Context, production string) ([]*podops.TrashItem, error) {
+	var t []*podops.TrashItem
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreTrash).Filter("ParentGUID =", production).Order("-Deleted"), &t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// ListExpiredTrash returns all deleted resources whose retention period ended before now
+func ListExpiredTrash(ctx context.Context, now int64) ([]*podops.TrashItem, error) {
+	var t []*podops.TrashItem
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreTrash).Filter("Expires <", now), &t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// UndeleteResource restores a deleted resource, its .yaml and its inventory entry
+func UndeleteResource(ctx context.Context, guid string) (*podops.Resource, error) {
+	t, err := GetTrashItem(ctx, guid)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return nil, errordef.ErrNotInTrash
+	}
+
+	existing, err := GetResource(ctx, guid)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf(messagedef.MsgResourceAlreadyExists, guid)
+	}
+
+	r := t.Resource
+	if r.Kind != podops.ResourceAsset {
+		if err := moveObject(ctx, t.Location, r.Location); err != nil {
+			return nil, err
+		}
+	}
+	r.Updated = timestamp.Now()
+	if err := updateResource(ctx, &r); err != nil {
+		return nil, err
+	}
+	if err := DefaultRepository().Delete(ctx, datastoreTrash, guid); err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// PurgeResource removes a deleted resource for good, i.e. its .yaml, revisions, metadata and files on the CDN
+func PurgeResource(ctx context.Context, guid string) error {
+	t, err := GetTrashItem(ctx, guid)
+	if err != nil {
+		return err
+	}
+	if t == nil {
+		return errordef.ErrNotInTrash
+	}
+
+	if t.Kind != podops.ResourceAsset {
+		if err := RemoveResourceContent(ctx, t.Location); err != nil && err != errordef.ErrNoSuchResource {
+			return err
+		}
+	}
+
+	// a resource that was created again keeps its metadata, files and revisions
+	r, err := GetResource(ctx, guid)
+	if err != nil {
+		return err
+	}
+	if r == nil {
+		if t.Kind == podops.ResourceAsset {
+			if err := DeleteMetadata(ctx, guid); err != nil && err != errordef.ErrNoSuchResource {
+				return err
+			}
+			if err := RemoveAsset(ctx, t.ParentGUID, t.Location); err != nil {
+				return err
+			}
+		}
+		if err := deleteRevisions(ctx, guid); err != nil {
+			return err
+		}
+	}
+
+	return DefaultRepository().Delete(ctx, datastoreTrash, guid)
+}
+
+// trashResource keeps a copy of a resource's inventory entry in the trash. The .yaml of shows and episodes
+// is moved out of the way, assets stay on the CDN until the resource is purged.
+func trashResource(ctx context.Context, r *podops.Resource, author string) error {
+	// an earlier deletion of the same resource is gone for good
+	if err := PurgeResource(ctx, r.GUID); err != nil && err != errordef.ErrNotInTrash {
+		return err
+	}
+
+	now := timestamp.Now()
+	t := podops.TrashItem{
+		GUID:       r.GUID,
+		ParentGUID: r.ParentGUID,
+		Kind:       r.Kind,
+		Name:       r.Name,
+		DeletedBy:  author,
+		Location:   r.Location,
+		Resource:   *r,
+		Deleted:    now,
+		Expires:    now + env.GetInt("PODOPS_TRASH_RETENTION", DefaultTrashRetention)*86400,
+	}
+
+	if r.Kind != podops.ResourceAsset {
+		t.Location = fmt.Sprintf("%s/trash/%s.yaml", r.ParentGUID, r.GUID)
+		if err := moveObject(ctx, r.Location, t.Location); err != nil {
+			return err
+		}
+	}
+	return DefaultRepository().Put(ctx, datastoreTrash, r.GUID, &t)
+}
+
+// moveObject moves an object within the production bucket
+func moveObject(ctx context.Context, from, to string) error {
+	data, err := DefaultBlobStore().Read(ctx, from)
+	if err != nil {
+		return err
+	}
+	if err := DefaultBlobStore().Write(ctx, to, data); err != nil {
+		return err
+	}
+	return DefaultBlobStore().Remove(ctx, from)
+}
@@ -0,0 +1,70 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+func TestLocalTrash(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	p, err := CreateProduction(ctx, "trashed-podcast", "title", "summary", "client")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	episode := podops.DefaultEpisode("episode1", p.Name, "trashed-guid", p.GUID, podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	location := fmt.Sprintf("%s/episode-trashed-guid.yaml", p.GUID)
+	_, err = WriteRevision(ctx, p.GUID, "trashed-guid", "jane", location, true, false, nil, episode)
+	assert.NoError(t, err)
+	assert.NoError(t, UpdateResource(ctx, "episode1", "trashed-guid", podops.ResourceEpisode, p.GUID, location))
+
+	// deleting moves the resource to the trash
+	assert.NoError(t, DeleteResource(ctx, p.GUID, podops.ResourceEpisode, "trashed-guid", "john"))
+	r, err := GetResource(ctx, "trashed-guid")
+	assert.NoError(t, err)
+	assert.Nil(t, r)
+	exists, _ := DefaultBlobStore().Exists(ctx, location)
+	assert.False(t, exists)
+
+	l, err := ListTrash(ctx, p.GUID)
+	if assert.NoError(t, err) && assert.Equal(t, 1, len(l)) {
+		assert.Equal(t, "john", l[0].DeletedBy)
+		assert.Equal(t, podops.ResourceEpisode, l[0].Kind)
+		assert.True(t, l[0].Expires > l[0].Deleted)
+	}
+
+	// undelete restores the inventory entry and the .yaml
+	r, err = UndeleteResource(ctx, "trashed-guid")
+	if assert.NoError(t, err) {
+		assert.Equal(t, location, r.Location)
+	}
+	exists, _ = DefaultBlobStore().Exists(ctx, location)
+	assert.True(t, exists)
+	_, err = UndeleteResource(ctx, "trashed-guid")
+	assert.Equal(t, errordef.ErrNotInTrash, err)
+
+	// purging removes the resource and its revisions for good
+	assert.NoError(t, DeleteResource(ctx, p.GUID, podops.ResourceEpisode, "trashed-guid", "john"))
+	expired, err := ListExpiredTrash(ctx, time.Now().Unix()+(DefaultTrashRetention+1)*86400)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, len(expired))
+	}
+	assert.NoError(t, PurgeResource(ctx, "trashed-guid"))
+	l, err = ListTrash(ctx, p.GUID)
+	if assert.NoError(t, err) {
+		assert.Empty(t, l)
+	}
+	revisions, err := ListRevisions(ctx, "trashed-guid")
+	if assert.NoError(t, err) {
+		assert.Empty(t, revisions)
+	}
+}
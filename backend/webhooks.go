@@ -0,0 +1,320 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/apis/provider"
+	"github.com/txsvc/platform/v2/pkg/env"
+	"github.com/txsvc/platform/v2/pkg/id"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/messagedef"
+	"github.com/podops/podops/internal/transport"
+)
+
+const (
+	// DatastoreWebhooks collection WEBHOOKS
+	datastoreWebhooks = "WEBHOOKS"
+	// DatastoreWebhookDeliveries collection WEBHOOK_DELIVERIES
+	datastoreWebhookDeliveries = "WEBHOOK_DELIVERIES"
+
+	// DefaultDeliveryHistory is the number of deliveries returned by ListDeliveries
+	DefaultDeliveryHistory = 50
+	// MaxDeliveryLog is the number of deliveries kept per webhook, older ones are removed
+	MaxDeliveryLog = 100
+	// MaxDeliveryAttempts is the number of times the delivery of an event is attempted before it is given up
+	MaxDeliveryAttempts = 5
+
+	// HeaderSignature carries the HMAC-SHA256 of the timestamp and the payload, signed with the webhook's secret
+	HeaderSignature = "X-Podops-Signature"
+	// HeaderTimestamp carries the time the request was signed, in seconds since the epoch
+	HeaderTimestamp = "X-Podops-Timestamp"
+	// HeaderEvent carries the type of the event
+	HeaderEvent = "X-Podops-Event"
+	// HeaderDelivery carries the ID of the event. It is the same for all attempts to deliver it.
+	HeaderDelivery = "X-Podops-Delivery"
+
+	deliveryTimeout = 10 * time.Second
+)
+
+var (
+	// full canonical route
+	deliveryTaskEndpoint string = podops.DefaultAPIEndpoint + "/_w/deliver"
+
+	webhookEvents = []string{
+		podops.EventBuildSucceeded,
+		podops.EventBuildFailed,
+		podops.EventEpisodePublished,
+		podops.EventResourceUpdated,
+		podops.EventAssetImported,
+		podops.EventAssetDeleted,
+	}
+)
+
+// CreateWebhook subscribes uri to events of a production. The webhook subscribes to all events if none are given.
+// Only https URLs of public hosts are accepted.
+func CreateWebhook(ctx context.Context, production, uri string, events []string) (*podops.Webhook, error) {
+	if err := ValidateCallback(ctx, uri); err != nil {
+		if err == errordef.ErrPrivateCallback {
+			return nil, err
+		}
+		return nil, fmt.Errorf(messagedef.MsgParameterIsInvalid, uri)
+	}
+	for _, e := range events {
+		if !validEvent(e) {
+			return nil, fmt.Errorf(messagedef.MsgParameterIsInvalid, e)
+		}
+	}
+
+	p, err := GetProduction(ctx, production)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errordef.ErrNoSuchProduction
+	}
+
+	webhookID, _ := id.ShortUUID()
+	secret := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, secret); err != nil {
+		return nil, err
+	}
+
+	w := podops.Webhook{
+		ID:      webhookID,
+		GUID:    production,
+		URL:     uri,
+		Events:  events,
+		Secret:  hex.EncodeToString(secret),
+		Created: timestamp.Now(),
+	}
+	if err := DefaultRepository().Put(ctx, datastoreWebhooks, webhookID, &w); err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// GetWebhook returns a webhook, or nil if it does not exist
+func GetWebhook(ctx context.Context, webhookID string) (*podops.Webhook, error) {
+	var w podops.Webhook
+
+	if err := DefaultRepository().Get(ctx, datastoreWebhooks, webhookID, &w); err != nil {
+		if err == errordef.ErrNoSuchEntity {
+			return nil, nil // not found is not an error
+		}
+		return nil, err
+	}
+	return &w, nil
+}
+
+// ListWebhooks returns all webhooks of a production
+func ListWebhooks(ctx context.Context, production string) ([]*podops.Webhook, error) {
+	var w []*podops.Webhook
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreWebhooks).Filter("GUID =", production).Order("Created"), &w); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// DeleteWebhook removes a webhook. Events that are already queued are not delivered anymore.
+func DeleteWebhook(ctx context.Context, production, webhookID string) error {
+	w, err := GetWebhook(ctx, webhookID)
+	if err != nil {
+		return err
+	}
+	if w == nil || w.GUID != production {
+		return errordef.ErrNoSuchWebhook
+	}
+	if err := DefaultRepository().Delete(ctx, datastoreWebhooks, webhookID); err != nil {
+		return err
+	}
+	return pruneDeliveries(ctx, webhookID, 0)
+}
+
+// Emit queues the delivery of an event to all webhooks of the production that subscribed to it.
+// Failures are reported but never affect the caller.
+func Emit(ctx context.Context, production, event, resource string, data interface{}) {
+	webhooks, err := ListWebhooks(ctx, production)
+	if err != nil {
+		platform.ReportError(err)
+		return
+	}
+	if len(webhooks) == 0 {
+		return
+	}
+
+	eventID, _ := id.ShortUUID()
+	e := podops.WebhookEvent{
+		ID:       eventID,
+		Event:    event,
+		GUID:     production,
+		Resource: resource,
+		Data:     data,
+		Created:  timestamp.Now(),
+	}
+
+	for _, w := range webhooks {
+		if !subscribed(w, event) {
+			continue
+		}
+		task := provider.HttpTask{
+			Method:  provider.HttpMethodPost,
+			Request: deliveryTaskEndpoint,
+			Token:   env.GetString("PODOPS_API_KEY", ""),
+			Payload: &podops.WebhookDeliveryRequest{WebhookID: w.ID, Event: &e},
+		}
+		if err := background().CreateHttpTask(ctx, task); err != nil {
+			platform.ReportError(err)
+		}
+	}
+}
+
+// DeliverEvent sends an event to a webhook and records the attempt in the delivery log.
+// An error is returned if the receiver did not accept the event.
+func DeliverEvent(ctx context.Context, req *podops.WebhookDeliveryRequest, attempt int) (*podops.WebhookDelivery, error) {
+	w, err := GetWebhook(ctx, req.WebhookID)
+	if err != nil {
+		return nil, err
+	}
+	if w == nil {
+		return nil, nil // the webhook was deleted in the meantime
+	}
+
+	payload, err := json.Marshal(req.Event)
+	if err != nil {
+		return nil, err
+	}
+
+	deliveryID, _ := id.ShortUUID()
+	d := podops.WebhookDelivery{
+		ID:        deliveryID,
+		GUID:      w.GUID,
+		WebhookID: w.ID,
+		URL:       w.URL,
+		EventID:   req.Event.ID,
+		Event:     req.Event.Event,
+		Attempt:   attempt,
+		Created:   timestamp.Now(),
+	}
+
+	status, err := 0, ValidateCallback(ctx, w.URL) // the host might resolve to another address by now
+	if err == nil {
+		status, err = post(ctx, w.URL, req.Event, w.Secret, payload)
+	}
+	d.Status = status
+	if err != nil {
+		d.Message = err.Error()
+	} else if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		err = fmt.Errorf(messagedef.MsgStatus, status)
+		d.Message = err.Error()
+	}
+
+	if perr := DefaultRepository().Put(ctx, datastoreWebhookDeliveries, deliveryID, &d); perr != nil {
+		return nil, perr
+	}
+	if perr := pruneDeliveries(ctx, w.ID, MaxDeliveryLog); perr != nil {
+		platform.ReportError(perr) // the log is just a bit longer
+	}
+	return &d, err
+}
+
+// ListDeliveries returns the most recent deliveries of events of a production, the latest first
+func ListDeliveries(ctx context.Context, production string, limit int) ([]*podops.WebhookDelivery, error) {
+	var d []*podops.WebhookDelivery
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreWebhookDeliveries).Filter("GUID =", production).Order("-Created").Limit(limit), &d); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// SignPayload returns the HMAC-SHA256 of a payload, as used in signature headers
+func SignPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignEvent returns the value of the signature header of an event. The timestamp is signed
+// together with the payload, receivers reject old timestamps to prevent replays.
+func SignEvent(secret string, ts int64, payload []byte) string {
+	return SignPayload(secret, append([]byte(fmt.Sprintf("%d.", ts)), payload...))
+}
+
+// pruneDeliveries removes all but the keep most recent deliveries of a webhook
+func pruneDeliveries(ctx context.Context, webhookID string, keep int) error {
+	var d []*podops.WebhookDelivery
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreWebhookDeliveries).Filter("WebhookID =", webhookID).Order("-Created"), &d); err != nil {
+		return err
+	}
+	for i := keep; i < len(d); i++ {
+		if err := DefaultRepository().Delete(ctx, datastoreWebhookDeliveries, d[i].ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func post(ctx context.Context, uri string, e *podops.WebhookEvent, secret string, payload []byte) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, deliveryTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", uri, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("User-Agent", transport.UserAgentString)
+	ts := timestamp.Now()
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, SignEvent(secret, ts, payload))
+	req.Header.Set(HeaderEvent, e.Event)
+	req.Header.Set(HeaderDelivery, e.ID)
+
+	resp, err := callbackClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
+func subscribed(w *podops.Webhook, event string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func validEvent(event string) bool {
+	for _, e := range webhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+func TestLocalWebhooks(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	allowPrivateCallbacks = true // the receiver runs on localhost
+	defer func() { allowPrivateCallbacks = false }()
+
+	var signature, event, ts string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(HeaderSignature)
+		ts = r.Header.Get(HeaderTimestamp)
+		event = r.Header.Get(HeaderEvent)
+		if event == podops.EventBuildFailed {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	p, err := CreateProduction(ctx, "hooked-podcast", "title", "summary", "owner-client")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = CreateWebhook(ctx, p.GUID, "ftp://example.com", nil)
+	assert.Error(t, err)
+	_, err = CreateWebhook(ctx, p.GUID, srv.URL, []string{"build.started"})
+	assert.Error(t, err)
+
+	w, err := CreateWebhook(ctx, p.GUID, srv.URL, []string{podops.EventBuildSucceeded, podops.EventBuildFailed})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 64, len(w.Secret))
+	assert.True(t, subscribed(w, podops.EventBuildFailed))
+	assert.False(t, subscribed(w, podops.EventAssetDeleted))
+
+	e := podops.WebhookEvent{ID: "event-1", Event: podops.EventBuildSucceeded, GUID: p.GUID}
+	d, err := DeliverEvent(ctx, &podops.WebhookDeliveryRequest{WebhookID: w.ID, Event: &e}, 1)
+	if assert.NoError(t, err) && assert.NotNil(t, d) {
+		assert.Equal(t, http.StatusOK, d.Status)
+	}
+	payload, _ := json.Marshal(&e)
+	signed, _ := strconv.ParseInt(ts, 10, 64)
+	assert.Equal(t, SignEvent(w.Secret, signed, payload), signature)
+	assert.Equal(t, podops.EventBuildSucceeded, event)
+
+	// a redirect is not followed and is not a successful delivery
+	redirect := httptest.NewServer(http.RedirectHandler(srv.URL, http.StatusFound))
+	defer redirect.Close()
+	moved, err := CreateWebhook(ctx, p.GUID, redirect.URL, nil)
+	if assert.NoError(t, err) {
+		d, err = DeliverEvent(ctx, &podops.WebhookDeliveryRequest{WebhookID: moved.ID, Event: &e}, 1)
+		if assert.Error(t, err) && assert.NotNil(t, d) {
+			assert.Equal(t, http.StatusFound, d.Status)
+		}
+		assert.NoError(t, DeleteWebhook(ctx, p.GUID, moved.ID))
+	}
+
+	e = podops.WebhookEvent{ID: "event-2", Event: podops.EventBuildFailed, GUID: p.GUID}
+	d, err = DeliverEvent(ctx, &podops.WebhookDeliveryRequest{WebhookID: w.ID, Event: &e}, 2)
+	if assert.Error(t, err) && assert.NotNil(t, d) {
+		assert.Equal(t, http.StatusInternalServerError, d.Status)
+		assert.Equal(t, 2, d.Attempt)
+	}
+
+	l, err := ListDeliveries(ctx, p.GUID, DefaultDeliveryHistory)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 2, len(l))
+	}
+	// the log is capped per webhook
+	assert.NoError(t, pruneDeliveries(ctx, w.ID, 1))
+	l, err = ListDeliveries(ctx, p.GUID, DefaultDeliveryHistory)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, len(l))
+	}
+
+	assert.Equal(t, errordef.ErrNoSuchWebhook, DeleteWebhook(ctx, "other-production", w.ID))
+	assert.NoError(t, DeleteWebhook(ctx, p.GUID, w.ID))
+	// events queued before the webhook was deleted are dropped
+	d, err = DeliverEvent(ctx, &podops.WebhookDeliveryRequest{WebhookID: w.ID, Event: &e}, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, d)
+}
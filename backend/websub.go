@@ -0,0 +1,341 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/apis/provider"
+	"github.com/txsvc/platform/v2/pkg/env"
+	"github.com/txsvc/platform/v2/pkg/id"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/messagedef"
+	"github.com/podops/podops/internal/transport"
+)
+
+const (
+	// DatastoreHubSubscriptions collection HUB_SUBSCRIPTIONS
+	datastoreHubSubscriptions = "HUB_SUBSCRIPTIONS"
+
+	// DefaultLeaseSeconds is the duration of a hub subscription if the subscriber does not ask for one
+	DefaultLeaseSeconds = 10 * 24 * 3600
+	// MaxLeaseSeconds is the maximum duration of a hub subscription
+	MaxLeaseSeconds = 30 * 24 * 3600
+
+	// HeaderHubSignature carries the HMAC-SHA256 of the content, signed with the subscriber's secret
+	HeaderHubSignature = "X-Hub-Signature"
+
+	hubTimeout = 10 * time.Second
+)
+
+var (
+	// full canonical route
+	distributionTaskEndpoint string = podops.DefaultCDNEndpoint + "/_w/distribute"
+)
+
+// Hubs returns the WebSub hubs that are declared in the feeds
+func Hubs() []string {
+	hubs := make([]string, 0)
+	for _, h := range strings.Split(podops.WebSubHubs, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			hubs = append(hubs, h)
+		}
+	}
+	return hubs
+}
+
+// BuiltinHubEnabled returns true if the CDN hosts a WebSub hub
+func BuiltinHubEnabled() bool {
+	for _, h := range Hubs() {
+		if h == podops.DefaultHubEndpoint {
+			return true
+		}
+	}
+	return false
+}
+
+// NotifyHubs sends a publish ping for the public feed of a production to all hubs. The subscribers
+// of the built-in hub get the feed directly. Failures are reported but never affect the caller, subscribers still poll the feed.
+func NotifyHubs(ctx context.Context, production string) {
+	hubs := Hubs()
+	if len(hubs) == 0 {
+		return
+	}
+
+	p, err := GetProduction(ctx, production)
+	if err != nil {
+		platform.ReportError(err)
+		return
+	}
+	if p == nil || p.Private {
+		return // members-only shows don't have a public feed
+	}
+
+	form := url.Values{}
+	form.Set("hub.mode", "publish")
+	form.Set("hub.url", FeedAliasURL(p.Name))
+
+	for _, h := range hubs {
+		if h == podops.DefaultHubEndpoint {
+			if err := QueueDistribution(ctx, p.GUID); err != nil {
+				platform.ReportError(err)
+			}
+			continue
+		}
+		status, err := hubRequest(ctx, "POST", h, "application/x-www-form-urlencoded", []byte(form.Encode()), nil)
+		if err != nil {
+			platform.ReportError(err)
+			continue
+		}
+		if status < http.StatusOK || status >= http.StatusMultipleChoices {
+			platform.ReportError(fmt.Errorf(messagedef.MsgStatus, status))
+		}
+	}
+}
+
+// FindProductionByTopic returns the production whose public feed is topic, or nil if the topic is not a public feed
+func FindProductionByTopic(ctx context.Context, topic string) (*podops.Production, error) {
+	prefix := podops.DefaultEndpoint + "/s/"
+	if !strings.HasPrefix(topic, prefix) || !strings.HasSuffix(topic, "/feed.xml") {
+		return nil, nil
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(topic, prefix), "/feed.xml")
+	if name == "" || strings.Contains(name, "/") {
+		return nil, nil // e.g. a subscriber's private feed
+	}
+
+	p, err := FindProductionByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		// subscribers of a renamed production keep their topic
+		if p, err = FindProductionByAlias(ctx, name); err != nil {
+			return nil, err
+		}
+	}
+	if p == nil || p.Private {
+		return nil, nil
+	}
+	return p, nil
+}
+
+// SubscribeHub verifies the intent of the subscriber and subscribes callback to the feed. Subscribing again renews the lease.
+func SubscribeHub(ctx context.Context, topic, callback, secret string, lease int) (*podops.HubSubscription, error) {
+	p, err := hubTopic(ctx, topic, callback)
+	if err != nil {
+		return nil, err
+	}
+	if lease <= 0 {
+		lease = DefaultLeaseSeconds
+	}
+	if lease > MaxLeaseSeconds {
+		lease = MaxLeaseSeconds
+	}
+
+	if err := verifyIntent(ctx, "subscribe", topic, callback, lease); err != nil {
+		return nil, err
+	}
+
+	now := timestamp.Now()
+	s := podops.HubSubscription{
+		ID:       id.Checksum(p.GUID + callback),
+		GUID:     p.GUID,
+		Topic:    topic,
+		Callback: callback,
+		Secret:   secret,
+		Expires:  now + int64(lease),
+		Created:  now,
+	}
+	if err := DefaultRepository().Put(ctx, datastoreHubSubscriptions, s.ID, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// UnsubscribeHub verifies the intent of the subscriber and removes its subscription to the feed
+func UnsubscribeHub(ctx context.Context, topic, callback string) error {
+	p, err := hubTopic(ctx, topic, callback)
+	if err != nil {
+		return err
+	}
+	if err := verifyIntent(ctx, "unsubscribe", topic, callback, 0); err != nil {
+		return err
+	}
+	return DefaultRepository().Delete(ctx, datastoreHubSubscriptions, id.Checksum(p.GUID+callback))
+}
+
+// ListHubSubscriptions returns all subscriptions to the feed of a production, including expired ones
+func ListHubSubscriptions(ctx context.Context, production string) ([]*podops.HubSubscription, error) {
+	var s []*podops.HubSubscription
+
+	if err := DefaultRepository().GetAll(ctx, NewQuery(datastoreHubSubscriptions).Filter("GUID =", production), &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// GetHubSubscription returns a subscription, or nil if it does not exist
+func GetHubSubscription(ctx context.Context, subscriptionID string) (*podops.HubSubscription, error) {
+	var s podops.HubSubscription
+
+	if err := DefaultRepository().Get(ctx, datastoreHubSubscriptions, subscriptionID, &s); err != nil {
+		if err == errordef.ErrNoSuchEntity {
+			return nil, nil // not found is not an error
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// QueueDistribution queues the delivery of a production's feed to each subscriber of the built-in hub.
+// Expired subscriptions are removed.
+func QueueDistribution(ctx context.Context, production string) error {
+	subscriptions, err := ListHubSubscriptions(ctx, production)
+	if err != nil {
+		return err
+	}
+
+	now := timestamp.Now()
+	for _, s := range subscriptions {
+		if s.Expires < now {
+			if err := DefaultRepository().Delete(ctx, datastoreHubSubscriptions, s.ID); err != nil {
+				platform.ReportError(err)
+			}
+			continue
+		}
+
+		task := provider.HttpTask{
+			Method:  provider.HttpMethodPost,
+			Request: distributionTaskEndpoint,
+			Token:   env.GetString("PODOPS_API_KEY", ""),
+			Payload: &podops.HubDeliveryRequest{SubscriptionID: s.ID},
+		}
+		if err := background().CreateHttpTask(ctx, task); err != nil {
+			platform.ReportError(err)
+		}
+	}
+	return nil
+}
+
+// DeliverFeed sends the content of a feed to a subscriber of the built-in hub. Subscribers
+// that answer with 410 Gone are removed, an error is returned if the subscriber did not accept the feed.
+func DeliverFeed(ctx context.Context, s *podops.HubSubscription, content []byte) error {
+	if err := ValidateCallback(ctx, s.Callback); err != nil {
+		return err
+	}
+
+	header := http.Header{}
+	header.Set("Link", fmt.Sprintf("<%s>; rel=\"hub\", <%s>; rel=\"self\"", podops.DefaultHubEndpoint, s.Topic))
+	if s.Secret != "" {
+		header.Set(HeaderHubSignature, SignPayload(s.Secret, content))
+	}
+
+	status, err := hubRequest(ctx, "POST", s.Callback, "application/rss+xml; charset=utf-8", content, header)
+	if err != nil {
+		return err
+	}
+	if status == http.StatusGone {
+		// the subscriber is no longer interested
+		return DefaultRepository().Delete(ctx, datastoreHubSubscriptions, s.ID)
+	}
+	if status < http.StatusOK || status >= http.StatusMultipleChoices {
+		return fmt.Errorf(messagedef.MsgStatus, status)
+	}
+	return nil
+}
+
+// hubTopic validates a (un)subscription request and returns the production of the topic
+func hubTopic(ctx context.Context, topic, callback string) (*podops.Production, error) {
+	if err := ValidateCallback(ctx, callback); err != nil {
+		if err == errordef.ErrPrivateCallback {
+			return nil, err
+		}
+		return nil, fmt.Errorf(messagedef.MsgParameterIsInvalid, callback)
+	}
+	p, err := FindProductionByTopic(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errordef.ErrInvalidTopic
+	}
+	return p, nil
+}
+
+// verifyIntent asks the subscriber to confirm a (un)subscription by echoing a challenge
+func verifyIntent(ctx context.Context, mode, topic, callback string, lease int) error {
+	u, err := url.Parse(callback)
+	if err != nil {
+		return err
+	}
+	challenge, _ := id.ShortUUID()
+
+	q := u.Query()
+	q.Set("hub.mode", mode)
+	q.Set("hub.topic", topic)
+	q.Set("hub.challenge", challenge)
+	if lease > 0 {
+		q.Set("hub.lease_seconds", strconv.Itoa(lease))
+	}
+	u.RawQuery = q.Encode()
+
+	ctx, cancel := context.WithTimeout(ctx, hubTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", transport.UserAgentString)
+
+	resp, err := callbackClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices || strings.TrimSpace(string(body)) != challenge {
+		return errordef.ErrIntentNotVerified
+	}
+	return nil
+}
+
+func hubRequest(ctx context.Context, method, uri, contentType string, payload []byte, header http.Header) (int, error) {
+	ctx, cancel := context.WithTimeout(ctx, hubTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, uri, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("User-Agent", transport.UserAgentString)
+
+	resp, err := callbackClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
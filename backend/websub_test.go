@@ -0,0 +1,77 @@
+package backend
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops/internal/errordef"
+)
+
+func TestLocalWebSub(t *testing.T) {
+	setupLocalStore(t)
+	ctx := context.TODO()
+
+	allowPrivateCallbacks = true // the subscriber runs on localhost
+	defer func() { allowPrivateCallbacks = false }()
+
+	var content []byte
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			fmt.Fprint(w, r.URL.Query().Get("hub.challenge"))
+			return
+		}
+		content, _ = ioutil.ReadAll(r.Body)
+		signature = r.Header.Get(HeaderHubSignature)
+	}))
+	defer srv.Close()
+
+	p, err := CreateProduction(ctx, "websub-podcast", "title", "summary", "owner-client")
+	if !assert.NoError(t, err) {
+		return
+	}
+	topic := FeedAliasURL(p.Name)
+
+	_, err = SubscribeHub(ctx, "https://example.com/feed.xml", srv.URL, "", 0)
+	assert.Equal(t, errordef.ErrInvalidTopic, err)
+	_, err = SubscribeHub(ctx, PrivateFeedURL(p.Name, "token"), srv.URL, "", 0)
+	assert.Equal(t, errordef.ErrInvalidTopic, err)
+
+	allowPrivateCallbacks = false
+	_, err = SubscribeHub(ctx, topic, srv.URL+"/callback", "secret", 0)
+	assert.Equal(t, errordef.ErrPrivateCallback, err)
+	allowPrivateCallbacks = true
+
+	s, err := SubscribeHub(ctx, topic, srv.URL+"/callback", "secret", 2*MaxLeaseSeconds)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, s.Expires <= s.Created+MaxLeaseSeconds)
+
+	// subscribing again renews the subscription
+	_, err = SubscribeHub(ctx, topic, srv.URL+"/callback", "secret", 0)
+	assert.NoError(t, err)
+	l, err := ListHubSubscriptions(ctx, p.GUID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 1, len(l))
+	}
+
+	s, err = GetHubSubscription(ctx, s.ID)
+	if assert.NoError(t, err) && assert.NotNil(t, s) {
+		assert.NoError(t, DeliverFeed(ctx, s, []byte("<rss/>")))
+		assert.Equal(t, "<rss/>", string(content))
+		assert.Equal(t, SignPayload("secret", content), signature)
+	}
+
+	assert.NoError(t, UnsubscribeHub(ctx, topic, srv.URL+"/callback"))
+	l, err = ListHubSubscriptions(ctx, p.GUID)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 0, len(l))
+	}
+}
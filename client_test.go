@@ -1,7 +1,15 @@
 package podops
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -30,3 +38,62 @@ func TestSimpleLoadConfiguration(t *testing.T) {
 		assert.NotNil(t, client)
 	}
 }
+
+func TestResumeUpload(t *testing.T) {
+	data := bytes.Repeat([]byte("podops"), UploadChunkSize/3) // two chunks
+	var received []byte
+	failed := false
+
+	// a minimal tus server that drops the second chunk once
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			w.Header().Set("Location", "/_w/uploads/prod/upload-id")
+			w.WriteHeader(http.StatusCreated)
+		case "HEAD":
+			w.Header().Set(HeaderUploadOffset, strconv.Itoa(len(received)))
+			w.Header().Set(HeaderUploadLength, strconv.Itoa(len(data)))
+		case "PATCH":
+			chunk, _ := ioutil.ReadAll(r.Body)
+			if len(received) > 0 && !failed {
+				failed = true
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			sum := sha256.Sum256(chunk)
+			if r.Header.Get(HeaderUploadChecksum) != FormatChecksum(sum[:]) || r.Header.Get(HeaderUploadOffset) != strconv.Itoa(len(received)) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			received = append(received, chunk...)
+			if len(received) == len(data) {
+				w.Header().Set("ETag", fmt.Sprintf("\"%x\"", sha256.Sum256(received)))
+			}
+			w.Header().Set(HeaderUploadOffset, strconv.Itoa(len(received)))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "episode.mp3")
+	if !assert.NoError(t, ioutil.WriteFile(path, data, 0644)) {
+		return
+	}
+
+	client, err := NewClient(context.TODO(), "po-xxx-xxx", &ClientOption{CDNEndpoint: srv.URL})
+	if !assert.NoError(t, err) {
+		return
+	}
+	location, err := client.CreateUpload("prod", path)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var progress int64
+	err = client.ResumeUpload(location, path, func(offset, length int64) { progress = offset })
+	if assert.NoError(t, err) {
+		assert.True(t, failed)
+		assert.Equal(t, data, received)
+		assert.Equal(t, int64(len(data)), progress)
+	}
+}
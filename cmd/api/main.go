@@ -45,13 +45,49 @@ func setup() *echo.Echo {
 	apiEndpoints := e.Group(apiv1.NamespacePrefix)
 	apiEndpoints.GET(apiv1.ListProductionsRoute, apiv1.ListProductionsEndpoint)
 	apiEndpoints.POST(apiv1.ProductionRoute, apiv1.ProductionEndpoint)
+	apiEndpoints.PUT(apiv1.UpdateProductionRoute, apiv1.RenameProductionEndpoint)
+	apiEndpoints.DELETE(apiv1.DeleteProductionRoute, apiv1.DeleteProductionEndpoint)
+	apiEndpoints.POST(apiv1.ArchiveProductionRoute, apiv1.ArchiveProductionEndpoint)
+	apiEndpoints.DELETE(apiv1.ArchiveProductionRoute, apiv1.ArchiveProductionEndpoint)
+	apiEndpoints.POST(apiv1.TransferProductionRoute, apiv1.TransferProductionEndpoint)
 	apiEndpoints.GET(apiv1.FindResourceRoute, apiv1.FindResourceEndpoint)
 	apiEndpoints.GET(apiv1.GetResourceRoute, apiv1.GetResourceEndpoint)
 	apiEndpoints.GET(apiv1.ListResourcesRoute, apiv1.ListResourcesEndpoint)
 	apiEndpoints.POST(apiv1.UpdateResourceRoute, apiv1.UpdateResourceEndpoint)
 	apiEndpoints.PUT(apiv1.UpdateResourceRoute, apiv1.UpdateResourceEndpoint)
 	apiEndpoints.DELETE(apiv1.DeleteResourceRoute, apiv1.DeleteResourceEndpoint)
+	apiEndpoints.GET(apiv1.ListRevisionsRoute, apiv1.ListRevisionsEndpoint)
+	apiEndpoints.GET(apiv1.DiffRevisionsRoute, apiv1.DiffRevisionsEndpoint)
+	apiEndpoints.POST(apiv1.RestoreRevisionRoute, apiv1.RestoreRevisionEndpoint)
+	apiEndpoints.GET(apiv1.ListTrashRoute, apiv1.ListTrashEndpoint)
+	apiEndpoints.POST(apiv1.UndeleteResourceRoute, apiv1.UndeleteResourceEndpoint)
 	apiEndpoints.POST(apiv1.BuildRoute, apiv1.BuildFeedEndpoint)
+	apiEndpoints.GET(apiv1.GetBuildRoute, apiv1.GetBuildEndpoint)
+	apiEndpoints.GET(apiv1.BuildArtifactRoute, apiv1.BuildArtifactEndpoint)
+	apiEndpoints.GET(apiv1.ListBuildsRoute, apiv1.ListBuildsEndpoint)
+	apiEndpoints.POST(apiv1.RollbackRoute, apiv1.RollbackEndpoint)
+	apiEndpoints.POST(apiv1.ImportFeedRoute, apiv1.ImportFeedEndpoint)
+	apiEndpoints.GET(apiv1.ScheduleRoute, apiv1.ScheduleEndpoint)
+	apiEndpoints.GET(apiv1.LintRoute, apiv1.LintEndpoint)
+	apiEndpoints.GET(apiv1.StatsRoute, apiv1.StatsEndpoint)
+	apiEndpoints.POST(apiv1.SubscriptionRoute, apiv1.CreateSubscriptionEndpoint)
+	apiEndpoints.GET(apiv1.ListSubscriptionsRoute, apiv1.ListSubscriptionsEndpoint)
+	apiEndpoints.DELETE(apiv1.RevokeSubscriptionRoute, apiv1.RevokeSubscriptionEndpoint)
+	apiEndpoints.POST(apiv1.MemberRoute, apiv1.AddMemberEndpoint)
+	apiEndpoints.GET(apiv1.ListMembersRoute, apiv1.ListMembersEndpoint)
+	apiEndpoints.DELETE(apiv1.RemoveMemberRoute, apiv1.RemoveMemberEndpoint)
+	apiEndpoints.POST(apiv1.WebhookRoute, apiv1.CreateWebhookEndpoint)
+	apiEndpoints.GET(apiv1.ListWebhooksRoute, apiv1.ListWebhooksEndpoint)
+	apiEndpoints.DELETE(apiv1.DeleteWebhookRoute, apiv1.DeleteWebhookEndpoint)
+	apiEndpoints.GET(apiv1.ListDeliveriesRoute, apiv1.ListDeliveriesEndpoint)
+
+	// task endpoints
+	webhook := e.Group(apiv1.WebhookNamespacePrefix)
+	webhook.GET(apiv1.ScheduleTask, apiv1.ScheduleTaskEndpoint)
+	webhook.POST(apiv1.BuildTask, apiv1.BuildTaskEndpoint)
+	webhook.GET(apiv1.PurgeTrashTask, apiv1.PurgeTrashTaskEndpoint)
+	webhook.GET(apiv1.StatsTask, apiv1.StatsTaskEndpoint)
+	webhook.POST(apiv1.DeliverTask, apiv1.DeliverTaskEndpoint)
 
 	// grapghql endpoints
 	gql := e.Group(apiv1.GraphqlNamespacePrefix)
@@ -13,6 +13,7 @@ import (
 	"github.com/txsvc/platform/v2/provider/local"
 
 	"github.com/podops/podops/apiv1"
+	"github.com/podops/podops/backend"
 	"github.com/podops/podops/internal/cdn"
 )
 
@@ -35,10 +36,34 @@ func setup() *echo.Echo {
 	webhook.POST(apiv1.ImportTask, cdn.ImportTaskEndpoint)
 	webhook.POST(apiv1.SyncTask, cdn.SyncTaskEndpoint)
 	webhook.DELETE(apiv1.DeleteTask, cdn.DeleteTaskEndpoint)
+	webhook.DELETE(apiv1.PurgeProductionTask, cdn.PurgeProductionTaskEndpoint)
+	webhook.POST(apiv1.VerifyTask, cdn.VerifyTaskEndpoint)
+	webhook.POST(apiv1.RepairTask, cdn.RepairTaskEndpoint)
 	webhook.POST(apiv1.UploadRoute, cdn.UploadEndpoint)
+	// resumable uploads
+	webhook.POST(apiv1.CreateUploadRoute, cdn.CreateUploadEndpoint)
+	webhook.HEAD(apiv1.UploadChunkRoute, cdn.UploadOffsetEndpoint)
+	webhook.PATCH(apiv1.UploadChunkRoute, cdn.UploadChunkEndpoint)
+	webhook.DELETE(apiv1.UploadChunkRoute, cdn.DeleteUploadEndpoint)
+
+	// admin endpoints
+	admin := e.Group(apiv1.AdminNamespacePrefix)
+	admin.GET(apiv1.VerifyRoute, cdn.VerifyEndpoint)
+	admin.POST(apiv1.VerifyRoute, cdn.VerifyEndpoint)
+	admin.GET(apiv1.ReconcileRoute, cdn.ReconcileEndpoint)
+	admin.POST(apiv1.ReconcileRoute, cdn.ReconcileEndpoint)
+	admin.GET(apiv1.RepairReportRoute, cdn.RepairReportEndpoint)
 
 	// redirect to the real feed.xml path
 	e.GET(apiv1.FeedRoute, cdn.FeedEndpoint)
+	// the subscribers' feeds are served directly
+	e.GET(apiv1.PrivateFeedRoute, cdn.PrivateFeedEndpoint)
+
+	// the built-in WebSub hub is optional
+	if backend.BuiltinHubEnabled() {
+		e.POST(apiv1.HubRoute, cdn.HubEndpoint)
+		webhook.POST(apiv1.DistributeTask, cdn.DistributeTaskEndpoint)
+	}
 
 	return e
 }
@@ -52,10 +77,17 @@ func init() {
 	if !env.Assert("PROJECT_ID") {
 		log.Fatal("Missing env variable 'PROJECT_ID'")
 	}
+	if !env.Assert("LOCATION_ID") {
+		log.Fatal("Missing env variable 'LOCATION_ID'")
+	}
+	if !env.Assert("DEFAULT_QUEUE") {
+		log.Fatal("Missing env variable 'DEFAULT_QUEUE'")
+	}
 
 	local.InitLocalProviders()
 	p := platform.DefaultPlatform()
-	err := p.RegisterProviders(true, google.GoogleErrorReportingConfig, google.GoogleCloudLoggingConfig, google.GoogleCloudMetricsConfig)
+	// the task queue delivers the events of imported assets to webhooks
+	err := p.RegisterProviders(true, google.GoogleErrorReportingConfig, google.GoogleCloudTaskConfig, google.GoogleCloudLoggingConfig, google.GoogleCloudMetricsConfig)
 	if err != nil {
 		log.Fatal("error initializing the platform services")
 	}
@@ -81,6 +81,14 @@ func setupCommands() []*cli.Command {
 			Action:    cmd.UpdateCommand,
 			Flags:     createFlags(),
 		},
+		{
+			Name:      "apply",
+			Usage:     "Create or update all resources from a file, directory or URL that have changed",
+			UsageText: applyUsageText,
+			Category:  ShowCmdGroup,
+			Action:    cmd.ApplyCommand,
+			Flags:     applyFlags(),
+		},
 		{
 			Name:      "get",
 			Usage:     "List one or many resources",
@@ -94,6 +102,42 @@ func setupCommands() []*cli.Command {
 			UsageText: "po delete [show|episode] ID",
 			Category:  ShowCmdGroup,
 			Action:    cmd.DeleteResourcesCommand,
+			Flags:     createFlags(),
+		},
+		{
+			Name:      "trash",
+			Usage:     "List deleted resources",
+			UsageText: trashUsageText,
+			Category:  ShowCmdGroup,
+			Action:    cmd.TrashCommand,
+		},
+		{
+			Name:      "undelete",
+			Usage:     "Restore a deleted resource from the trash",
+			UsageText: "po undelete ID",
+			Category:  ShowCmdGroup,
+			Action:    cmd.UndeleteCommand,
+		},
+		{
+			Name:      "history",
+			Usage:     "List the revisions of a show or episode",
+			UsageText: "po history ID",
+			Category:  ShowCmdGroup,
+			Action:    cmd.HistoryCommand,
+		},
+		{
+			Name:      "diff",
+			Usage:     "Show the changes between two revisions",
+			UsageText: "po diff ID REV1 REV2",
+			Category:  ShowCmdGroup,
+			Action:    cmd.DiffCommand,
+		},
+		{
+			Name:      "restore",
+			Usage:     "Restore a show or episode to an earlier revision",
+			UsageText: restoreUsageText,
+			Category:  ShowCmdGroup,
+			Action:    cmd.RestoreCommand,
 		},
 		{
 			Name:      "template",
@@ -112,9 +156,44 @@ func setupCommands() []*cli.Command {
 			Action:    cmd.NewProductionCommand,
 			Flags:     newShowFlags(),
 		},
+		{
+			Name:      "rename",
+			Usage:     "Rename the podcast, the old feed URL redirects to the new one",
+			UsageText: "po rename NAME",
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.RenameProductionCommand,
+		},
+		{
+			Name:      "archive",
+			Usage:     "Make the podcast read-only and freeze its feed",
+			UsageText: "po archive",
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.ArchiveProductionCommand,
+		},
+		{
+			Name:      "unarchive",
+			Usage:     "Make an archived podcast writable again",
+			UsageText: "po unarchive",
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.UnarchiveProductionCommand,
+		},
+		{
+			Name:      "transfer",
+			Usage:     "Transfer the podcast to another account",
+			UsageText: "po transfer EMAIL",
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.TransferProductionCommand,
+		},
+		{
+			Name:      "destroy",
+			Usage:     "Move a podcast with all its resources, assets and feeds to the trash",
+			UsageText: "po destroy ID",
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.DeleteProductionCommand,
+		},
 		{
 			Name:      "upload",
-			Usage:     "Upload an asset from a file",
+			Usage:     "Upload an asset from a file, resuming an interrupted upload unless --force is set",
 			UsageText: "upload FILENAME",
 			Category:  ShowBuildCmdGroup,
 			Action:    cmd.UploadCommand,
@@ -123,9 +202,169 @@ func setupCommands() []*cli.Command {
 		{
 			Name:      "build",
 			Usage:     "Build the podcast feed",
-			UsageText: "po build",
+			UsageText: buildUsageText,
 			Category:  ShowBuildCmdGroup,
 			Action:    cmd.BuildCommand,
+			Flags:     buildFlags(),
+			Subcommands: []*cli.Command{
+				{
+					Name:      "logs",
+					Usage:     "Show the state and issues of a build",
+					UsageText: "po build logs ID",
+					Action:    cmd.BuildLogsCommand,
+				},
+			},
+		},
+		{
+			Name:      "rollback",
+			Usage:     "Republish the feed of an earlier build",
+			UsageText: rollbackUsageText,
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.RollbackCommand,
+		},
+		{
+			Name:      "builds",
+			Usage:     "List the most recent builds",
+			UsageText: "po builds",
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.ListBuildsCommand,
+		},
+		{
+			Name:      "schedule",
+			Usage:     "List the upcoming episodes",
+			UsageText: "po schedule",
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.ScheduleCommand,
+		},
+		{
+			Name:      "lint",
+			Usage:     "Check the podcast against the Apple Podcasts and RSS 2.0 rules",
+			UsageText: lintUsageText,
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.LintCommand,
+		},
+		{
+			Name:      "verify",
+			Usage:     "Check the files of the podcast in the CDN for corruption (admin only)",
+			UsageText: verifyUsageText,
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.VerifyCommand,
+			Flags:     verifyFlags(),
+		},
+		{
+			Name:      "fsck",
+			Usage:     "Reconcile the inventory, the production bucket and the CDN (admin only)",
+			UsageText: fsckUsageText,
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.FsckCommand,
+			Flags:     fsckFlags(),
+		},
+		{
+			Name:      "import-feed",
+			Usage:     "Import the show and episodes from an existing feed",
+			UsageText: importFeedUsageText,
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.ImportFeedCommand,
+			Flags:     createFlags(),
+		},
+		{
+			Name:      "stats",
+			Usage:     "List the downloads of the podcast or an episode",
+			UsageText: statsUsageText,
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.StatsCommand,
+			Flags:     statsFlags(),
+		},
+		{
+			Name:      "subscribers",
+			Usage:     "List the subscribers of a private feed",
+			UsageText: "po subscribers",
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.ListSubscribersCommand,
+		},
+		{
+			Name:      "subscribe",
+			Usage:     "Issue a subscriber token for the private feed",
+			UsageText: subscribeUsageText,
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.SubscribeCommand,
+		},
+		{
+			Name:      "unsubscribe",
+			Usage:     "Revoke a subscriber token",
+			UsageText: "po unsubscribe TOKEN",
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.UnsubscribeCommand,
+		},
+		{
+			Name:      "members",
+			Usage:     "List the members of the podcast",
+			UsageText: membersUsageText,
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.ListMembersCommand,
+			Subcommands: []*cli.Command{
+				{
+					Name:      "list",
+					Usage:     "List the members of the podcast",
+					UsageText: "po members list",
+					Action:    cmd.ListMembersCommand,
+				},
+				{
+					Name:      "add",
+					Usage:     "Invite someone to work on the podcast",
+					UsageText: "po members add EMAIL --role [owner|publisher|editor|viewer]",
+					Action:    cmd.AddMemberCommand,
+					Flags:     memberFlags(),
+				},
+				{
+					Name:      "remove",
+					Usage:     "Revoke the access of a member",
+					UsageText: "po members remove EMAIL",
+					Action:    cmd.RemoveMemberCommand,
+				},
+			},
+		},
+		{
+			Name:      "webhooks",
+			Usage:     "List the webhooks of the podcast",
+			UsageText: webhooksUsageText,
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.ListWebhooksCommand,
+			Subcommands: []*cli.Command{
+				{
+					Name:      "list",
+					Usage:     "List the webhooks of the podcast",
+					UsageText: "po webhooks list",
+					Action:    cmd.ListWebhooksCommand,
+				},
+				{
+					Name:      "add",
+					Usage:     "Send events of the podcast to a URL",
+					UsageText: "po webhooks add URL [--event EVENT]...",
+					Action:    cmd.AddWebhookCommand,
+					Flags:     webhookFlags(),
+				},
+				{
+					Name:      "remove",
+					Usage:     "Stop sending events to a webhook",
+					UsageText: "po webhooks remove ID",
+					Action:    cmd.RemoveWebhookCommand,
+				},
+				{
+					Name:      "log",
+					Usage:     "Show the most recent deliveries of events",
+					UsageText: "po webhooks log",
+					Action:    cmd.WebhookLogCommand,
+				},
+			},
+		},
+		{
+			Name:      "serve",
+			Usage:     "Preview the podcast feed from local resources",
+			UsageText: serveUsageText,
+			Category:  ShowBuildCmdGroup,
+			Action:    cmd.ServeCommand,
+			Flags:     serveFlags(),
 		},
 		// settings
 		{
@@ -176,13 +415,115 @@ func createFlags() []cli.Flag {
 	f := []cli.Flag{
 		&cli.BoolFlag{
 			Name:    "force",
-			Usage:   "Force create/update/upload",
+			Usage:   "Force create/update/delete/upload",
 			Aliases: []string{"f"},
 		},
 	}
 	return f
 }
 
+func fsckFlags() []cli.Flag {
+	f := []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "repair",
+			Usage: "Repair the issues that were found",
+		},
+	}
+	return f
+}
+
+func verifyFlags() []cli.Flag {
+	f := []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "last",
+			Usage: "Show the report of the latest check instead of starting a new one",
+		},
+	}
+	return f
+}
+
+func applyFlags() []cli.Flag {
+	f := []cli.Flag{
+		&cli.StringFlag{
+			Name:    "filename",
+			Usage:   "File, directory or URL with the resources",
+			Aliases: []string{"f"},
+		},
+		&cli.BoolFlag{
+			Name:  "prune",
+			Usage: "Delete episodes that are not part of the resources",
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "Show what would change without changing anything",
+		},
+		&cli.BoolFlag{
+			Name:  "yes",
+			Usage: "Don't ask before deleting episodes",
+		},
+	}
+	return f
+}
+
+func serveFlags() []cli.Flag {
+	f := []cli.Flag{
+		&cli.IntFlag{
+			Name:  "port",
+			Usage: "Port of the preview server",
+			Value: 8080,
+		},
+	}
+	return f
+}
+
+func buildFlags() []cli.Flag {
+	f := []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "wait",
+			Usage: "Wait for the build to finish",
+		},
+		&cli.BoolFlag{
+			Name:  "strict",
+			Usage: "Verify all enclosures and images before publishing the feed",
+		},
+	}
+	return f
+}
+
+func statsFlags() []cli.Flag {
+	f := []cli.Flag{
+		&cli.IntFlag{
+			Name:  "days",
+			Usage: "Number of days, including today",
+			Value: 30,
+		},
+	}
+	return f
+}
+
+func memberFlags() []cli.Flag {
+	f := []cli.Flag{
+		&cli.StringFlag{
+			Name:    "role",
+			Usage:   "Role of the member: owner, publisher, editor or viewer",
+			Aliases: []string{"r"},
+			Value:   podops.RoleEditor,
+		},
+	}
+	return f
+}
+
+func webhookFlags() []cli.Flag {
+	f := []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "event",
+			Usage:   "Event to send, can be repeated. All events are sent if none is given",
+			Aliases: []string{"e"},
+		},
+	}
+	return f
+}
+
 func templateFlags() []cli.Flag {
 	f := []cli.Flag{
 		&cli.StringFlag{
@@ -227,6 +568,179 @@ To see the full list of supported commands, run 'po help'`
 	 # Show details about a resource
 	 po get ID`
 
+	applyUsageText = `apply -f [FILENAME|DIR|URL] [--prune] [--dry-run] [--yes]
+
+	 # Create or update the show and all episodes in a directory
+	 po apply -f DIR
+
+	 # Show what would be created, updated or deleted
+	 po apply -f DIR --prune --dry-run
+
+	 # Also delete all episodes on the server that don't exist in the directory
+	 po apply -f DIR --prune
+
+	 Deleted episodes are moved to the trash, --prune asks for confirmation first unless --yes is set.
+
+	 Only resources that differ from the server copy are updated, the differences are shown as a diff.`
+
+	buildUsageText = `build [--wait] [--strict]
+
+	 # Queue a build of the feed
+	 po build
+
+	 # Queue a build and wait for it to finish, e.g. in a CI pipeline
+	 po build --wait
+
+	 # Fail the build if an enclosure or image is missing, incomplete or differs from the feed
+	 po build --strict --wait
+
+	 # Show the state and the issues of a build
+	 po build logs ID
+
+	 Builds run in the background. Use 'po builds' to list the most recent builds.`
+
+	restoreUsageText = `restore ID REV
+
+	 # List the revisions of an episode
+	 po history ID
+
+	 # Restore revision 3 of the episode
+	 po restore ID 3
+
+	 The restored content becomes the latest revision, nothing is lost. Run 'po build' to publish the change.`
+
+	trashUsageText = `trash
+
+	 # List the deleted resources of the current production
+	 po trash
+
+	 # Restore a deleted episode
+	 po undelete ID
+
+	 # Restore a deleted production
+	 po undelete --prod ID ID
+
+	 Deleted resources and productions are kept in the trash for 30 days before they are removed for good.`
+
+	rollbackUsageText = `rollback [BUILD]
+
+	 # Republish the feed that was live before the latest build
+	 po rollback
+
+	 # Republish the feed of a specific build
+	 po rollback BUILD
+
+	 Use 'po builds' to find the ID of a build. The next build publishes the current episodes again.`
+
+	lintUsageText = `lint [DIR]
+
+	 # Check the current production, including its artwork in the CDN
+	 po lint
+
+	 # Check the show-*.yaml and episode-*.yaml in a directory before uploading them
+	 po lint DIR
+
+	 Every issue is listed with the ID of the rule it violates. The command fails if there are errors, warnings are ignored.`
+
+	verifyUsageText = `verify [--last]
+
+	 # Re-hash the files of the current production in the CDN
+	 po verify
+
+	 # Show the report of the latest check
+	 po verify --last
+
+	 Every file is compared with the digest recorded when it was uploaded or imported, and with its copy in the production bucket.
+	 Problems are 'corrupted', 'missing', 'drift' (differs from the bucket), 'untracked' (unknown file) and
+	 'unverified' (no digest recorded). The check runs in the background on the CDN, the command waits for its report.
+	 Requires an API token with admin scope. The command fails if there are issues.`
+
+	fsckUsageText = `fsck [--repair]
+
+	 # Compare the inventory, the production bucket and the CDN of the current production
+	 po fsck
+
+	 # Repair what can be repaired
+	 po fsck --repair
+
+	 Finds orphans, missing files and mismatched sizes. Repairs restore .yaml files from their latest revision,
+	 import assets from their origin again, re-create missing inventory entries and remove files nobody refers to.
+	 Files written within the last hour are not treated as orphans, their upload or import might still be running.
+	 The repair runs in the background on the CDN, the command waits for its report.
+	 Requires an API token with admin scope. The command fails if issues remain.`
+
+	importFeedUsageText = `import-feed URL
+
+	 # Create the show and all episodes of the current production from an existing RSS feed
+	 po import-feed https://example.com/feed.xml
+
+	 # Overwrite resources that already exist
+	 po import-feed --force URL
+
+	 Images and media files are imported into the CDN in the background.`
+
+	statsUsageText = `stats [ID]
+
+	 # List the downloads of all episodes in the last 30 days
+	 po stats
+
+	 # List the daily downloads of an episode in the last week
+	 po stats --days 7 ID
+
+	 Downloads are counted following the IAB v2 guidelines: bots are ignored and repeated requests
+	 of the same listener within 24 hours count as one download.`
+
+	subscribeUsageText = `subscribe NAME
+
+	 # Issue a token for a subscriber, e.g. identified by their email
+	 po subscribe jane@example.com
+
+	 Each subscriber gets a unique feed URL. Besides the public episodes, it contains all episodes labeled 'private: yes'.
+	 Shows labeled 'private: yes' only have subscriber feeds. Use 'po unsubscribe TOKEN' to revoke access.`
+
+	membersUsageText = `members [list|add|remove]
+
+	 # List the members of the podcast
+	 po members
+
+	 # Invite an editor, invitees without an account get access after their first 'po login'
+	 po members add jane@example.com --role editor
+
+	 # Revoke the access of a member
+	 po members remove jane@example.com
+
+	 Owners can do everything but transfer or destroy the podcast, publishers can also build the feed, editors can change the show and episodes
+	 and viewers can only read them.`
+
+	webhooksUsageText = `webhooks [list|add|remove|log]
+
+	 # List the webhooks of the podcast
+	 po webhooks
+
+	 # Send build results to a URL, all events are sent if no --event is given
+	 po webhooks add https://example.com/hooks/podops --event build.succeeded --event build.failed
+
+	 # Show the most recent deliveries and their HTTP status
+	 po webhooks log
+
+	 # Stop sending events to a webhook
+	 po webhooks remove ID
+
+	 Events: build.succeeded, build.failed, episode.published, resource.updated, asset.imported and asset.deleted.
+	 Webhooks must be https URLs of public hosts, redirects are not followed. Each request carries the headers
+	 'X-Podops-Timestamp: SECONDS' and 'X-Podops-Signature: sha256=HMAC', the HMAC-SHA256 of 'SECONDS.BODY' keyed
+	 with the secret shown by 'po webhooks add'. Failed deliveries are retried with increasing delays.`
+
+	serveUsageText = `serve [DIR]
+
+	 # Preview the feed built from the show-*.yaml and episode-*.yaml in the current directory
+	 po serve
+
+	 # Preview the feed on a different port
+	 po serve --port 9000 DIR
+
+	 The feed is available at http://localhost:8080/feed.xml and is rebuilt whenever a file in DIR changes.`
+
 	loginUsageText = `login EMAIL [TOKEN]
 
 	 # Login to the service
@@ -16,6 +16,12 @@ const (
 	defaultCDNEndpoint     = "https://cdn.podops.dev"
 	defaultStorageEndpoint = "https://storage.podops.dev"
 	defaultStorageLocation = "/data/storage/cdn"
+	defaultUploadLocation  = "/data/storage/uploads"
+
+	defaultHubRoute = "/hub"
+
+	defaultStorageBackend     = "google"
+	defaultLocalStoreLocation = "/data/storage/local"
 
 	machineEntry = "api.podops.dev"
 )
@@ -33,11 +39,27 @@ var (
 	// DefaultStorageEndpoint is the direct link to assets in the CDN
 	DefaultStorageEndpoint string = env.GetString("STORAGE_ENDPOINT", defaultStorageEndpoint)
 
+	// DefaultHubEndpoint is the built-in WebSub hub of the CDN
+	DefaultHubEndpoint string = DefaultCDNEndpoint + defaultHubRoute
+
+	// WebSubHubs is a comma-separated list of WebSub hubs that are declared in the feeds and notified after each build.
+	// Add DefaultHubEndpoint to enable the built-in hub.
+	WebSubHubs = env.GetString("WEBSUB_HUBS", "")
+
 	// BucketProduction is the canonical name of the production bucket
 	BucketProduction string = env.GetString("BUCKET_PRODUCTION", defaultBucketProduction)
 
 	// StorageLocation is the root location for the cdn
 	StorageLocation = env.GetString("STORAGE_LOCATION", defaultStorageLocation)
+
+	// UploadLocation is the staging area of the CDN for uploads in progress. It should be on the same volume as StorageLocation.
+	UploadLocation = env.GetString("UPLOAD_LOCATION", defaultUploadLocation)
+
+	// StorageBackend selects the inventory and bucket implementation, 'google' or 'local'
+	StorageBackend = env.GetString("STORAGE_BACKEND", defaultStorageBackend)
+
+	// LocalStoreLocation is the root location of the inventory and bucket if STORAGE_BACKEND=local
+	LocalStoreLocation = env.GetString("LOCAL_STORE_LOCATION", defaultLocalStoreLocation)
 )
 
 // DefaultClientOptions returns a default configuration bases on ENV variables
@@ -0,0 +1,132 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/messagedef"
+	"github.com/podops/podops/internal/validator"
+)
+
+const (
+	// RuleAssetMissing the asset must exist in the CDN, or at its origin if it is external
+	RuleAssetMissing = "asset-missing"
+	// RuleAssetIncomplete an imported asset must be fully imported into the CDN
+	RuleAssetIncomplete = "asset-incomplete"
+	// RuleAssetSize the size in the feed must match the stored asset
+	RuleAssetSize = "asset-size"
+	// RuleAssetType the content type in the feed must match the stored asset
+	RuleAssetType = "asset-type"
+)
+
+// AssureAssets verifies that the show's image and the enclosure and image of every episode can be downloaded,
+// and that their size and content type match the stored assets. Problems are reported to v, by episode.
+func AssureAssets(ctx context.Context, production string, show *podops.Show, episodes []*podops.Episode, v *validator.Validator) error {
+	if err := assureAsset(ctx, v, production, "show image", &show.Image, false); err != nil {
+		return err
+	}
+	for _, e := range episodes {
+		name := fmt.Sprintf("episode '%s'", e.Metadata.Name)
+		if err := assureAsset(ctx, v, production, name+" enclosure", &e.Enclosure, true); err != nil {
+			return err
+		}
+		if e.Image.URI == "" {
+			continue // the episode uses the show's image
+		}
+		if err := assureAsset(ctx, v, production, name+" image", &e.Image, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assureAsset checks a single asset. Only errors accessing the inventory are returned, everything else is reported to v.
+func assureAsset(ctx context.Context, v *validator.Validator, production, name string, a *podops.Asset, enclosure bool) error {
+	uri := a.URI
+	if !strings.HasPrefix(uri, podops.DefaultStorageEndpoint) {
+		uri = a.ResolveURI(podops.DefaultStorageEndpoint, production)
+	}
+
+	if a.Rel != podops.ResourceTypeLocal && a.Rel != podops.ResourceTypeImport {
+		// external assets can only be checked at their origin
+		status, header, err := backend.HeadURL(uri)
+		if err != nil || !backend.Reachable(status) {
+			v.AssertRule(RuleAssetMissing, validator.AssertionError, fmt.Sprintf(messagedef.MsgAssetUnreachable, name, uri, describeStatus(status, err)))
+			return nil
+		}
+		if size := contentLength(header); enclosure && size > 0 && a.Size > 0 && size != int64(a.Size) {
+			v.AssertRule(RuleAssetSize, validator.AssertionError, fmt.Sprintf(messagedef.MsgAssetSizeMismatch, name, uri, size, a.Size))
+		}
+		if ct := header.Get("Content-Type"); enclosure && ct != "" && a.Type != "" && ct != a.Type {
+			v.AssertRule(RuleAssetType, validator.AssertionError, fmt.Sprintf(messagedef.MsgAssetTypeMismatch, name, uri, ct, a.Type))
+		}
+		return nil
+	}
+
+	// local and imported assets have to be in the inventory ...
+	m, err := backend.GetAssetMetadata(ctx, production, uri, a.Rel)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		if a.Rel == podops.ResourceTypeImport {
+			v.AssertRule(RuleAssetIncomplete, validator.AssertionError, fmt.Sprintf(messagedef.MsgAssetNotImported, name, uri))
+		} else {
+			v.AssertRule(RuleAssetMissing, validator.AssertionError, fmt.Sprintf(messagedef.MsgAssetNotInventoried, name, uri))
+		}
+		return nil
+	}
+
+	// ... and on disk. Private assets can't be downloaded without a subscriber token, their metadata has to do.
+	private, err := backend.IsPrivateAsset(ctx, production, a.AssetName())
+	if err != nil {
+		return err
+	}
+	if !private {
+		status, header, err := backend.HeadURL(uri)
+		if err != nil || !backend.Reachable(status) {
+			v.AssertRule(RuleAssetMissing, validator.AssertionError, fmt.Sprintf(messagedef.MsgAssetUnreachable, name, uri, describeStatus(status, err)))
+			return nil
+		}
+		if size := contentLength(header); size >= 0 && size != m.Size {
+			rule := RuleAssetSize
+			if a.Rel == podops.ResourceTypeImport {
+				rule = RuleAssetIncomplete
+			}
+			v.AssertRule(rule, validator.AssertionError, fmt.Sprintf(messagedef.MsgAssetSizeMismatch, name, uri, size, m.Size))
+			return nil
+		}
+	}
+
+	// the feed announces what the inventory says
+	if enclosure {
+		if a.Size > 0 && int64(a.Size) != m.Size {
+			v.AssertRule(RuleAssetSize, validator.AssertionError, fmt.Sprintf(messagedef.MsgAssetSizeMismatch, name, uri, m.Size, a.Size))
+		}
+		if a.Type != "" && a.Type != m.ContentType {
+			v.AssertRule(RuleAssetType, validator.AssertionError, fmt.Sprintf(messagedef.MsgAssetTypeMismatch, name, uri, m.ContentType, a.Type))
+		}
+	}
+	return nil
+}
+
+// contentLength returns the Content-Length of a response, or -1 if it is unknown
+func contentLength(header http.Header) int64 {
+	size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return -1
+	}
+	return size
+}
+
+func describeStatus(status int, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return fmt.Sprintf("%d %s", status, http.StatusText(status))
+}
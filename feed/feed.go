@@ -1,12 +1,13 @@
 package feed
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/txsvc/platform/v2"
-	ds "github.com/txsvc/platform/v2/pkg/datastore"
 	"github.com/txsvc/platform/v2/pkg/timestamp"
 
 	"github.com/podops/podops"
@@ -14,6 +15,12 @@ import (
 	"github.com/podops/podops/feed/rss"
 	"github.com/podops/podops/internal/errordef"
 	"github.com/podops/podops/internal/messagedef"
+	"github.com/podops/podops/internal/validator"
+)
+
+const (
+	// PrivateFeedToken is replaced with the subscriber's token when a private feed is served
+	PrivateFeedToken = "{token}"
 )
 
 var mediaTypeMap map[string]rss.EnclosureType
@@ -27,23 +34,32 @@ func init() {
 	mediaTypeMap["video/quicktime"] = rss.MOV
 	mediaTypeMap["application/pdf"] = rss.PDF
 	mediaTypeMap["document/x-epub"] = rss.EPUB
+	mediaTypeMap["audio/ogg"] = rss.OGG
+	mediaTypeMap["audio/flac"] = rss.FLAC
 }
 
-// Build gathers all resources and builds the feed.xml
-func Build(ctx context.Context, production string, validateOnly bool) error {
+// Build gathers all resources and builds the feed.xml. All issues found on the way are collected in v.
+// In strict mode, every enclosure and image is verified before the feed is published.
+// Build returns the generated feed, i.e. the private feed in case of a members-only show.
+func Build(ctx context.Context, production string, validateOnly, strict bool, v *validator.Validator) ([]byte, error) {
 
 	p, err := backend.GetProduction(ctx, production)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if p == nil {
-		return fmt.Errorf(messagedef.MsgResourceNotFound, production)
+		return nil, fmt.Errorf(messagedef.MsgResourceNotFound, production)
+	}
+
+	if v == nil {
+		v = validator.New(production)
 	}
+	if err = backend.ValidateProduction(ctx, production, v); err != nil {
+		v.AssertError(err.Error())
 
-	if err = backend.ValidateProduction(ctx, production); err != nil {
 		p, err := backend.GetProduction(ctx, production)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		p.BuildDate = 0
 		p.Published = false
@@ -51,20 +67,24 @@ func Build(ctx context.Context, production string, validateOnly bool) error {
 
 		backend.UpdateProduction(ctx, p)
 
-		return errordef.ErrFeedFailed
+		return nil, errordef.ErrFeedFailed
+	}
+	if !v.IsValid() {
+		return nil, v.AsError() // e.g. the artwork does not comply with Apple's requirements
 	}
 
 	// list all episodes, excluding future (i.e. unpublished) ones, descending order
 
 	now := timestamp.Now()
-	er, err := backend.ListPublishedEpisodes(ctx, production, now, 1)
+	er, err := backend.ListPublishedEpisodes(ctx, production, now, 0)
 	if err != nil {
 		platform.ReportError(err)
-		return err
+		return nil, err
 	}
 
 	if len(er) == 0 {
-		return errordef.ErrFeedFailed
+		v.AssertError(messagedef.MsgBuildNoEpisodes)
+		return nil, errordef.ErrFeedFailed
 	}
 
 	// read all episodes
@@ -72,7 +92,7 @@ func Build(ctx context.Context, production string, validateOnly bool) error {
 	for i := range er {
 		e, err := backend.GetResourceContent(ctx, er[i].GUID)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		// FIXME filter for other flags, e.g. Block = true
 		episodes[i] = e.(*podops.Episode)
@@ -81,52 +101,140 @@ func Build(ctx context.Context, production string, validateOnly bool) error {
 	// read the show
 	s, err := backend.GetResourceContent(ctx, production)
 	if err != nil {
-		return err
+		return nil, err
 	}
-
-	// build the feed XML
 	show := s.(*podops.Show)
-	feed, err := TransformToPodcast(show)
-	if err != nil {
-		return err
-	}
-
-	tt, _ := time.Parse(time.RFC1123Z, episodes[0].PublishDate())
-	feed.AddPubDate(&tt)
 
-	// FIXME use a -f flag to enforce asset assurance on build
+	if strict {
+		if err := AssureAssets(ctx, production, show, episodes, v); err != nil {
+			return nil, err
+		}
+		if !v.IsValid() {
+			return nil, v.AsError() // don't publish enclosures that can't be downloaded
+		}
+	}
 
+	// the public feed omits private episodes, subscribers get all of them
+	public := make([]*podops.Episode, 0, len(episodes))
 	for _, e := range episodes {
-		item, err := TransformToItem(e)
-		if err != nil {
-			return err
+		if !show.IsPrivate() && !e.IsPrivate() {
+			public = append(public, e)
 		}
-		feed.AddItem(item)
+	}
+
+	// build the feed XML
+	var feed *rss.Channel
+	if !show.IsPrivate() {
+		if feed, err = TransformToFeed(show, public); err != nil {
+			return nil, err
+		}
+		// WebSub subscribers get notified by the hubs when the feed changes
+		feed.AddAtomLink(backend.FeedAliasURL(p.Name))
+		for _, h := range backend.Hubs() {
+			feed.AddHubLink(h)
+		}
+	}
+	privateAssets := tokenizeEnclosures(production, show, episodes)
+	privateFeed, err := TransformToFeed(show, episodes)
+	if err != nil {
+		return nil, err
+	}
+
+	result := privateFeed.Bytes()
+	if feed != nil {
+		result = feed.Bytes()
 	}
 
 	if validateOnly {
-		return nil // no errors so far, the feed is valid
+		return result, nil // no errors so far, the feed is valid
 	}
 
-	// dump the feed to the CDN
-	obj := ds.Storage().Bucket(podops.BucketProduction).Object(fmt.Sprintf("%s/feed.xml", production))
-	writer := obj.NewWriter(ctx)
-	if _, err := writer.Write(feed.Bytes()); err != nil {
-		return err
+	// dump the feeds to the production bucket. Only the public feed is synced to the CDN.
+	if feed != nil {
+		if err := backend.DefaultBlobStore().Write(ctx, fmt.Sprintf("%s/feed.xml", production), result); err != nil {
+			return nil, err
+		}
 	}
-	if err := writer.Close(); err != nil {
-		return err
+	if err := backend.DefaultBlobStore().Write(ctx, fmt.Sprintf("%s/private.xml", production), privateFeed.Bytes()); err != nil {
+		return nil, err
+	}
+
+	// episodes that went live since the last build
+	published := make([]*podops.Resource, 0)
+	for _, e := range er {
+		if e.Published > p.LatestPublishDate {
+			published = append(published, e)
+		}
 	}
 
 	// source data should be OK by now, we can update the metadata
 	p.BuildDate = timestamp.Now()
 	p.Published = true
 	p.LatestPublishDate = er[0].Published
+	p.Private = show.IsPrivate()
+	p.PrivateAssets = privateAssets
 	if err := backend.UpdateProduction(ctx, p); err != nil {
-		return err
+		return nil, err
+	}
+	for _, e := range published {
+		backend.Emit(ctx, production, podops.EventEpisodePublished, e.GUID, e)
 	}
 
-	return nil
+	// the next build is due when the next scheduled episode goes live. The feed is published
+	// already, a failure only delays the next scheduled build.
+	if err := backend.UpdateSchedule(ctx, production); err != nil {
+		platform.ReportError(err)
+	}
+	return result, nil
+}
+
+// tokenizeEnclosures adds the subscriber token to the enclosures of private episodes and returns
+// the names of the assets in the CDN that require a token.
+func tokenizeEnclosures(production string, show *podops.Show, episodes []*podops.Episode) []string {
+	prefix := fmt.Sprintf("%s/%s/", podops.DefaultStorageEndpoint, production)
+	assets := make([]string, 0)
+
+	for _, e := range episodes {
+		if !show.IsPrivate() && !e.IsPrivate() {
+			continue
+		}
+		if !strings.HasPrefix(e.Enclosure.URI, prefix) {
+			continue // external enclosures are not protected by the CDN
+		}
+		assets = append(assets, e.Enclosure.AssetName())
+		e.Enclosure.URI = e.Enclosure.URI + "?token=" + PrivateFeedToken
+	}
+	return assets
+}
+
+// PrivateFeed returns a copy of the private feed of a production, personalized for the subscriber with token
+func PrivateFeed(data []byte, token string) []byte {
+	return bytes.ReplaceAll(data, []byte(PrivateFeedToken), []byte(token))
+}
+
+// TransformToFeed creates the podcast feed struct from a show and its episodes. Episodes are expected in descending order of their publish date.
+func TransformToFeed(show *podops.Show, episodes []*podops.Episode) (*rss.Channel, error) {
+	if len(episodes) == 0 {
+		return nil, errordef.ErrFeedFailed
+	}
+
+	feed, err := TransformToPodcast(show)
+	if err != nil {
+		return nil, err
+	}
+
+	tt, _ := time.Parse(time.RFC1123Z, episodes[0].PublishDate())
+	feed.AddPubDate(&tt)
+
+	for _, e := range episodes {
+		item, err := TransformToItem(e)
+		if err != nil {
+			return nil, err
+		}
+		feed.AddItem(item)
+	}
+
+	return feed, nil
 }
 
 // TransformToPodcast transforms Show metadata into a podcast feed struct
@@ -168,6 +276,20 @@ func TransformToPodcast(s *podops.Show) (*rss.Channel, error) {
 		pf.IComplete = "yes"
 	}
 
+	// podcast namespace
+	pf.PGUID = s.Metadata.Labels[podops.LabelPodcastGUID]
+	if locked, ok := s.Metadata.Labels[podops.LabelLocked]; ok {
+		pf.AddLocked(locked == "yes", s.Description.Owner.Email)
+	}
+	for _, f := range s.Funding {
+		pf.AddFunding(f.URI, f.Title)
+	}
+	for i := range s.Persons {
+		pf.AddPerson(transformPerson(&s.Persons[i]))
+	}
+	pf.PLocation = transformLocation(s.Location)
+	pf.PValue = transformValue(s.Value)
+
 	return &pf, nil
 }
 
@@ -197,6 +319,9 @@ func TransformToItem(e *podops.Episode) (*rss.Item, error) {
 	ef.Link = e.Description.Link.URI
 	ef.ISubtitle = e.Description.Summary
 	ef.GUID = e.Metadata.Labels[podops.LabelGUID]
+	if guid := e.Metadata.Labels[podops.LabelItemGUID]; guid != "" {
+		ef.GUID = guid
+	}
 	ef.IExplicit = e.Metadata.Labels[podops.LabelExplicit]
 	ef.ISeason = e.Metadata.Labels[podops.LabelSeason]
 	ef.IEpisode = e.Metadata.Labels[podops.LabelEpisode]
@@ -205,5 +330,207 @@ func TransformToItem(e *podops.Episode) (*rss.Item, error) {
 		ef.IBlock = "yes"
 	}
 
+	// podcast namespace
+	for _, t := range e.Transcripts {
+		ef.AddTranscript(t.URI, t.Type, "")
+	}
+	if e.Chapters != nil {
+		ef.AddChapters(e.Chapters.URI)
+	}
+	for i := range e.Persons {
+		ef.AddPerson(transformPerson(&e.Persons[i]))
+	}
+	for _, sb := range e.Soundbites {
+		ef.AddSoundbite(sb.Start, sb.Duration, sb.Title)
+	}
+	ef.PLocation = transformLocation(e.Location)
+	ef.PValue = transformValue(e.Value)
+
 	return ef, nil
 }
+
+func transformPerson(p *podops.Person) *rss.PPerson {
+	return &rss.PPerson{
+		Name:  p.Name,
+		Role:  p.Role,
+		Group: p.Group,
+		Img:   p.Image,
+		Href:  p.Link,
+	}
+}
+
+func transformLocation(l *podops.Location) *rss.PLocation {
+	if l == nil {
+		return nil
+	}
+	return &rss.PLocation{
+		Name: l.Name,
+		Geo:  l.Geo,
+		OSM:  l.OSM,
+	}
+}
+
+func transformValue(v *podops.Value) *rss.PValue {
+	if v == nil {
+		return nil
+	}
+	pv := &rss.PValue{
+		Type:      v.Type,
+		Method:    v.Method,
+		Suggested: v.Suggested,
+	}
+	for _, r := range v.Recipients {
+		pv.Recipients = append(pv.Recipients, &rss.PValueRecipient{
+			Name:        r.Name,
+			CustomKey:   r.CustomKey,
+			CustomValue: r.CustomValue,
+			Type:        r.Type,
+			Address:     r.Address,
+			Split:       r.Split,
+			Fee:         r.Fee,
+		})
+	}
+	return pv
+}
+
+// Rollback republishes the feeds of an earlier build and restores the production's metadata to match.
+// The rollback itself is recorded as a new build. Without a buildID, the feed published before the live one is restored.
+func Rollback(ctx context.Context, production, buildID string) (*podops.Build, error) {
+	p, err := backend.GetProduction(ctx, production)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errordef.ErrNoSuchProduction
+	}
+
+	var target *podops.Build
+	if buildID == "" {
+		target, err = backend.FindRollbackTarget(ctx, production)
+	} else {
+		target, err = backend.GetBuild(ctx, production, buildID)
+	}
+	if err == nil && target != nil && target.RollbackOf != "" {
+		target, err = backend.GetBuild(ctx, production, target.RollbackOf) // a rollback has no feeds of its own
+	}
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		return nil, errordef.ErrNoSuchBuild
+	}
+	if !target.IsPublished() || target.Artifact == "" {
+		return nil, errordef.ErrRollbackFailed
+	}
+
+	// read both feeds before touching anything, a missing artifact leaves the live feed as it is
+	private, err := backend.DefaultBlobStore().Read(ctx, backend.PrivateArtifactLocation(production, target.ID))
+	if err != nil {
+		return nil, errordef.ErrRollbackFailed
+	}
+	var public []byte
+	if !target.Private {
+		if public, err = backend.DefaultBlobStore().Read(ctx, target.Artifact); err != nil {
+			return nil, errordef.ErrRollbackFailed
+		}
+	}
+
+	b, err := backend.CreateBuild(ctx, production, false, false)
+	if err != nil {
+		return nil, err
+	}
+	b.State = podops.BuildRunning
+	b.Started = timestamp.Now()
+	b.RollbackOf = target.ID
+	if err := backend.UpdateBuild(ctx, b); err != nil {
+		return nil, err
+	}
+
+	if err := publishRollback(ctx, p, target, public, private); err != nil {
+		b.State = podops.BuildFailed
+		b.Message = err.Error()
+		b.Finished = timestamp.Now()
+		if err := backend.UpdateBuild(ctx, b); err != nil {
+			platform.ReportError(err)
+		}
+		return nil, err
+	}
+
+	b.State = podops.BuildSucceeded
+	b.Artifact = target.Artifact
+	b.Private = target.Private
+	b.PrivateAssets = target.PrivateAssets
+	b.BuildDate = target.BuildDate
+	b.LatestPublishDate = target.LatestPublishDate
+	b.Finished = timestamp.Now()
+	if err := backend.UpdateBuild(ctx, b); err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// publishRollback replaces the live feeds with the ones of target and updates the production's metadata last.
+// The feeds that were live before are restored if any of the steps fails.
+func publishRollback(ctx context.Context, p *podops.Production, target *podops.Build, public, private []byte) error {
+	publicLocation := fmt.Sprintf("%s/feed.xml", p.GUID)
+	privateLocation := fmt.Sprintf("%s/private.xml", p.GUID)
+
+	livePublic, err := readFeed(ctx, publicLocation)
+	if err != nil {
+		return err
+	}
+	livePrivate, err := readFeed(ctx, privateLocation)
+	if err != nil {
+		return err
+	}
+	restore := func() {
+		restoreFeed(ctx, publicLocation, livePublic)
+		restoreFeed(ctx, privateLocation, livePrivate)
+	}
+
+	// each object is replaced as a whole, subscribers see either the old or the new feed
+	if public != nil {
+		if err := backend.DefaultBlobStore().Write(ctx, publicLocation, public); err != nil {
+			restore()
+			return err
+		}
+	}
+	if err := backend.DefaultBlobStore().Write(ctx, privateLocation, private); err != nil {
+		restore()
+		return err
+	}
+
+	p.BuildDate = target.BuildDate
+	p.Published = true
+	p.LatestPublishDate = target.LatestPublishDate
+	p.Private = target.Private
+	p.PrivateAssets = target.PrivateAssets
+	if err := backend.UpdateProduction(ctx, p); err != nil {
+		restore()
+		return err
+	}
+	return nil
+}
+
+// readFeed returns a feed from the production bucket, or nil if it does not exist
+func readFeed(ctx context.Context, location string) ([]byte, error) {
+	data, err := backend.DefaultBlobStore().Read(ctx, location)
+	if err == errordef.ErrNoSuchObject {
+		return nil, nil
+	}
+	return data, err
+}
+
+// restoreFeed puts back a feed that was live before a failed rollback. Failures are reported, the next build repairs the feed.
+func restoreFeed(ctx context.Context, location string, data []byte) {
+	var err error
+	if data == nil {
+		err = backend.DefaultBlobStore().Remove(ctx, location)
+	} else {
+		err = backend.DefaultBlobStore().Write(ctx, location, data)
+	}
+	if err != nil && err != errordef.ErrNoSuchObject {
+		platform.ReportError(err)
+	}
+}
@@ -0,0 +1,285 @@
+package feed
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/txsvc/platform/v2/pkg/id"
+	"gopkg.in/yaml.v2"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/validator"
+)
+
+func TestPodcastNamespace(t *testing.T) {
+	show := podops.DefaultShow("simple-podcast", "title", "summary", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	episode := podops.DefaultEpisode("episode1", "simple-podcast", "episode-guid", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+
+	// plain feeds don't declare the podcast namespace
+	feed, err := TransformToPodcast(show)
+	if assert.NoError(t, err) {
+		assert.NotContains(t, feed.String(), "xmlns:podcast")
+	}
+
+	show.Metadata.Labels[podops.LabelLocked] = "yes"
+	show.Metadata.Labels[podops.LabelPodcastGUID] = "917393e3-1b1e-5cef-ace4-edaa54e1f810"
+	show.Funding = []podops.Asset{{URI: "https://example.com/donate", Title: "Support the show"}}
+	show.Persons = []podops.Person{{Name: "Jane Doe", Role: "host"}}
+
+	episode.Transcripts = []podops.Asset{{URI: "https://example.com/episode1.vtt", Type: "text/vtt"}}
+	episode.Chapters = &podops.Asset{URI: "https://example.com/episode1.json"}
+	episode.Soundbites = []podops.Soundbite{{Start: 73, Duration: 60, Title: "Highlight"}}
+
+	feed, err = TransformToPodcast(show)
+	if !assert.NoError(t, err) {
+		return
+	}
+	item, err := TransformToItem(episode)
+	if !assert.NoError(t, err) {
+		return
+	}
+	feed.AddItem(item)
+
+	xml := feed.String()
+	for _, s := range []string{
+		`xmlns:podcast="https://podcastindex.org/namespace/1.0"`,
+		`<podcast:guid>917393e3-1b1e-5cef-ace4-edaa54e1f810</podcast:guid>`,
+		`<podcast:locked owner="`,
+		`<podcast:funding url="https://example.com/donate">Support the show</podcast:funding>`,
+		`<podcast:person role="host">Jane Doe</podcast:person>`,
+		`<podcast:transcript url="https://example.com/episode1.vtt" type="text/vtt" rel="captions">`,
+		`<podcast:chapters url="https://example.com/episode1.json" type="application/json+chapters">`,
+		`<podcast:soundbite startTime="73.0" duration="60.0">Highlight</podcast:soundbite>`,
+	} {
+		assert.True(t, strings.Contains(xml, s), s)
+	}
+}
+
+func TestHubLinks(t *testing.T) {
+	show := podops.DefaultShow("simple-podcast", "title", "summary", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+
+	feed, err := TransformToPodcast(show)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotContains(t, feed.String(), "xmlns:atom")
+
+	feed.AddAtomLink("https://podops.dev/s/simple-podcast/feed.xml")
+	feed.AddHubLink("https://cdn.podops.dev/hub")
+
+	xml := feed.String()
+	for _, s := range []string{
+		`xmlns:atom="http://www.w3.org/2005/Atom"`,
+		`<atom:link href="https://podops.dev/s/simple-podcast/feed.xml" rel="self" type="application/rss+xml"></atom:link>`,
+		`<atom:link href="https://cdn.podops.dev/hub" rel="hub"></atom:link>`,
+	} {
+		assert.True(t, strings.Contains(xml, s), s)
+	}
+}
+
+func TestBuildPreview(t *testing.T) {
+	dir := t.TempDir()
+
+	writeResource := func(name string, rsrc interface{}) {
+		data, err := yaml.Marshal(rsrc)
+		if assert.NoError(t, err) {
+			assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), data, 0644))
+		}
+	}
+
+	show := podops.DefaultShow("simple-podcast", "title", "summary", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	writeResource("show-show-guid.yaml", show)
+
+	// one published, one future episode
+	published := podops.DefaultEpisode("episode1", "simple-podcast", "episode1", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	published.Metadata.Labels[podops.LabelDate] = time.Now().Add(-time.Hour).UTC().Format(time.RFC1123Z)
+	writeResource("episode-episode1.yaml", published)
+
+	future := podops.DefaultEpisode("episode2", "simple-podcast", "episode2", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	future.Metadata.Labels[podops.LabelDate] = time.Now().Add(time.Hour).UTC().Format(time.RFC1123Z)
+	writeResource("episode-episode2.yaml", future)
+
+	// the local enclosure, a FLAC header of 90s (44100Hz, 2 channels, 16 bits per sample) padded to 1024 bytes
+	enclosure := make([]byte, 1024)
+	copy(enclosure, []byte{'f', 'L', 'a', 'C', 0x80, 0, 0, 34})
+	enclosure[18], enclosure[19], enclosure[20], enclosure[21] = 0x0A, 0xC4, 0x42, 0xF0
+	binary.BigEndian.PutUint32(enclosure[22:26], 90*44100)
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "show-guid"), os.ModePerm))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "show-guid", "episode1.mp3"), enclosure, 0644))
+
+	feed, err := BuildPreview(dir, "http://localhost:8080")
+	if assert.NoError(t, err) && assert.Equal(t, 1, len(feed.Items)) {
+		assert.Equal(t, "episode1", feed.Items[0].GUID)
+		assert.Equal(t, "http://localhost:8080/show-guid/episode1.mp3", feed.Items[0].Enclosure.URL)
+		assert.Equal(t, int64(1024), feed.Items[0].Enclosure.Length)
+		assert.Equal(t, "1:30", feed.Items[0].IDuration)
+	}
+
+	// a second show is an error
+	writeResource("show-other.yaml", show)
+	_, err = BuildPreview(dir, "http://localhost:8080")
+	assert.Equal(t, errordef.ErrNoLocalShow, err)
+}
+
+func TestTransformFromFeed(t *testing.T) {
+	data := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+<channel>
+	<title>Imported Podcast</title>
+	<link>https://example.com</link>
+	<language>en-us</language>
+	<description>An imported podcast</description>
+	<itunes:author>Jane Doe</itunes:author>
+	<itunes:explicit>false</itunes:explicit>
+	<itunes:type>serial</itunes:type>
+	<itunes:image href="https://example.com/cover.png"/>
+	<itunes:category text="Technology"/>
+	<itunes:owner><itunes:name>Jane Doe</itunes:name><itunes:email>jane@example.com</itunes:email></itunes:owner>
+	<item>
+		<title>Episode 2: Hello World!</title>
+		<guid isPermaLink="false">https://example.com/?p=2</guid>
+		<pubDate>Tue, 2 Mar 2021 10:00:00 +0000</pubDate>
+		<itunes:duration>01:02:03</itunes:duration>
+		<itunes:episodeType>bonus</itunes:episodeType>
+		<content:encoded><![CDATA[<p>Show notes</p>]]></content:encoded>
+		<enclosure url="https://example.com/episode2.mp3" length="1024" type="audio/mpeg"/>
+	</item>
+	<item>
+		<title>Episode 1</title>
+		<guid>episode-1</guid>
+		<pubDate>Mon, 01 Mar 2021 10:00:00 GMT</pubDate>
+		<itunes:episode>1</itunes:episode>
+		<enclosure url="https://example.com/episode1.mp3" length="2048" type="audio/mpeg"/>
+	</item>
+	<item>
+		<title>No enclosure</title>
+	</item>
+</channel>
+</rss>`)
+
+	show, episodes, err := TransformFromFeed(data, "imported-podcast", "show-guid")
+	if !assert.NoError(t, err) || !assert.Equal(t, 2, len(episodes)) {
+		return
+	}
+
+	assert.Equal(t, "en_US", show.Metadata.Labels[podops.LabelLanguage])
+	assert.Equal(t, podops.ShowTypeSerial, show.Metadata.Labels[podops.LabelType])
+	assert.Equal(t, "Technology", show.Description.Category.Name)
+	assert.Equal(t, podops.ResourceTypeImport, show.Image.Rel)
+
+	e := episodes[0]
+	assert.Equal(t, "episode-2-hello-world", e.Metadata.Name)
+	assert.Equal(t, "https://example.com/?p=2", e.Metadata.Labels[podops.LabelItemGUID])
+	assert.NotEqual(t, e.Metadata.Labels[podops.LabelItemGUID], e.GUID())
+	assert.Equal(t, podops.EpisodeTypeBonus, e.Metadata.Labels[podops.LabelType])
+	assert.Equal(t, "Tue, 02 Mar 2021 10:00:00 +0000", e.Metadata.Labels[podops.LabelDate])
+	assert.Equal(t, 3723, e.Description.Duration)
+	assert.Equal(t, "<p>Show notes</p>", e.Description.EpisodeText)
+	assert.Equal(t, podops.ResourceTypeImport, e.Enclosure.Rel)
+	assert.Equal(t, 1024, e.Enclosure.Size)
+
+	// GUIDs are scoped to the production, the same feed imported twice does not collide
+	assert.Equal(t, id.Checksum("show-guid"+"episode-1"), episodes[1].GUID())
+	assert.Equal(t, "episode-1", episodes[1].Metadata.Labels[podops.LabelItemGUID])
+	assert.Equal(t, "1", episodes[1].Metadata.Labels[podops.LabelEpisode])
+
+	// the original guid survives the round trip
+	f, err := TransformToFeed(show, episodes)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "https://example.com/?p=2", f.Items[0].GUID)
+	}
+}
+
+func TestLint(t *testing.T) {
+	show := podops.DefaultShow("simple-podcast", "title", "summary", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	show.Description.Category.SubCategory = nil
+
+	episodes := make([]*podops.Episode, 5)
+	for i := range episodes {
+		name := fmt.Sprintf("episode%d", i+1)
+		e := podops.DefaultEpisode(name, "simple-podcast", name, "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+		e.Metadata.Labels[podops.LabelDate] = time.Now().Add(time.Duration(i-3) * time.Hour).UTC().Format(time.RFC1123Z)
+		e.Metadata.Labels[podops.LabelEpisode] = fmt.Sprintf("%d", i+1)
+		e.Enclosure.Size = 1000
+		episodes[i] = e
+	}
+	// the limits are in characters, not bytes
+	episodes[2].Description.Summary = strings.Repeat("ü", MaxSubtitleLength)
+
+	v := Lint(validator.New("simple-podcast"), show, episodes)
+	assert.True(t, v.IsClean(), v.Report())
+
+	// break some rules
+	show.Description.Category = podops.Category{Name: "Technology", SubCategory: []string{"Podcasting"}}
+	show.Metadata.Labels[podops.LabelType] = podops.ShowTypeSerial
+	show.Metadata.Labels[podops.LabelExplicit] = "maybe"
+	episodes[0].Enclosure.Size = 0
+	episodes[1].Metadata.Labels[podops.LabelEpisode] = "3"
+
+	report := NewLintReport("simple-podcast", Lint(validator.New("simple-podcast"), show, episodes))
+	assert.Equal(t, 3, report.Errors)
+	assert.Equal(t, 1, report.Warnings)
+
+	rules := make(map[string]string)
+	for _, issue := range report.Issues {
+		rules[issue.Rule] = issue.Severity
+	}
+	assert.Equal(t, podops.SeverityWarning, rules[RuleCategory])
+	assert.Equal(t, podops.SeverityError, rules[RuleExplicit])
+	assert.Equal(t, podops.SeverityError, rules[RuleEnclosureLength])
+	assert.Equal(t, podops.SeverityError, rules[RuleSerialOrder])
+}
+
+func TestAssureExternalAssets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/missing.mp3":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case "/private.mp3":
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/mpeg")
+		w.Header().Set("Content-Length", "1024")
+	}))
+	defer srv.Close()
+
+	show := podops.DefaultShow("simple-podcast", "title", "summary", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+	show.Image = podops.Asset{URI: srv.URL + "/cover.png", Rel: podops.ResourceTypeExternal}
+
+	episodes := make([]*podops.Episode, 5)
+	for i := range episodes {
+		episodes[i] = podops.DefaultEpisode(fmt.Sprintf("episode%d", i+1), "simple-podcast", fmt.Sprintf("episode-guid-%d", i+1), "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+		episodes[i].Image = podops.Asset{}
+		episodes[i].Enclosure = podops.Asset{URI: srv.URL + "/episode.mp3", Rel: podops.ResourceTypeExternal, Type: "audio/mpeg", Size: 1024}
+	}
+	episodes[1].Enclosure.URI = srv.URL + "/missing.mp3"
+	episodes[2].Enclosure.Size = 2048
+	episodes[3].Enclosure.URI = srv.URL + "/private.mp3"
+	episodes[4].Enclosure.Type = "audio/x-m4a"
+
+	v := validator.New("simple-podcast")
+	if assert.NoError(t, AssureAssets(context.TODO(), "show-guid", show, episodes, v)) {
+		assert.Equal(t, 4, v.NErrors(), v.Report())
+		assert.Equal(t, RuleAssetMissing, v.Issues[0].Rule)
+		assert.Contains(t, v.Issues[0].Txt, "episode 'episode2' enclosure")
+		assert.Equal(t, RuleAssetSize, v.Issues[1].Rule)
+		assert.Contains(t, v.Issues[1].Txt, "episode 'episode3' enclosure")
+		assert.Equal(t, RuleAssetMissing, v.Issues[2].Rule)
+		assert.Contains(t, v.Issues[2].Txt, "episode 'episode4' enclosure")
+		assert.Equal(t, RuleAssetType, v.Issues[3].Rule)
+		assert.Contains(t, v.Issues[3].Txt, "episode 'episode5' enclosure")
+	}
+}
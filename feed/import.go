@@ -0,0 +1,350 @@
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/txsvc/platform/v2/pkg/id"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+const (
+	itunesNS  = "http://www.itunes.com/dtds/podcast-1.0.dtd"
+	contentNS = "http://purl.org/rss/1.0/modules/content/"
+	podcastNS = "https://podcastindex.org/namespace/1.0"
+
+	// maximum length of a generated episode name
+	maxNameLength = 40
+)
+
+type (
+	// xmlNode is a generic XML element. RSS, iTunes and others share element names like 'title' or 'image',
+	// a tree of nodes makes it easy to tell them apart by their namespace.
+	xmlNode struct {
+		XMLName xml.Name
+		Attrs   []xml.Attr `xml:",any,attr"`
+		Content string     `xml:",chardata"`
+		Nodes   []*xmlNode `xml:",any"`
+	}
+)
+
+var (
+	// different flavours of RFC 822 found in the wild
+	pubDateLayouts = []string{
+		time.RFC1123Z,
+		time.RFC1123,
+		"Mon, 2 Jan 2006 15:04:05 -0700",
+		"Mon, 2 Jan 2006 15:04:05 MST",
+		time.RFC822Z,
+		time.RFC822,
+		"2 Jan 2006 15:04:05 -0700",
+	}
+
+	nameChars = regexp.MustCompile(`[^a-z0-9]+`)
+)
+
+// TransformFromFeed parses an existing RSS/iTunes podcast feed and creates the show and episode resources of production guid.
+//
+// Images and enclosures are referenced as ResourceTypeImport so that they will be imported into the CDN.
+func TransformFromFeed(data []byte, name, guid string) (*podops.Show, []*podops.Episode, error) {
+	var root xmlNode
+
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&root); err != nil {
+		return nil, nil, err
+	}
+	channel := root.child("", "channel")
+	if root.XMLName.Local != "rss" || channel == nil {
+		return nil, nil, errordef.ErrInvalidParameters
+	}
+
+	show := transformFromChannel(channel, name, guid)
+
+	items := channel.children("", "item")
+	episodes := make([]*podops.Episode, 0, len(items))
+	names := make(map[string]bool)
+
+	for i, item := range items {
+		if item.child("", "enclosure") == nil {
+			continue // nothing to listen to
+		}
+		e := transformFromItem(item, show, len(items)-i)
+		e.Metadata.Name = uniqueName(names, e.Description.Title, len(items)-i)
+		episodes = append(episodes, e)
+	}
+
+	return show, episodes, nil
+}
+
+func transformFromChannel(channel *xmlNode, name, guid string) *podops.Show {
+	show := &podops.Show{
+		APIVersion: podops.Version,
+		Kind:       podops.ResourceShow,
+		Metadata: podops.Metadata{
+			Name:   name,
+			Labels: podops.DefaultShowMetadata(guid),
+		},
+		Description: podops.ShowDescription{
+			Title:     channel.text("", "title"),
+			Summary:   firstOf(channel.text("", "description"), channel.text(itunesNS, "summary")),
+			Link:      podops.Asset{URI: channel.text("", "link")},
+			Author:    channel.text(itunesNS, "author"),
+			Copyright: channel.text("", "copyright"),
+		},
+	}
+
+	// labels
+	labels := show.Metadata.Labels
+	if lang := channel.text("", "language"); lang != "" {
+		labels[podops.LabelLanguage] = normalizeLanguage(lang)
+	}
+	if explicit := channel.text(itunesNS, "explicit"); explicit != "" {
+		labels[podops.LabelExplicit] = trueFalse(explicit)
+	}
+	if strings.ToLower(channel.text(itunesNS, "type")) == strings.ToLower(podops.ShowTypeSerial) {
+		labels[podops.LabelType] = podops.ShowTypeSerial
+	}
+	labels[podops.LabelBlock] = yesNo(channel.text(itunesNS, "block"))
+	labels[podops.LabelComplete] = yesNo(channel.text(itunesNS, "complete"))
+	if pguid := channel.text(podcastNS, "guid"); pguid != "" {
+		labels[podops.LabelPodcastGUID] = pguid
+	}
+	if locked := channel.text(podcastNS, "locked"); locked != "" {
+		labels[podops.LabelLocked] = yesNo(locked)
+	}
+
+	// description
+	if c := channel.child(itunesNS, "category"); c != nil {
+		show.Description.Category.Name = c.attr("text")
+		for _, sub := range c.children(itunesNS, "category") {
+			show.Description.Category.SubCategory = append(show.Description.Category.SubCategory, sub.attr("text"))
+		}
+	}
+	if o := channel.child(itunesNS, "owner"); o != nil {
+		show.Description.Owner.Name = o.text(itunesNS, "name")
+		show.Description.Owner.Email = o.text(itunesNS, "email")
+	}
+	if newFeed := channel.text(itunesNS, "new-feed-url"); newFeed != "" {
+		show.Description.NewFeed = &podops.Asset{URI: newFeed, Rel: podops.ResourceTypeExternal}
+	}
+
+	// assets
+	show.Image = podops.Asset{
+		URI: channelImage(channel),
+		Rel: podops.ResourceTypeImport,
+	}
+	for _, f := range channel.children(podcastNS, "funding") {
+		show.Funding = append(show.Funding, podops.Asset{
+			URI:   f.attr("url"),
+			Title: strings.TrimSpace(f.Content),
+			Rel:   podops.ResourceTypeExternal,
+		})
+	}
+
+	return show
+}
+
+func transformFromItem(item *xmlNode, show *podops.Show, index int) *podops.Episode {
+	guid := item.text("", "guid")
+	if guid == "" {
+		guid = item.child("", "enclosure").attr("url")
+	}
+	parent := show.GUID()
+
+	// the original guid is scoped to the feed and might not be usable in routes and file names
+	episode := &podops.Episode{
+		APIVersion: podops.Version,
+		Kind:       podops.ResourceEpisode,
+		Metadata: podops.Metadata{
+			Labels: podops.DefaultEpisodeMetadata(id.Checksum(parent+guid), parent),
+		},
+		Description: podops.EpisodeDescription{
+			Title: firstOf(item.text("", "title"), item.text(itunesNS, "title")),
+			Link:  podops.Asset{URI: item.text("", "link")},
+		},
+	}
+	episode.Description.Summary = firstOf(item.text(itunesNS, "subtitle"), item.text(itunesNS, "summary"), episode.Description.Title)
+	episode.Description.EpisodeText = firstOf(item.text(contentNS, "encoded"), item.text("", "description"), episode.Description.Summary)
+	episode.Description.Duration = parseDurationString(item.text(itunesNS, "duration"))
+	if episode.Description.Duration == 0 {
+		episode.Description.Duration = 1 // must not be 0, the actual duration is calculated once the enclosure is imported
+	}
+
+	// labels
+	labels := episode.Metadata.Labels
+	labels[podops.LabelItemGUID] = guid
+	if pubDate := parsePubDate(item.text("", "pubDate")); !pubDate.IsZero() {
+		labels[podops.LabelDate] = pubDate.UTC().Format(time.RFC1123Z)
+	}
+	if season := item.text(itunesNS, "season"); season != "" {
+		labels[podops.LabelSeason] = season
+	}
+	labels[podops.LabelEpisode] = firstOf(item.text(itunesNS, "episode"), strconv.Itoa(index))
+	labels[podops.LabelExplicit] = show.Metadata.Labels[podops.LabelExplicit]
+	if explicit := item.text(itunesNS, "explicit"); explicit != "" {
+		labels[podops.LabelExplicit] = trueFalse(explicit)
+	}
+	switch strings.ToLower(item.text(itunesNS, "episodeType")) {
+	case strings.ToLower(podops.EpisodeTypeTrailer):
+		labels[podops.LabelType] = podops.EpisodeTypeTrailer
+	case strings.ToLower(podops.EpisodeTypeBonus):
+		labels[podops.LabelType] = podops.EpisodeTypeBonus
+	default:
+		labels[podops.LabelType] = podops.EpisodeTypeFull
+	}
+	labels[podops.LabelBlock] = yesNo(item.text(itunesNS, "block"))
+
+	// assets
+	episode.Image = podops.Asset{
+		URI: show.Image.URI,
+		Rel: podops.ResourceTypeImport,
+	}
+	if img := item.child(itunesNS, "image"); img != nil && img.attr("href") != "" {
+		episode.Image.URI = img.attr("href")
+	}
+
+	enclosure := item.child("", "enclosure")
+	size, _ := strconv.Atoi(enclosure.attr("length"))
+	episode.Enclosure = podops.Asset{
+		URI:  enclosure.attr("url"),
+		Type: enclosure.attr("type"),
+		Size: size,
+		Rel:  podops.ResourceTypeImport,
+	}
+
+	return episode
+}
+
+// child returns the first child element with the given namespace and name
+func (n *xmlNode) child(space, local string) *xmlNode {
+	for _, c := range n.Nodes {
+		if c.XMLName.Space == space && c.XMLName.Local == local {
+			return c
+		}
+	}
+	return nil
+}
+
+// children returns all child elements with the given namespace and name
+func (n *xmlNode) children(space, local string) []*xmlNode {
+	var nodes []*xmlNode
+	for _, c := range n.Nodes {
+		if c.XMLName.Space == space && c.XMLName.Local == local {
+			nodes = append(nodes, c)
+		}
+	}
+	return nodes
+}
+
+// text returns the trimmed content of the first child element with the given namespace and name
+func (n *xmlNode) text(space, local string) string {
+	if c := n.child(space, local); c != nil {
+		return strings.TrimSpace(c.Content)
+	}
+	return ""
+}
+
+// attr returns the value of attribute name
+func (n *xmlNode) attr(name string) string {
+	for _, a := range n.Attrs {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+func channelImage(channel *xmlNode) string {
+	if img := channel.child(itunesNS, "image"); img != nil && img.attr("href") != "" {
+		return img.attr("href")
+	}
+	if img := channel.child("", "image"); img != nil {
+		return img.text("", "url")
+	}
+	return ""
+}
+
+// uniqueName creates an episode name from its title, e.g. 'Episode 1: Hello World!' -> 'episode-1-hello-world'
+func uniqueName(names map[string]bool, title string, index int) string {
+	name := strings.Trim(nameChars.ReplaceAllString(strings.ToLower(title), "-"), "-")
+	if len(name) > maxNameLength {
+		name = strings.TrimRight(name[:maxNameLength], "-")
+	}
+	if name == "" || !podops.ValidResourceName(name) {
+		name = fmt.Sprintf("episode-%d", index)
+	}
+
+	n := name
+	for i := 2; names[n]; i++ {
+		n = fmt.Sprintf("%s-%d", name, i)
+	}
+	names[n] = true
+	return n
+}
+
+// parsePubDate tries the RFC 822 variants that are common in podcast feeds
+func parsePubDate(s string) time.Time {
+	for _, layout := range pubDateLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// parseDurationString converts 'HH:MM:SS', 'MM:SS' or seconds into seconds
+func parseDurationString(s string) int {
+	if s == "" {
+		return 0
+	}
+	d := 0
+	for _, part := range strings.Split(s, ":") {
+		n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return 0
+		}
+		d = d*60 + int(n)
+	}
+	return d
+}
+
+// normalizeLanguage converts RSS language codes like 'en-us' into 'en_US'
+func normalizeLanguage(lang string) string {
+	parts := strings.Split(strings.Replace(lang, "-", "_", -1), "_")
+	if len(parts) == 1 {
+		return strings.ToLower(parts[0])
+	}
+	return strings.ToLower(parts[0]) + "_" + strings.ToUpper(parts[1])
+}
+
+func yesNo(s string) string {
+	switch strings.ToLower(s) {
+	case "yes", "true":
+		return "yes"
+	}
+	return "no"
+}
+
+// trueFalse converts the legacy values of itunes:explicit into 'true' or 'false'
+func trueFalse(s string) string {
+	switch strings.ToLower(s) {
+	case "yes", "true", "explicit":
+		return "true"
+	}
+	return "false"
+}
+
+func firstOf(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
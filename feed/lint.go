@@ -0,0 +1,407 @@
+package feed
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/metadata"
+	"github.com/podops/podops/internal/validator"
+)
+
+const (
+	// RuleSchema covers the basic integrity checks of the show and episode resources
+	RuleSchema = "schema"
+	// RuleCategory the category and subcategories must be on Apple's list
+	RuleCategory = "apple-category"
+	// RuleExplicit the explicit flag must be 'true' or 'false'
+	RuleExplicit = "apple-explicit"
+	// RuleType the show type must be Episodic or Serial, the episode type Full, Trailer or Bonus
+	RuleType = "apple-type"
+	// RuleEpisodeNumber season and episode numbers must be positive integers
+	RuleEpisodeNumber = "apple-episode-number"
+	// RuleSerialOrder episode numbers of a Serial show must be unique and ascending within a season
+	RuleSerialOrder = "apple-serial-order"
+	// RuleDescriptionLength descriptions must not exceed Apple's length limits
+	RuleDescriptionLength = "apple-description-length"
+	// RuleOwner the owner's email is used to verify the ownership of the show
+	RuleOwner = "apple-owner"
+	// RuleEnclosureLength the enclosure length must not be 0
+	RuleEnclosureLength = "rss-enclosure-length"
+	// RuleEnclosureType the enclosure must be of a supported media type
+	RuleEnclosureType = "rss-enclosure-type"
+	// RuleGUID the guid of an episode must exist, be unique and never change
+	RuleGUID = "rss-guid"
+	// RuleLink links must be absolute http(s) URLs
+	RuleLink = "rss-link"
+
+	// MaxDescriptionLength is the maximum length of 'channel.description' and 'item.description'
+	MaxDescriptionLength = 4000
+	// MaxSubtitleLength is the length at which Apple truncates 'item.itunes.subtitle'
+	MaxSubtitleLength = 255
+)
+
+type (
+	// lintRule checks a production against one compliance rule and reports violations to v
+	lintRule struct {
+		ID    string
+		Check func(v *validator.Validator, id string, show *podops.Show, episodes []*podops.Episode)
+	}
+)
+
+var (
+	// the rules, in the order they are checked
+	lintRules = []lintRule{
+		{RuleCategory, checkCategory},
+		{RuleExplicit, checkExplicit},
+		{RuleType, checkType},
+		{RuleEpisodeNumber, checkEpisodeNumber},
+		{RuleSerialOrder, checkSerialOrder},
+		{RuleDescriptionLength, checkDescriptionLength},
+		{RuleOwner, checkOwner},
+		{RuleEnclosureLength, checkEnclosureLength},
+		{RuleEnclosureType, checkEnclosureType},
+		{RuleGUID, checkGUID},
+		{RuleLink, checkLink},
+	}
+
+	// Apple Podcasts categories and their subcategories, see https://podcasters.apple.com/support/1691-apple-podcasts-categories
+	appleCategories = map[string][]string{
+		"Arts":                    {"Books", "Design", "Fashion & Beauty", "Food", "Performing Arts", "Visual Arts"},
+		"Business":                {"Careers", "Entrepreneurship", "Investing", "Management", "Marketing", "Non-Profit"},
+		"Comedy":                  {"Comedy Interviews", "Improv", "Stand-Up"},
+		"Education":               {"Courses", "How To", "Language Learning", "Self-Improvement"},
+		"Fiction":                 {"Comedy Fiction", "Drama", "Science Fiction"},
+		"Government":              {},
+		"History":                 {},
+		"Health & Fitness":        {"Alternative Health", "Fitness", "Medicine", "Mental Health", "Nutrition", "Sexuality"},
+		"Kids & Family":           {"Education for Kids", "Parenting", "Pets & Animals", "Stories for Kids"},
+		"Leisure":                 {"Animation & Manga", "Automotive", "Aviation", "Crafts", "Games", "Hobbies", "Home & Garden", "Video Games"},
+		"Music":                   {"Music Commentary", "Music History", "Music Interviews"},
+		"News":                    {"Business News", "Daily News", "Entertainment News", "News Commentary", "Politics", "Sports News", "Tech News"},
+		"Religion & Spirituality": {"Buddhism", "Christianity", "Hinduism", "Islam", "Judaism", "Religion", "Spirituality"},
+		"Science":                 {"Astronomy", "Chemistry", "Earth Sciences", "Life Sciences", "Mathematics", "Natural Sciences", "Nature", "Physics", "Social Sciences"},
+		"Society & Culture":       {"Documentary", "Personal Journals", "Philosophy", "Places & Travel", "Relationships"},
+		"Sports":                  {"Baseball", "Basketball", "Cricket", "Fantasy Sports", "Football", "Golf", "Hockey", "Rugby", "Running", "Soccer", "Swimming", "Tennis", "Volleyball", "Wilderness", "Wrestling"},
+		"Technology":              {},
+		"True Crime":              {},
+		"TV & Film":               {"After Shows", "Film History", "Film Interviews", "Film Reviews", "TV Reviews"},
+	}
+)
+
+// Lint checks the show and its episodes against the Apple Podcasts and RSS 2.0 compliance rules.
+// Violations are reported to v, together with the rule's ID.
+func Lint(v *validator.Validator, show *podops.Show, episodes []*podops.Episode) *validator.Validator {
+	v.Validate(show)
+	for _, e := range episodes {
+		v.Validate(e)
+	}
+
+	for _, r := range lintRules {
+		r.Check(v, r.ID, show, episodes)
+	}
+	return v
+}
+
+// LintProduction checks all resources of a production, including scheduled episodes and the artwork in the CDN
+func LintProduction(ctx context.Context, production string) (*validator.Validator, error) {
+	v := validator.New(production)
+
+	if err := backend.ValidateProduction(ctx, production, v); err != nil {
+		return nil, err
+	}
+
+	s, err := backend.GetResourceContent(ctx, production)
+	if err != nil {
+		return nil, err
+	}
+	l, err := backend.ListResources(ctx, production, podops.ResourceEpisode)
+	if err != nil {
+		return nil, err
+	}
+	episodes := make([]*podops.Episode, 0, len(l))
+	for _, r := range l {
+		e, err := backend.GetResourceContent(ctx, r.GUID)
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			episodes = append(episodes, e.(*podops.Episode))
+		}
+	}
+
+	return Lint(v, s.(*podops.Show), episodes), nil
+}
+
+// LintResources checks the show-*.yaml and episode-*.yaml files in dir. The metadata of local assets is taken from the files in dir.
+func LintResources(dir string) (*validator.Validator, error) {
+	show, episodes, err := LoadResources(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New(dir)
+
+	lintArtwork(v, &show.Image, dir, "show.Image")
+	for _, e := range episodes {
+		lintArtwork(v, &e.Image, dir, fmt.Sprintf("%s.Image", e.Metadata.Name))
+		lintAsset(&e.Enclosure, dir)
+	}
+
+	return Lint(v, show, episodes), nil
+}
+
+// NewLintReport converts the issues collected by v into a report
+func NewLintReport(guid string, v *validator.Validator) *podops.LintReport {
+	report := podops.LintReport{
+		GUID:     guid,
+		Errors:   v.NErrors(),
+		Warnings: v.NWarnings(),
+		Issues:   make([]*podops.LintIssue, len(v.Issues)),
+	}
+	for i, a := range v.Issues {
+		issue := podops.LintIssue{
+			Rule:     a.Rule,
+			Severity: podops.SeverityWarning,
+			Message:  a.Txt,
+		}
+		if issue.Rule == "" {
+			issue.Rule = RuleSchema
+		}
+		if a.Type == validator.AssertionError {
+			issue.Severity = podops.SeverityError
+		}
+		report.Issues[i] = &issue
+	}
+	return &report
+}
+
+func checkCategory(v *validator.Validator, id string, show *podops.Show, episodes []*podops.Episode) {
+	c := show.Description.Category
+	subCategories, ok := appleCategories[c.Name]
+	if !ok {
+		v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Unknown category '%s'", c.Name))
+		return
+	}
+	for _, sub := range c.SubCategory {
+		if !contains(subCategories, sub) {
+			v.AssertRule(id, validator.AssertionWarning, fmt.Sprintf("Unknown subcategory '%s' of category '%s'", sub, c.Name))
+		}
+	}
+}
+
+func checkExplicit(v *validator.Validator, id string, show *podops.Show, episodes []*podops.Episode) {
+	assertExplicit := func(value, name string) {
+		switch value {
+		case "true", "false":
+			// all good
+		case "yes", "no", "clean":
+			v.AssertRule(id, validator.AssertionWarning, fmt.Sprintf("Deprecated value '%s' for '%s.explicit', use 'true' or 'false'", value, name))
+		default:
+			v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Expected 'true' or 'false' for '%s.explicit', found '%s'", name, value))
+		}
+	}
+
+	assertExplicit(show.Metadata.Labels[podops.LabelExplicit], show.Metadata.Name)
+	for _, e := range episodes {
+		assertExplicit(e.Metadata.Labels[podops.LabelExplicit], e.Metadata.Name)
+	}
+}
+
+func checkType(v *validator.Validator, id string, show *podops.Show, episodes []*podops.Episode) {
+	if t := show.Metadata.Labels[podops.LabelType]; t != podops.ShowTypeEpisodic && t != podops.ShowTypeSerial {
+		v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Expected '%s' or '%s' for '%s.type', found '%s'", podops.ShowTypeEpisodic, podops.ShowTypeSerial, show.Metadata.Name, t))
+	}
+	for _, e := range episodes {
+		switch t := e.Metadata.Labels[podops.LabelType]; t {
+		case podops.EpisodeTypeFull, podops.EpisodeTypeTrailer, podops.EpisodeTypeBonus:
+			// all good
+		default:
+			v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Expected '%s', '%s' or '%s' for '%s.type', found '%s'", podops.EpisodeTypeFull, podops.EpisodeTypeTrailer, podops.EpisodeTypeBonus, e.Metadata.Name, t))
+		}
+	}
+}
+
+func checkEpisodeNumber(v *validator.Validator, id string, show *podops.Show, episodes []*podops.Episode) {
+	for _, e := range episodes {
+		for _, label := range []string{podops.LabelSeason, podops.LabelEpisode} {
+			value, ok := e.Metadata.Labels[label]
+			if !ok {
+				continue // reported by the schema validation
+			}
+			if n, err := strconv.Atoi(value); err != nil || n < 1 {
+				v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Expected a positive number for '%s.%s', found '%s'", e.Metadata.Name, label, value))
+			}
+		}
+	}
+}
+
+func checkSerialOrder(v *validator.Validator, id string, show *podops.Show, episodes []*podops.Episode) {
+	if show.Metadata.Labels[podops.LabelType] != podops.ShowTypeSerial {
+		return
+	}
+
+	// only full episodes are numbered, in the order they are published
+	full := make([]*podops.Episode, 0, len(episodes))
+	for _, e := range episodes {
+		if e.Metadata.Labels[podops.LabelType] == podops.EpisodeTypeFull {
+			full = append(full, e)
+		}
+	}
+	sort.SliceStable(full, func(i, j int) bool {
+		return full[i].PublishDateTimestamp() < full[j].PublishDateTimestamp()
+	})
+
+	last := make(map[string]int)     // season -> latest episode number
+	numbers := make(map[string]bool) // season/episode -> exists
+	for _, e := range full {
+		season := e.Metadata.Labels[podops.LabelSeason]
+		n, err := strconv.Atoi(e.Metadata.Labels[podops.LabelEpisode])
+		if err != nil {
+			continue // reported by checkEpisodeNumber
+		}
+
+		key := fmt.Sprintf("%s/%d", season, n)
+		if numbers[key] {
+			v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Duplicate episode number %d in season %s, found in '%s'", n, season, e.Metadata.Name))
+			continue
+		}
+		numbers[key] = true
+
+		if n < last[season] {
+			v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Expected episode numbers in ascending order of the publish date, '%s' is episode %d but follows episode %d of season %s", e.Metadata.Name, n, last[season], season))
+		}
+		if n > last[season] {
+			last[season] = n
+		}
+	}
+}
+
+func checkDescriptionLength(v *validator.Validator, id string, show *podops.Show, episodes []*podops.Episode) {
+	if l := utf8.RuneCountInString(show.Description.Summary); l > MaxDescriptionLength {
+		v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Expected at most %d characters in '%s.summary', found %d", MaxDescriptionLength, show.Metadata.Name, l))
+	}
+	for _, e := range episodes {
+		if l := utf8.RuneCountInString(e.Description.Summary); l > MaxDescriptionLength {
+			v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Expected at most %d characters in '%s.summary', found %d", MaxDescriptionLength, e.Metadata.Name, l))
+		} else if l > MaxSubtitleLength {
+			v.AssertRule(id, validator.AssertionWarning, fmt.Sprintf("The summary of '%s' will be truncated to %d characters in Apple Podcasts, found %d", e.Metadata.Name, MaxSubtitleLength, l))
+		}
+		if l := utf8.RuneCountInString(e.Description.EpisodeText); l > MaxDescriptionLength {
+			v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Expected at most %d characters in '%s.episodeText', found %d", MaxDescriptionLength, e.Metadata.Name, l))
+		}
+	}
+}
+
+func checkOwner(v *validator.Validator, id string, show *podops.Show, episodes []*podops.Episode) {
+	if email := show.Description.Owner.Email; email != "" && !podops.ValidEmail(email) {
+		v.AssertRule(id, validator.AssertionWarning, fmt.Sprintf("Invalid owner email '%s'", email))
+	}
+}
+
+func checkEnclosureLength(v *validator.Validator, id string, show *podops.Show, episodes []*podops.Episode) {
+	for _, e := range episodes {
+		if e.Enclosure.Size <= 0 {
+			v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Expected a non-zero length of the enclosure of '%s'", e.Metadata.Name))
+		}
+	}
+}
+
+func checkEnclosureType(v *validator.Validator, id string, show *podops.Show, episodes []*podops.Episode) {
+	for _, e := range episodes {
+		if _, ok := mediaTypeMap[e.Enclosure.Type]; !ok {
+			v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Unsupported media type '%s' of the enclosure of '%s'", e.Enclosure.Type, e.Metadata.Name))
+		}
+	}
+}
+
+func checkGUID(v *validator.Validator, id string, show *podops.Show, episodes []*podops.Episode) {
+	guids := make(map[string]string) // guid -> episode name
+
+	for _, e := range episodes {
+		// the guid that ends up in the feed
+		guid := e.Metadata.Labels[podops.LabelGUID]
+		if itemGUID := e.Metadata.Labels[podops.LabelItemGUID]; itemGUID != "" {
+			guid = itemGUID
+		}
+		if guid == "" {
+			continue // reported by the schema validation
+		}
+
+		if other, ok := guids[guid]; ok {
+			v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Duplicate guid '%s' in '%s' and '%s'", guid, other, e.Metadata.Name))
+			continue
+		}
+		guids[guid] = e.Metadata.Name
+
+		if e.Metadata.Labels[podops.LabelParentGUID] != show.GUID() {
+			v.AssertRule(id, validator.AssertionError, fmt.Sprintf("Episode '%s' does not belong to show '%s'", e.Metadata.Name, show.GUID()))
+		}
+		if guid == e.Enclosure.URI {
+			// podcast apps use the guid to tell episodes apart, it must not change when the media file moves
+			v.AssertRule(id, validator.AssertionWarning, fmt.Sprintf("The guid of '%s' is the location of its enclosure", e.Metadata.Name))
+		}
+	}
+}
+
+func checkLink(v *validator.Validator, id string, show *podops.Show, episodes []*podops.Episode) {
+	assertLink := func(link, name string) {
+		if link == "" {
+			return // optional
+		}
+		if u, err := url.Parse(link); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.AssertRule(id, validator.AssertionWarning, fmt.Sprintf("Expected an absolute URL for '%s.link', found '%s'", name, link))
+		}
+	}
+
+	assertLink(show.Description.Link.URI, show.Metadata.Name)
+	for _, e := range episodes {
+		assertLink(e.Description.Link.URI, e.Metadata.Name)
+	}
+}
+
+// lintArtwork verifies local cover art based on the image file in dir
+func lintArtwork(v *validator.Validator, a *podops.Asset, dir, name string) {
+	if m := lintAsset(a, dir); m != nil {
+		v.AssertArtwork(m.Width, m.Height, m.Format, m.ColorModel, name)
+	}
+}
+
+// lintAsset fills in the size and type of a local asset from the file in dir and returns its metadata, if available
+func lintAsset(a *podops.Asset, dir string) *metadata.Metadata {
+	if a.Rel != podops.ResourceTypeLocal {
+		return nil // only the CDN knows about imported and external assets
+	}
+	path := filepath.Join(dir, filepath.FromSlash(a.URI))
+	if _, err := os.Stat(path); err != nil {
+		return nil
+	}
+	m, err := metadata.ProbeMetadataFromFile(path)
+	if err != nil {
+		return nil
+	}
+	if a.Size == 0 {
+		a.Size = int(m.Size)
+	}
+	if a.Type == "" {
+		a.Type = m.ContentType
+	}
+	return m
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if strings.EqualFold(l, s) {
+			return true
+		}
+	}
+	return false
+}
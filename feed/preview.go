@@ -0,0 +1,142 @@
+package feed
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/feed/rss"
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/loader"
+	"github.com/podops/podops/internal/messagedef"
+	"github.com/podops/podops/internal/metadata"
+	"github.com/podops/podops/internal/validator"
+)
+
+// LoadResources reads the show and all episodes from the show-*.yaml and episode-*.yaml files in dir
+func LoadResources(dir string) (*podops.Show, []*podops.Episode, error) {
+	shows, err := filepath.Glob(filepath.Join(dir, "show-*.yaml"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(shows) != 1 {
+		return nil, nil, errordef.ErrNoLocalShow
+	}
+
+	r, err := loadResource(shows[0], podops.ResourceShow)
+	if err != nil {
+		return nil, nil, err
+	}
+	show := r.(*podops.Show)
+
+	files, err := filepath.Glob(filepath.Join(dir, "episode-*.yaml"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	episodes := make([]*podops.Episode, 0)
+	for _, f := range files {
+		r, err := loadResource(f, podops.ResourceEpisode)
+		if err != nil {
+			return nil, nil, err
+		}
+		episodes = append(episodes, r.(*podops.Episode))
+	}
+
+	return show, episodes, nil
+}
+
+// BuildPreview builds the feed from the resources in dir without accessing the API or the CDN.
+//
+// Local assets are resolved relative to baseURL, episodes with a publish date in the future are omitted.
+func BuildPreview(dir, baseURL string) (*rss.Channel, error) {
+	show, episodes, err := LoadResources(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	v := validator.New(dir)
+	v.Validate(show)
+	for _, e := range episodes {
+		v.Validate(e)
+	}
+	if !v.IsValid() {
+		return nil, v.AsError()
+	}
+
+	// list all episodes, excluding future (i.e. unpublished) ones, descending order
+	now := timestamp.Now()
+	published := make([]*podops.Episode, 0)
+	for _, e := range episodes {
+		if p := e.PublishDateTimestamp(); p > 0 && p < now {
+			published = append(published, e)
+		}
+	}
+	sort.SliceStable(published, func(i, j int) bool {
+		return published[i].PublishDateTimestamp() > published[j].PublishDateTimestamp()
+	})
+
+	// rewrite the local assets
+	previewAsset(&show.Image, dir, baseURL)
+	for _, e := range published {
+		previewAsset(&e.Image, dir, baseURL)
+		if m := previewAsset(&e.Enclosure, dir, baseURL); m != nil && m.Duration > 0 {
+			e.Description.Duration = int(m.Duration) // like the build, the media file has the final say
+		}
+		for i := range e.Transcripts {
+			previewAsset(&e.Transcripts[i], dir, baseURL)
+		}
+		if e.Chapters != nil {
+			previewAsset(e.Chapters, dir, baseURL)
+		}
+	}
+
+	return TransformToFeed(show, published)
+}
+
+// previewAsset points a local asset to the preview server, fills in its size and type from the file, if missing,
+// and returns the metadata of the file, if available. Only the headers are probed, the preview is rebuilt often.
+func previewAsset(a *podops.Asset, dir, baseURL string) *metadata.Metadata {
+	if a.Rel != podops.ResourceTypeLocal {
+		return nil // imported and external assets are served from their origin
+	}
+
+	path := filepath.Join(dir, filepath.FromSlash(a.URI))
+	a.URI = fmt.Sprintf("%s/%s", baseURL, a.URI)
+
+	if _, err := os.Stat(path); err != nil {
+		return nil // let the podcast app complain about the missing asset
+	}
+	meta, err := metadata.ProbeMetadataFromFile(path)
+	if err != nil {
+		return nil
+	}
+	if a.Size == 0 {
+		a.Size = int(meta.Size)
+	}
+	if a.Type == "" {
+		a.Type = meta.ContentType
+	}
+	return meta
+}
+
+func loadResource(path, kind string) (interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, k, _, err := loader.UnmarshalResource(data)
+	if err != nil {
+		return nil, err
+	}
+	if k != kind {
+		return nil, fmt.Errorf(messagedef.MsgResourceKindMismatch, kind, k)
+	}
+	return r, nil
+}
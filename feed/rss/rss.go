@@ -23,8 +23,13 @@ const (
 	MOV
 	PDF
 	EPUB
+	OGG
+	FLAC
 
 	enclosureDefault = "application/octet-stream"
+
+	// ChaptersJSON is the MIME type of Podcasting 2.0 JSON chapters
+	ChaptersJSON = "application/json+chapters"
 )
 
 // New instantiates a podcast with required parameters.
@@ -63,11 +68,25 @@ func (p *Channel) AddAtomLink(href string) {
 	if len(href) == 0 {
 		return
 	}
-	p.AtomLink = &AtomLink{
+	p.AtomLinks = append(p.AtomLinks, &AtomLink{
 		HREF: href,
 		Rel:  "self",
 		Type: "application/rss+xml",
+	})
+}
+
+// AddHubLink declares a WebSub hub that notifies subscribers when the feed changes.
+//
+// The hub link can be listed multiple times. WebSub subscribers also expect a
+// rel="self" link, see AddAtomLink.
+func (p *Channel) AddHubLink(href string) {
+	if len(href) == 0 {
+		return
 	}
+	p.AtomLinks = append(p.AtomLinks, &AtomLink{
+		HREF: href,
+		Rel:  "hub",
+	})
 }
 
 // AddCategory adds the category to the podcast.
@@ -294,18 +313,69 @@ func (p *Channel) Encode(w io.Writer) error {
 	}
 
 	atomLink := ""
-	if p.AtomLink != nil {
+	if len(p.AtomLinks) > 0 {
 		atomLink = "http://www.w3.org/2005/Atom"
 	}
+	podcastNS := ""
+	if p.usesPodcastNamespace() {
+		podcastNS = "https://podcastindex.org/namespace/1.0"
+	}
 	wrapped := channelWrapper{
-		ITUNESNS: "http://www.itunes.com/dtds/podcast-1.0.dtd",
-		ATOMNS:   atomLink,
-		Version:  "2.0",
-		Channel:  p,
+		ITUNESNS:  "http://www.itunes.com/dtds/podcast-1.0.dtd",
+		ATOMNS:    atomLink,
+		PODCASTNS: podcastNS,
+		Version:   "2.0",
+		Channel:   p,
 	}
 	return p.encode(w, wrapped)
 }
 
+// AddLocked tells other podcast platforms whether they are allowed to import the feed.
+func (p *Channel) AddLocked(locked bool, owner string) {
+	value := "no"
+	if locked {
+		value = "yes"
+	}
+	p.PLocked = &PLocked{
+		Owner: owner,
+		Value: value,
+	}
+}
+
+// AddFunding adds a donation/funding link to the podcast.
+//
+// Limit: 128 characters
+func (p *Channel) AddFunding(url, text string) {
+	if len(url) == 0 {
+		return
+	}
+	p.PFunding = append(p.PFunding, &PFunding{
+		URL:  url,
+		Text: truncate(text, 128),
+	})
+}
+
+// AddPerson adds a person of interest to the podcast.
+func (p *Channel) AddPerson(person *PPerson) {
+	if person == nil || len(person.Name) == 0 {
+		return
+	}
+	p.PPersons = append(p.PPersons, person)
+}
+
+// usesPodcastNamespace returns true if any podcast:* element is set on the channel or its items
+func (p *Channel) usesPodcastNamespace() bool {
+	if len(p.PGUID) > 0 || p.PLocked != nil || len(p.PFunding) > 0 || len(p.PPersons) > 0 || p.PLocation != nil || p.PValue != nil {
+		return true
+	}
+	for _, i := range p.Items {
+		if len(i.PTranscripts) > 0 || i.PChapters != nil || len(i.PPersons) > 0 || i.PLocation != nil || len(i.PSoundbites) > 0 || i.PValue != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // String encodes the Podcast state to a string.
 func (p *Channel) String() string {
 	b := new(bytes.Buffer)
@@ -373,6 +443,53 @@ func (i *Item) AddDuration(durationInSeconds int64) {
 	i.IDuration = parseDuration(durationInSeconds)
 }
 
+// AddTranscript adds a link to a transcript or closed captions file.
+func (i *Item) AddTranscript(url, mimeType, language string) {
+	if len(url) == 0 || len(mimeType) == 0 {
+		return
+	}
+	t := PTranscript{
+		URL:      url,
+		Type:     mimeType,
+		Language: language,
+	}
+	if mimeType == podops.TranscriptTypeSRT || mimeType == podops.TranscriptTypeVTT {
+		t.Rel = "captions"
+	}
+	i.PTranscripts = append(i.PTranscripts, &t)
+}
+
+// AddChapters adds a link to the JSON chapters file of the episode.
+func (i *Item) AddChapters(url string) {
+	if len(url) == 0 {
+		return
+	}
+	i.PChapters = &PChapters{
+		URL:  url,
+		Type: ChaptersJSON,
+	}
+}
+
+// AddPerson adds a person of interest to the episode, e.g. a guest.
+func (i *Item) AddPerson(person *PPerson) {
+	if person == nil || len(person.Name) == 0 {
+		return
+	}
+	i.PPersons = append(i.PPersons, person)
+}
+
+// AddSoundbite adds a soundbite, start and duration are in seconds.
+func (i *Item) AddSoundbite(start, duration float64, title string) {
+	if duration <= 0 {
+		return
+	}
+	i.PSoundbites = append(i.PSoundbites, &PSoundbite{
+		StartTime: strconv.FormatFloat(start, 'f', 1, 64),
+		Duration:  strconv.FormatFloat(duration, 'f', 1, 64),
+		Title:     title,
+	})
+}
+
 // String returns the MIME type encoding of the specified EnclosureType.
 func (et EnclosureType) String() string {
 	// https://help.apple.com/itc/podcasts_connect/#/itcb54353390
@@ -391,6 +508,10 @@ func (et EnclosureType) String() string {
 		return "application/pdf"
 	case EPUB:
 		return "document/x-epub"
+	case OGG:
+		return "audio/ogg"
+	case FLAC:
+		return "audio/flac"
 	}
 	return enclosureDefault
 }
@@ -29,7 +29,7 @@ type (
 		WebMaster      string   `xml:"webMaster,omitempty"`
 		Image          *Image
 		TextInput      *TextInput
-		AtomLink       *AtomLink
+		AtomLinks      []*AtomLink
 
 		// https://help.apple.com/itc/podcasts_connect/#/itcb54353390
 		IAuthor     string `xml:"itunes:author,omitempty"`
@@ -47,17 +47,26 @@ type (
 		ITitle string `xml:"itunes:title,omitempty"`
 		IType  string `xml:"itunes:type,omitempty"`
 
+		// https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md
+		PGUID     string `xml:"podcast:guid,omitempty"`
+		PLocked   *PLocked
+		PFunding  []*PFunding
+		PPersons  []*PPerson
+		PLocation *PLocation
+		PValue    *PValue
+
 		Items []*Item
 
 		encode func(w io.Writer, o interface{}) error
 	}
 
 	channelWrapper struct {
-		XMLName  xml.Name `xml:"rss"`
-		Version  string   `xml:"version,attr"`
-		ATOMNS   string   `xml:"xmlns:atom,attr,omitempty"`
-		ITUNESNS string   `xml:"xmlns:itunes,attr"`
-		Channel  *Channel
+		XMLName   xml.Name `xml:"rss"`
+		Version   string   `xml:"version,attr"`
+		ATOMNS    string   `xml:"xmlns:atom,attr,omitempty"`
+		ITUNESNS  string   `xml:"xmlns:itunes,attr"`
+		PODCASTNS string   `xml:"xmlns:podcast,attr,omitempty"`
+		Channel   *Channel
 	}
 
 	// Item represents a single entry in a podcast.
@@ -106,6 +115,14 @@ type (
 		IEpisodeType string `xml:"itunes:episodeType,omitempty"`
 		IBlock       string `xml:"itunes:block,omitempty"`
 
+		// https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md
+		PTranscripts []*PTranscript
+		PChapters    *PChapters
+		PPersons     []*PPerson
+		PLocation    *PLocation
+		PSoundbites  []*PSoundbite
+		PValue       *PValue
+
 		// REMOVE IIsClosedCaptioned string `xml:"itunes:isClosedCaptioned,omitempty"`
 		// REMOVE IOrder string `xml:"itunes:order,omitempty"`
 	}
@@ -115,7 +132,7 @@ type (
 		XMLName xml.Name `xml:"atom:link"`
 		HREF    string   `xml:"href,attr"`
 		Rel     string   `xml:"rel,attr"`
-		Type    string   `xml:"type,attr"`
+		Type    string   `xml:"type,attr,omitempty"`
 	}
 
 	// Image represents an image.
@@ -179,6 +196,85 @@ type (
 		Text    string   `xml:",cdata"`
 	}
 
+	// PLocked tells other podcast platforms whether they are allowed to import this feed.
+	//
+	// The owner is an email address that can be used to verify ownership when moving hosting platforms.
+	PLocked struct {
+		XMLName xml.Name `xml:"podcast:locked"`
+		Owner   string   `xml:"owner,attr,omitempty"`
+		Value   string   `xml:",chardata"` // yes | no
+	}
+
+	// PFunding lists a donation/funding link for the podcast.
+	PFunding struct {
+		XMLName xml.Name `xml:"podcast:funding"`
+		URL     string   `xml:"url,attr"`
+		Text    string   `xml:",chardata"` // max 128 characters
+	}
+
+	// PTranscript links to an external file containing a transcript or closed captions.
+	PTranscript struct {
+		XMLName  xml.Name `xml:"podcast:transcript"`
+		URL      string   `xml:"url,attr"`
+		Type     string   `xml:"type,attr"`
+		Language string   `xml:"language,attr,omitempty"`
+		Rel      string   `xml:"rel,attr,omitempty"` // captions
+	}
+
+	// PChapters links to an external file containing chapter data for the episode.
+	PChapters struct {
+		XMLName xml.Name `xml:"podcast:chapters"`
+		URL     string   `xml:"url,attr"`
+		Type    string   `xml:"type,attr"` // application/json+chapters
+	}
+
+	// PPerson specifies a person of interest to the podcast or episode, e.g. host or guest.
+	PPerson struct {
+		XMLName xml.Name `xml:"podcast:person"`
+		Role    string   `xml:"role,attr,omitempty"`
+		Group   string   `xml:"group,attr,omitempty"`
+		Img     string   `xml:"img,attr,omitempty"`
+		Href    string   `xml:"href,attr,omitempty"`
+		Name    string   `xml:",chardata"`
+	}
+
+	// PLocation describes the location of editorial focus for the podcast or episode.
+	PLocation struct {
+		XMLName xml.Name `xml:"podcast:location"`
+		Geo     string   `xml:"geo,attr,omitempty"`
+		OSM     string   `xml:"osm,attr,omitempty"`
+		Name    string   `xml:",chardata"` // max 128 characters
+	}
+
+	// PSoundbite points to a soundbite within the episode, e.g. to be used as a preview.
+	PSoundbite struct {
+		XMLName   xml.Name `xml:"podcast:soundbite"`
+		StartTime string   `xml:"startTime,attr"`
+		Duration  string   `xml:"duration,attr"`
+		Title     string   `xml:",chardata"`
+	}
+
+	// PValue designates a cryptocurrency or payment layer used to transact value to the podcaster.
+	PValue struct {
+		XMLName    xml.Name `xml:"podcast:value"`
+		Type       string   `xml:"type,attr"`
+		Method     string   `xml:"method,attr"`
+		Suggested  string   `xml:"suggested,attr,omitempty"`
+		Recipients []*PValueRecipient
+	}
+
+	// PValueRecipient designates a destination for the payments of a PValue.
+	PValueRecipient struct {
+		XMLName     xml.Name `xml:"podcast:valueRecipient"`
+		Name        string   `xml:"name,attr,omitempty"`
+		CustomKey   string   `xml:"customKey,attr,omitempty"`
+		CustomValue string   `xml:"customValue,attr,omitempty"`
+		Type        string   `xml:"type,attr"`
+		Address     string   `xml:"address,attr"`
+		Split       int      `xml:"split,attr"`
+		Fee         bool     `xml:"fee,attr,omitempty"`
+	}
+
 	// EnclosureType specifies the type of the enclosure.
 	EnclosureType int
 
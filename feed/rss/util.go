@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"time"
+	"unicode/utf8"
 
 	"github.com/podops/podops/internal/errordef"
 )
@@ -63,3 +64,10 @@ var parseAuthorNameEmail = func(a *Author) string {
 	}
 	return author
 }
+
+var truncate = func(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	return string([]rune(s)[0:max])
+}
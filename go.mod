@@ -8,14 +8,18 @@ require (
 	github.com/99designs/gqlgen v0.13.0
 	github.com/OrlovEvgeny/go-mcache v0.0.0-20200121124330-1a8195b34f3a
 	github.com/caddyserver/caddy/v2 v2.3.0
+	github.com/hashicorp/golang-lru v0.5.1
 	github.com/johngb/langreg v0.0.0-20150123211413-5c6abc6d19d2
 	github.com/labstack/echo/v4 v4.2.0
 	github.com/mailgun/mailgun-go/v4 v4.5.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/stretchr/testify v1.6.1
 	github.com/tcolgate/mp3 v0.0.0-20170426193717-e79c5a46d300
 	github.com/txsvc/platform/v2 v2.6.2
 	github.com/urfave/cli/v2 v2.3.0
 	github.com/vektah/gqlparser/v2 v2.2.0
+	go.etcd.io/bbolt v1.3.3
+	google.golang.org/api v0.43.0
 	gopkg.in/yaml.v2 v2.3.0
 	gopkg.in/yaml.v3 v3.0.0-20210107192922-496545a6307b
 )
@@ -43,10 +43,11 @@ type DirectiveRoot struct {
 
 type ComplexityRoot struct {
 	Query struct {
-		Episode func(childComplexity int, guid *string) int
-		Popular func(childComplexity int, limit int) int
-		Recent  func(childComplexity int, limit int) int
-		Show    func(childComplexity int, name *string, limit int) int
+		Downloads func(childComplexity int, guid string, days int) int
+		Episode   func(childComplexity int, guid *string) int
+		Popular   func(childComplexity int, limit int) int
+		Recent    func(childComplexity int, limit int) int
+		Show      func(childComplexity int, name *string, limit int) int
 	}
 
 	Category struct {
@@ -54,6 +55,11 @@ type ComplexityRoot struct {
 		Subcategory func(childComplexity int) int
 	}
 
+	DailyDownloads struct {
+		Day       func(childComplexity int) int
+		Downloads func(childComplexity int) int
+	}
+
 	Enclosure struct {
 		Link func(childComplexity int) int
 		Size func(childComplexity int) int
@@ -61,8 +67,10 @@ type ComplexityRoot struct {
 	}
 
 	Episode struct {
+		Chapters    func(childComplexity int) int
 		Created     func(childComplexity int) int
 		Description func(childComplexity int) int
+		Downloads   func(childComplexity int) int
 		Enclosure   func(childComplexity int) int
 		GUID        func(childComplexity int) int
 		Image       func(childComplexity int) int
@@ -70,6 +78,7 @@ type ComplexityRoot struct {
 		Name        func(childComplexity int) int
 		Production  func(childComplexity int) int
 		Published   func(childComplexity int) int
+		Transcripts func(childComplexity int) int
 	}
 
 	EpisodeDescription struct {
@@ -105,6 +114,7 @@ type ComplexityRoot struct {
 		Build       func(childComplexity int) int
 		Created     func(childComplexity int) int
 		Description func(childComplexity int) int
+		Downloads   func(childComplexity int) int
 		Episodes    func(childComplexity int) int
 		GUID        func(childComplexity int) int
 		Image       func(childComplexity int) int
@@ -121,6 +131,11 @@ type ComplexityRoot struct {
 		Summary   func(childComplexity int) int
 		Title     func(childComplexity int) int
 	}
+
+	Transcript struct {
+		Link func(childComplexity int) int
+		Type func(childComplexity int) int
+	}
 }
 
 type QueryResolver interface {
@@ -128,6 +143,7 @@ type QueryResolver interface {
 	Episode(ctx context.Context, guid *string) (*model.Episode, error)
 	Recent(ctx context.Context, limit int) ([]*model.Show, error)
 	Popular(ctx context.Context, limit int) ([]*model.Show, error)
+	Downloads(ctx context.Context, guid string, days int) ([]*model.DailyDownloads, error)
 }
 
 type executableSchema struct {
@@ -145,6 +161,18 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 	_ = ec
 	switch typeName + "." + field {
 
+	case "Query.downloads":
+		if e.complexity.Query.Downloads == nil {
+			break
+		}
+
+		args, err := ec.field_Query_downloads_args(context.TODO(), rawArgs)
+		if err != nil {
+			return 0, false
+		}
+
+		return e.complexity.Query.Downloads(childComplexity, args["guid"].(string), args["days"].(int)), true
+
 	case "Query.episode":
 		if e.complexity.Query.Episode == nil {
 			break
@@ -207,6 +235,20 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Category.Subcategory(childComplexity), true
 
+	case "dailyDownloads.day":
+		if e.complexity.DailyDownloads.Day == nil {
+			break
+		}
+
+		return e.complexity.DailyDownloads.Day(childComplexity), true
+
+	case "dailyDownloads.downloads":
+		if e.complexity.DailyDownloads.Downloads == nil {
+			break
+		}
+
+		return e.complexity.DailyDownloads.Downloads(childComplexity), true
+
 	case "enclosure.link":
 		if e.complexity.Enclosure.Link == nil {
 			break
@@ -228,6 +270,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Enclosure.Type(childComplexity), true
 
+	case "episode.chapters":
+		if e.complexity.Episode.Chapters == nil {
+			break
+		}
+
+		return e.complexity.Episode.Chapters(childComplexity), true
+
 	case "episode.created":
 		if e.complexity.Episode.Created == nil {
 			break
@@ -242,6 +291,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Episode.Description(childComplexity), true
 
+	case "episode.downloads":
+		if e.complexity.Episode.Downloads == nil {
+			break
+		}
+
+		return e.complexity.Episode.Downloads(childComplexity), true
+
 	case "episode.enclosure":
 		if e.complexity.Episode.Enclosure == nil {
 			break
@@ -291,6 +347,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Episode.Published(childComplexity), true
 
+	case "episode.transcripts":
+		if e.complexity.Episode.Transcripts == nil {
+			break
+		}
+
+		return e.complexity.Episode.Transcripts(childComplexity), true
+
 	case "episodeDescription.description":
 		if e.complexity.EpisodeDescription.Description == nil {
 			break
@@ -431,6 +494,13 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.Show.Description(childComplexity), true
 
+	case "show.downloads":
+		if e.complexity.Show.Downloads == nil {
+			break
+		}
+
+		return e.complexity.Show.Downloads(childComplexity), true
+
 	case "show.episodes":
 		if e.complexity.Show.Episodes == nil {
 			break
@@ -515,6 +585,20 @@ func (e *executableSchema) Complexity(typeName, field string, childComplexity in
 
 		return e.complexity.ShowDescription.Title(childComplexity), true
 
+	case "transcript.link":
+		if e.complexity.Transcript.Link == nil {
+			break
+		}
+
+		return e.complexity.Transcript.Link(childComplexity), true
+
+	case "transcript.type":
+		if e.complexity.Transcript.Type == nil {
+			break
+		}
+
+		return e.complexity.Transcript.Type(childComplexity), true
+
 	}
 	return 0, false
 }
@@ -573,6 +657,7 @@ var sources = []*ast.Source{
     labels: labels!
     description: showDescription!
     image: String!
+    downloads: Int!
     episodes: [episode!]!
 }
 
@@ -591,6 +676,9 @@ type episode {
     description: episodeDescription!
     image: String!
     enclosure: enclosure!
+    transcripts: [transcript!]!
+    chapters: String
+    downloads: Int!
     production: production!
 }
 
@@ -618,6 +706,11 @@ type enclosure {
     size: Int!
 }
 
+type transcript {
+    link: String!,
+    type: String!
+}
+
 type owner {
     name: String!
     email: String!
@@ -628,6 +721,11 @@ type category {
     subcategory: String
 }
 
+type dailyDownloads {
+    day: String!
+    downloads: Int!
+}
+
 type labels {
     block: String!
     explicit: String!
@@ -644,6 +742,8 @@ type Query {
 
     recent(limit: Int!) : [show]!
     popular(limit: Int!) : [show]!
+
+    downloads(guid: ID!, days: Int!) : [dailyDownloads!]!
 }
 
 scalar Timestamp
@@ -670,6 +770,30 @@ func (ec *executionContext) field_Query___type_args(ctx context.Context, rawArgs
 	return args, nil
 }
 
+func (ec *executionContext) field_Query_downloads_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
+	var err error
+	args := map[string]interface{}{}
+	var arg0 string
+	if tmp, ok := rawArgs["guid"]; ok {
+		ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("guid"))
+		arg0, err = ec.unmarshalNID2string(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["guid"] = arg0
+	var arg1 int
+	if tmp, ok := rawArgs["days"]; ok {
+		ctx := graphql.WithPathContext(ctx, graphql.NewPathWithField("days"))
+		arg1, err = ec.unmarshalNInt2int(ctx, tmp)
+		if err != nil {
+			return nil, err
+		}
+	}
+	args["days"] = arg1
+	return args, nil
+}
+
 func (ec *executionContext) field_Query_episode_args(ctx context.Context, rawArgs map[string]interface{}) (map[string]interface{}, error) {
 	var err error
 	args := map[string]interface{}{}
@@ -939,6 +1063,48 @@ func (ec *executionContext) _Query_popular(ctx context.Context, field graphql.Co
 	return ec.marshalNshow2ᚕᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐShow(ctx, field.Selections, res)
 }
 
+func (ec *executionContext) _Query_downloads(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:     "Query",
+		Field:      field,
+		Args:       nil,
+		IsMethod:   true,
+		IsResolver: true,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	rawArgs := field.ArgumentMap(ec.Variables)
+	args, err := ec.field_Query_downloads_args(ctx, rawArgs)
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	fc.Args = args
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return ec.resolvers.Query().Downloads(rctx, args["guid"].(string), args["days"].(int))
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.([]*model.DailyDownloads)
+	fc.Result = res
+	return ec.marshalNdailyDownloads2ᚕᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐDailyDownloadsᚄ(ctx, field.Selections, res)
+}
+
 func (ec *executionContext) _Query___type(ctx context.Context, field graphql.CollectedField) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -2160,6 +2326,76 @@ func (ec *executionContext) _category_subcategory(ctx context.Context, field gra
 	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
+func (ec *executionContext) _dailyDownloads_day(ctx context.Context, field graphql.CollectedField, obj *model.DailyDownloads) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:     "dailyDownloads",
+		Field:      field,
+		Args:       nil,
+		IsMethod:   false,
+		IsResolver: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Day, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _dailyDownloads_downloads(ctx context.Context, field graphql.CollectedField, obj *model.DailyDownloads) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:     "dailyDownloads",
+		Field:      field,
+		Args:       nil,
+		IsMethod:   false,
+		IsResolver: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Downloads, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
 func (ec *executionContext) _enclosure_link(ctx context.Context, field graphql.CollectedField, obj *model.Enclosure) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -2545,7 +2781,7 @@ func (ec *executionContext) _episode_enclosure(ctx context.Context, field graphq
 	return ec.marshalNenclosure2ᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐEnclosure(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _episode_production(ctx context.Context, field graphql.CollectedField, obj *model.Episode) (ret graphql.Marshaler) {
+func (ec *executionContext) _episode_transcripts(ctx context.Context, field graphql.CollectedField, obj *model.Episode) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2563,7 +2799,7 @@ func (ec *executionContext) _episode_production(ctx context.Context, field graph
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Production, nil
+		return obj.Transcripts, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2575,12 +2811,12 @@ func (ec *executionContext) _episode_production(ctx context.Context, field graph
 		}
 		return graphql.Null
 	}
-	res := resTmp.(*model.Production)
+	res := resTmp.([]*model.Transcript)
 	fc.Result = res
-	return ec.marshalNproduction2ᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐProduction(ctx, field.Selections, res)
+	return ec.marshalNtranscript2ᚕᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐTranscriptᚄ(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _episodeDescription_title(ctx context.Context, field graphql.CollectedField, obj *model.EpisodeDescription) (ret graphql.Marshaler) {
+func (ec *executionContext) _episode_chapters(ctx context.Context, field graphql.CollectedField, obj *model.Episode) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2588,7 +2824,7 @@ func (ec *executionContext) _episodeDescription_title(ctx context.Context, field
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:     "episodeDescription",
+		Object:     "episode",
 		Field:      field,
 		Args:       nil,
 		IsMethod:   false,
@@ -2598,24 +2834,21 @@ func (ec *executionContext) _episodeDescription_title(ctx context.Context, field
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Title, nil
+		return obj.Chapters, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _episodeDescription_summary(ctx context.Context, field graphql.CollectedField, obj *model.EpisodeDescription) (ret graphql.Marshaler) {
+func (ec *executionContext) _episode_downloads(ctx context.Context, field graphql.CollectedField, obj *model.Episode) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2623,7 +2856,7 @@ func (ec *executionContext) _episodeDescription_summary(ctx context.Context, fie
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:     "episodeDescription",
+		Object:     "episode",
 		Field:      field,
 		Args:       nil,
 		IsMethod:   false,
@@ -2633,7 +2866,7 @@ func (ec *executionContext) _episodeDescription_summary(ctx context.Context, fie
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Summary, nil
+		return obj.Downloads, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2645,12 +2878,12 @@ func (ec *executionContext) _episodeDescription_summary(ctx context.Context, fie
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(int)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNInt2int(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _episodeDescription_description(ctx context.Context, field graphql.CollectedField, obj *model.EpisodeDescription) (ret graphql.Marshaler) {
+func (ec *executionContext) _episode_production(ctx context.Context, field graphql.CollectedField, obj *model.Episode) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2658,7 +2891,7 @@ func (ec *executionContext) _episodeDescription_description(ctx context.Context,
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:     "episodeDescription",
+		Object:     "episode",
 		Field:      field,
 		Args:       nil,
 		IsMethod:   false,
@@ -2668,21 +2901,24 @@ func (ec *executionContext) _episodeDescription_description(ctx context.Context,
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Description, nil
+		return obj.Production, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
 		return graphql.Null
 	}
-	res := resTmp.(*string)
+	res := resTmp.(*model.Production)
 	fc.Result = res
-	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
+	return ec.marshalNproduction2ᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐProduction(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _episodeDescription_link(ctx context.Context, field graphql.CollectedField, obj *model.EpisodeDescription) (ret graphql.Marshaler) {
+func (ec *executionContext) _episodeDescription_title(ctx context.Context, field graphql.CollectedField, obj *model.EpisodeDescription) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2700,7 +2936,7 @@ func (ec *executionContext) _episodeDescription_link(ctx context.Context, field
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Link, nil
+		return obj.Title, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2717,7 +2953,7 @@ func (ec *executionContext) _episodeDescription_link(ctx context.Context, field
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _episodeDescription_duration(ctx context.Context, field graphql.CollectedField, obj *model.EpisodeDescription) (ret graphql.Marshaler) {
+func (ec *executionContext) _episodeDescription_summary(ctx context.Context, field graphql.CollectedField, obj *model.EpisodeDescription) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2735,7 +2971,7 @@ func (ec *executionContext) _episodeDescription_duration(ctx context.Context, fi
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Duration, nil
+		return obj.Summary, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2747,12 +2983,12 @@ func (ec *executionContext) _episodeDescription_duration(ctx context.Context, fi
 		}
 		return graphql.Null
 	}
-	res := resTmp.(int)
+	res := resTmp.(string)
 	fc.Result = res
-	return ec.marshalNInt2int(ctx, field.Selections, res)
+	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _labels_block(ctx context.Context, field graphql.CollectedField, obj *model.Labels) (ret graphql.Marshaler) {
+func (ec *executionContext) _episodeDescription_description(ctx context.Context, field graphql.CollectedField, obj *model.EpisodeDescription) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2760,7 +2996,7 @@ func (ec *executionContext) _labels_block(ctx context.Context, field graphql.Col
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:     "labels",
+		Object:     "episodeDescription",
 		Field:      field,
 		Args:       nil,
 		IsMethod:   false,
@@ -2770,24 +3006,21 @@ func (ec *executionContext) _labels_block(ctx context.Context, field graphql.Col
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Block, nil
+		return obj.Description, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
 		return graphql.Null
 	}
 	if resTmp == nil {
-		if !graphql.HasFieldError(ctx, fc) {
-			ec.Errorf(ctx, "must not be null")
-		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(*string)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalOString2ᚖstring(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _labels_explicit(ctx context.Context, field graphql.CollectedField, obj *model.Labels) (ret graphql.Marshaler) {
+func (ec *executionContext) _episodeDescription_link(ctx context.Context, field graphql.CollectedField, obj *model.EpisodeDescription) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2795,7 +3028,7 @@ func (ec *executionContext) _labels_explicit(ctx context.Context, field graphql.
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:     "labels",
+		Object:     "episodeDescription",
 		Field:      field,
 		Args:       nil,
 		IsMethod:   false,
@@ -2805,7 +3038,7 @@ func (ec *executionContext) _labels_explicit(ctx context.Context, field graphql.
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Explicit, nil
+		return obj.Link, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2822,7 +3055,7 @@ func (ec *executionContext) _labels_explicit(ctx context.Context, field graphql.
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _labels_type(ctx context.Context, field graphql.CollectedField, obj *model.Labels) (ret graphql.Marshaler) {
+func (ec *executionContext) _episodeDescription_duration(ctx context.Context, field graphql.CollectedField, obj *model.EpisodeDescription) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2830,7 +3063,7 @@ func (ec *executionContext) _labels_type(ctx context.Context, field graphql.Coll
 		}
 	}()
 	fc := &graphql.FieldContext{
-		Object:     "labels",
+		Object:     "episodeDescription",
 		Field:      field,
 		Args:       nil,
 		IsMethod:   false,
@@ -2840,7 +3073,7 @@ func (ec *executionContext) _labels_type(ctx context.Context, field graphql.Coll
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Type, nil
+		return obj.Duration, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2852,12 +3085,12 @@ func (ec *executionContext) _labels_type(ctx context.Context, field graphql.Coll
 		}
 		return graphql.Null
 	}
-	res := resTmp.(string)
+	res := resTmp.(int)
 	fc.Result = res
-	return ec.marshalNString2string(ctx, field.Selections, res)
+	return ec.marshalNInt2int(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _labels_complete(ctx context.Context, field graphql.CollectedField, obj *model.Labels) (ret graphql.Marshaler) {
+func (ec *executionContext) _labels_block(ctx context.Context, field graphql.CollectedField, obj *model.Labels) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -2875,7 +3108,7 @@ func (ec *executionContext) _labels_complete(ctx context.Context, field graphql.
 	ctx = graphql.WithFieldContext(ctx, fc)
 	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
 		ctx = rctx // use context from middleware stack in children
-		return obj.Complete, nil
+		return obj.Block, nil
 	})
 	if err != nil {
 		ec.Error(ctx, err)
@@ -2892,7 +3125,112 @@ func (ec *executionContext) _labels_complete(ctx context.Context, field graphql.
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
-func (ec *executionContext) _labels_language(ctx context.Context, field graphql.CollectedField, obj *model.Labels) (ret graphql.Marshaler) {
+func (ec *executionContext) _labels_explicit(ctx context.Context, field graphql.CollectedField, obj *model.Labels) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:     "labels",
+		Field:      field,
+		Args:       nil,
+		IsMethod:   false,
+		IsResolver: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Explicit, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _labels_type(ctx context.Context, field graphql.CollectedField, obj *model.Labels) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:     "labels",
+		Field:      field,
+		Args:       nil,
+		IsMethod:   false,
+		IsResolver: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Type, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _labels_complete(ctx context.Context, field graphql.CollectedField, obj *model.Labels) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:     "labels",
+		Field:      field,
+		Args:       nil,
+		IsMethod:   false,
+		IsResolver: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Complete, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _labels_language(ctx context.Context, field graphql.CollectedField, obj *model.Labels) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
 			ec.Error(ctx, ec.Recover(ctx, r))
@@ -3417,6 +3755,41 @@ func (ec *executionContext) _show_image(ctx context.Context, field graphql.Colle
 	return ec.marshalNString2string(ctx, field.Selections, res)
 }
 
+func (ec *executionContext) _show_downloads(ctx context.Context, field graphql.CollectedField, obj *model.Show) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:     "show",
+		Field:      field,
+		Args:       nil,
+		IsMethod:   false,
+		IsResolver: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Downloads, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(int)
+	fc.Result = res
+	return ec.marshalNInt2int(ctx, field.Selections, res)
+}
+
 func (ec *executionContext) _show_episodes(ctx context.Context, field graphql.CollectedField, obj *model.Show) (ret graphql.Marshaler) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -3697,6 +4070,76 @@ func (ec *executionContext) _showDescription_owner(ctx context.Context, field gr
 	return ec.marshalNowner2ᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐOwner(ctx, field.Selections, res)
 }
 
+func (ec *executionContext) _transcript_link(ctx context.Context, field graphql.CollectedField, obj *model.Transcript) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:     "transcript",
+		Field:      field,
+		Args:       nil,
+		IsMethod:   false,
+		IsResolver: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Link, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
+func (ec *executionContext) _transcript_type(ctx context.Context, field graphql.CollectedField, obj *model.Transcript) (ret graphql.Marshaler) {
+	defer func() {
+		if r := recover(); r != nil {
+			ec.Error(ctx, ec.Recover(ctx, r))
+			ret = graphql.Null
+		}
+	}()
+	fc := &graphql.FieldContext{
+		Object:     "transcript",
+		Field:      field,
+		Args:       nil,
+		IsMethod:   false,
+		IsResolver: false,
+	}
+
+	ctx = graphql.WithFieldContext(ctx, fc)
+	resTmp, err := ec.ResolverMiddleware(ctx, func(rctx context.Context) (interface{}, error) {
+		ctx = rctx // use context from middleware stack in children
+		return obj.Type, nil
+	})
+	if err != nil {
+		ec.Error(ctx, err)
+		return graphql.Null
+	}
+	if resTmp == nil {
+		if !graphql.HasFieldError(ctx, fc) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	res := resTmp.(string)
+	fc.Result = res
+	return ec.marshalNString2string(ctx, field.Selections, res)
+}
+
 // endregion **************************** field.gotpl *****************************
 
 // region    **************************** input.gotpl *****************************
@@ -3774,6 +4217,20 @@ func (ec *executionContext) _Query(ctx context.Context, sel ast.SelectionSet) gr
 				}
 				return res
 			})
+		case "downloads":
+			field := field
+			out.Concurrently(i, func() (res graphql.Marshaler) {
+				defer func() {
+					if r := recover(); r != nil {
+						ec.Error(ctx, ec.Recover(ctx, r))
+					}
+				}()
+				res = ec._Query_downloads(ctx, field)
+				if res == graphql.Null {
+					atomic.AddUint32(&invalids, 1)
+				}
+				return res
+			})
 		case "__type":
 			out.Values[i] = ec._Query___type(ctx, field)
 		case "__schema":
@@ -4059,6 +4516,38 @@ func (ec *executionContext) _category(ctx context.Context, sel ast.SelectionSet,
 	return out
 }
 
+var dailyDownloadsImplementors = []string{"dailyDownloads"}
+
+func (ec *executionContext) _dailyDownloads(ctx context.Context, sel ast.SelectionSet, obj *model.DailyDownloads) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, dailyDownloadsImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("dailyDownloads")
+		case "day":
+			out.Values[i] = ec._dailyDownloads_day(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "downloads":
+			out.Values[i] = ec._dailyDownloads_downloads(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
 var enclosureImplementors = []string{"enclosure"}
 
 func (ec *executionContext) _enclosure(ctx context.Context, sel ast.SelectionSet, obj *model.Enclosure) graphql.Marshaler {
@@ -4147,6 +4636,18 @@ func (ec *executionContext) _episode(ctx context.Context, sel ast.SelectionSet,
 			if out.Values[i] == graphql.Null {
 				invalids++
 			}
+		case "transcripts":
+			out.Values[i] = ec._episode_transcripts(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "chapters":
+			out.Values[i] = ec._episode_chapters(ctx, field, obj)
+		case "downloads":
+			out.Values[i] = ec._episode_downloads(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
 		case "production":
 			out.Values[i] = ec._episode_production(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
@@ -4379,6 +4880,11 @@ func (ec *executionContext) _show(ctx context.Context, sel ast.SelectionSet, obj
 			if out.Values[i] == graphql.Null {
 				invalids++
 			}
+		case "downloads":
+			out.Values[i] = ec._show_downloads(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
 		case "episodes":
 			out.Values[i] = ec._show_episodes(ctx, field, obj)
 			if out.Values[i] == graphql.Null {
@@ -4452,6 +4958,38 @@ func (ec *executionContext) _showDescription(ctx context.Context, sel ast.Select
 	return out
 }
 
+var transcriptImplementors = []string{"transcript"}
+
+func (ec *executionContext) _transcript(ctx context.Context, sel ast.SelectionSet, obj *model.Transcript) graphql.Marshaler {
+	fields := graphql.CollectFields(ec.OperationContext, sel, transcriptImplementors)
+
+	out := graphql.NewFieldSet(fields)
+	var invalids uint32
+	for i, field := range fields {
+		switch field.Name {
+		case "__typename":
+			out.Values[i] = graphql.MarshalString("transcript")
+		case "link":
+			out.Values[i] = ec._transcript_link(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		case "type":
+			out.Values[i] = ec._transcript_type(ctx, field, obj)
+			if out.Values[i] == graphql.Null {
+				invalids++
+			}
+		default:
+			panic("unknown field " + strconv.Quote(field.Name))
+		}
+	}
+	out.Dispatch()
+	if invalids > 0 {
+		return graphql.Null
+	}
+	return out
+}
+
 // endregion **************************** object.gotpl ****************************
 
 // region    ***************************** type.gotpl *****************************
@@ -4807,6 +5345,53 @@ func (ec *executionContext) marshalNcategory2ᚖgithubᚗcomᚋpodopsᚋpodops
 	return ec._category(ctx, sel, v)
 }
 
+func (ec *executionContext) marshalNdailyDownloads2ᚕᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐDailyDownloadsᚄ(ctx context.Context, sel ast.SelectionSet, v []*model.DailyDownloads) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNdailyDownloads2ᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐDailyDownloads(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+	return ret
+}
+
+func (ec *executionContext) marshalNdailyDownloads2ᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐDailyDownloads(ctx context.Context, sel ast.SelectionSet, v *model.DailyDownloads) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._dailyDownloads(ctx, sel, v)
+}
+
 func (ec *executionContext) marshalNenclosure2ᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐEnclosure(ctx context.Context, sel ast.SelectionSet, v *model.Enclosure) graphql.Marshaler {
 	if v == nil {
 		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
@@ -4951,6 +5536,53 @@ func (ec *executionContext) marshalNshowDescription2ᚖgithubᚗcomᚋpodopsᚋp
 	return ec._showDescription(ctx, sel, v)
 }
 
+func (ec *executionContext) marshalNtranscript2ᚕᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐTranscriptᚄ(ctx context.Context, sel ast.SelectionSet, v []*model.Transcript) graphql.Marshaler {
+	ret := make(graphql.Array, len(v))
+	var wg sync.WaitGroup
+	isLen1 := len(v) == 1
+	if !isLen1 {
+		wg.Add(len(v))
+	}
+	for i := range v {
+		i := i
+		fc := &graphql.FieldContext{
+			Index:  &i,
+			Result: &v[i],
+		}
+		ctx := graphql.WithFieldContext(ctx, fc)
+		f := func(i int) {
+			defer func() {
+				if r := recover(); r != nil {
+					ec.Error(ctx, ec.Recover(ctx, r))
+					ret = nil
+				}
+			}()
+			if !isLen1 {
+				defer wg.Done()
+			}
+			ret[i] = ec.marshalNtranscript2ᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐTranscript(ctx, sel, v[i])
+		}
+		if isLen1 {
+			f(i)
+		} else {
+			go f(i)
+		}
+
+	}
+	wg.Wait()
+	return ret
+}
+
+func (ec *executionContext) marshalNtranscript2ᚖgithubᚗcomᚋpodopsᚋpodopsᚋgraphqlᚋgraphᚋmodelᚐTranscript(ctx context.Context, sel ast.SelectionSet, v *model.Transcript) graphql.Marshaler {
+	if v == nil {
+		if !graphql.HasFieldError(ctx, graphql.GetFieldContext(ctx)) {
+			ec.Errorf(ctx, "must not be null")
+		}
+		return graphql.Null
+	}
+	return ec._transcript(ctx, sel, v)
+}
+
 func (ec *executionContext) unmarshalOBoolean2bool(ctx context.Context, v interface{}) (bool, error) {
 	res, err := graphql.UnmarshalBoolean(v)
 	return res, graphql.ErrorOnPath(ctx, err)
@@ -7,6 +7,11 @@ type Category struct {
 	Subcategory *string `json:"subcategory"`
 }
 
+type DailyDownloads struct {
+	Day       string `json:"day"`
+	Downloads int    `json:"downloads"`
+}
+
 type Enclosure struct {
 	Link string `json:"link"`
 	Type string `json:"type"`
@@ -22,6 +27,9 @@ type Episode struct {
 	Description *EpisodeDescription `json:"description"`
 	Image       string              `json:"image"`
 	Enclosure   *Enclosure          `json:"enclosure"`
+	Transcripts []*Transcript       `json:"transcripts"`
+	Chapters    *string             `json:"chapters"`
+	Downloads   int                 `json:"downloads"`
 	Production  *Production         `json:"production"`
 }
 
@@ -62,6 +70,7 @@ type Show struct {
 	Labels      *Labels          `json:"labels"`
 	Description *ShowDescription `json:"description"`
 	Image       string           `json:"image"`
+	Downloads   int              `json:"downloads"`
 	Episodes    []*Episode       `json:"episodes"`
 }
 
@@ -74,3 +83,8 @@ type ShowDescription struct {
 	Copyright string      `json:"copyright"`
 	Owner     *Owner      `json:"owner"`
 }
+
+type Transcript struct {
+	Link string `json:"link"`
+	Type string `json:"type"`
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/podops/podops"
 	"github.com/podops/podops/backend"
 	"github.com/podops/podops/graphql/graph/model"
+	"github.com/podops/podops/internal/analytics"
 	"github.com/podops/podops/internal/loader"
 	"github.com/podops/podops/internal/messagedef"
 )
@@ -57,6 +58,11 @@ func LoadShow(ctx context.Context, key string) (interface{}, error) {
 		//Season: NOT USED
 	}
 
+	downloads, err := backend.CountDownloads(ctx, p.GUID, "", analytics.Since(backend.PopularityDays))
+	if err != nil {
+		return nil, err
+	}
+
 	result := model.Show{
 		GUID:    p.GUID,
 		Name:    p.Name,
@@ -75,7 +81,8 @@ func LoadShow(ctx context.Context, key string) (interface{}, error) {
 				Email: show.Description.Owner.Email,
 			},
 		},
-		Image: show.Image.URI,
+		Image:     show.Image.URI,
+		Downloads: int(downloads),
 		// Episodes are loaded by the schema.resolver implementation in order make use of the dataloader
 	}
 
@@ -102,6 +109,11 @@ func LoadEpisode(ctx context.Context, key string) (interface{}, error) {
 	}
 	episode := e.(*podops.Episode)
 
+	downloads, err := backend.CountDownloads(ctx, r.ParentGUID, r.GUID, analytics.Since(backend.PopularityDays))
+	if err != nil {
+		return nil, err
+	}
+
 	n, _ := strconv.ParseInt(episode.Metadata.Labels[podops.LabelEpisode], 10, 64)
 	season, _ := strconv.ParseInt(episode.Metadata.Labels[podops.LabelSeason], 10, 64)
 	labels := &model.Labels{
@@ -114,6 +126,18 @@ func LoadEpisode(ctx context.Context, key string) (interface{}, error) {
 		Season:  int(season),
 	}
 
+	transcripts := make([]*model.Transcript, len(episode.Transcripts))
+	for i := range episode.Transcripts {
+		transcripts[i] = &model.Transcript{
+			Link: episode.Transcripts[i].URI,
+			Type: episode.Transcripts[i].Type,
+		}
+	}
+	var chapters *string
+	if episode.Chapters != nil {
+		chapters = &episode.Chapters.URI
+	}
+
 	result := model.Episode{
 		GUID:      episode.GUID(),
 		Name:      episode.Metadata.Name,
@@ -133,6 +157,9 @@ func LoadEpisode(ctx context.Context, key string) (interface{}, error) {
 			Type: episode.Enclosure.Type,
 			Size: episode.Enclosure.Size,
 		},
+		Transcripts: transcripts,
+		Chapters:    chapters,
+		Downloads:   int(downloads),
 		Production: &model.Production{
 			GUID:  p.GUID,
 			Name:  p.Name,
@@ -11,9 +11,12 @@ import (
 	"github.com/txsvc/platform/v2"
 	"github.com/txsvc/platform/v2/pkg/timestamp"
 
+	"github.com/podops/podops"
 	"github.com/podops/podops/backend"
 	"github.com/podops/podops/graphql/graph/generated"
 	"github.com/podops/podops/graphql/graph/model"
+	"github.com/podops/podops/internal/analytics"
+	"github.com/podops/podops/internal/errordef"
 )
 
 func (r *queryResolver) Show(ctx context.Context, name *string, limit int) (*model.Show, error) {
@@ -119,7 +122,77 @@ func (r *queryResolver) Recent(ctx context.Context, limit int) ([]*model.Show, e
 }
 
 func (r *queryResolver) Popular(ctx context.Context, limit int) ([]*model.Show, error) {
-	return r.Recent(ctx, limit) // FIXME this is just a placeholder, we don't have usage data at the moment to return a real answer
+	sh, err := backend.ListPopularProductions(ctx, limit)
+	if err != nil {
+		platform.ReportError(err)
+		return nil, err
+	}
+
+	shows := make([]*model.Show, len(sh))
+	for i := range sh {
+		show, err := r.ShowLoader.Load(ctx, sh[i].Name)
+		if err != nil {
+			platform.ReportError(err)
+			return nil, err
+		}
+		shows[i] = show.(*model.Show)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "graphql.popular", "limit", fmt.Sprintf("%d", limit))
+
+	return shows, nil
+}
+
+func (r *queryResolver) Downloads(ctx context.Context, guid string, days int) ([]*model.DailyDownloads, error) {
+	if days < 1 {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	// guid is either a show or an episode
+	rsrc, err := backend.GetResource(ctx, guid)
+	if err != nil {
+		platform.ReportError(err)
+		return nil, err
+	}
+	if rsrc == nil {
+		return make([]*model.DailyDownloads, 0), nil
+	}
+	production, episode := rsrc.GUID, ""
+	if rsrc.Kind == podops.ResourceEpisode {
+		production, episode = rsrc.ParentGUID, rsrc.GUID
+	}
+
+	// only published shows are public
+	p, err := backend.GetProduction(ctx, production)
+	if err != nil {
+		platform.ReportError(err)
+		return nil, err
+	}
+	if p == nil || !p.Published || p.Private {
+		return make([]*model.DailyDownloads, 0), nil
+	}
+
+	stats, err := backend.ListStats(ctx, production, episode, analytics.Since(days))
+	if err != nil {
+		platform.ReportError(err)
+		return nil, err
+	}
+
+	// sum up the episodes of each day
+	downloads := make([]*model.DailyDownloads, 0)
+	for _, s := range stats {
+		if n := len(downloads); n > 0 && downloads[n-1].Day == s.Day {
+			downloads[n-1].Downloads += int(s.Downloads)
+			continue
+		}
+		downloads = append(downloads, &model.DailyDownloads{Day: s.Day, Downloads: int(s.Downloads)})
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "graphql.downloads", "production", production)
+
+	return downloads, nil
 }
 
 // Query returns generated.QueryResolver implementation.
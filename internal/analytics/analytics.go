@@ -0,0 +1,172 @@
+package analytics
+
+import (
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/txsvc/platform/v2/pkg/id"
+)
+
+const (
+	// DedupWindow is the period in which repeated requests of the same listener count as one download (IAB v2)
+	DedupWindow = 24 * time.Hour
+	// MinDownloadBytes is roughly one minute of audio at 128kbps. Smaller files count once they were served completely.
+	MinDownloadBytes = 960 * 1024
+
+	// ClientBot identifies crawlers, scripts and other non-human clients
+	ClientBot = "Bot"
+	// ClientBrowser identifies web browsers
+	ClientBrowser = "Browser"
+	// ClientOther identifies all unknown clients
+	ClientOther = "Other"
+)
+
+type (
+	// Request is a single request for an asset, as seen by the CDN
+	Request struct {
+		Production string
+		Asset      string
+		UserAgent  string
+		RemoteAddr string
+		Range      string // the Range header of the request, if any
+		Status     int
+		Bytes      int64 // bytes served
+		Size       int64 // total size of the asset, 0 if unknown
+		Timestamp  int64
+	}
+
+	// client maps a user agent fragment to the name of a podcast app
+	client struct {
+		fragment string
+		name     string
+	}
+)
+
+var (
+	// user agent fragments of bots, scripts and libraries, based on the IAB/ABC Spiders & Bots list. Lower case.
+	botFragments = []string{
+		"bot", "crawl", "spider", "slurp", "scrape", "fetch", "monitor", "preview", "headless",
+		"curl/", "wget/", "python-", "go-http-client", "java/", "libwww", "httpclient", "node-fetch", "axios/",
+		"facebookexternalhit", "feedburner", "podcastindex", "lighthouse",
+	}
+
+	// user agent fragments of podcast apps, in order of precedence
+	podcastClients = []client{
+		{"podcasts/", "Apple Podcasts"},
+		{"itunes/", "Apple Podcasts"},
+		{"applecoremedia/", "Apple Podcasts"},
+		{"spotify", "Spotify"},
+		{"overcast", "Overcast"},
+		{"pocketcasts", "Pocket Casts"},
+		{"pocket casts", "Pocket Casts"},
+		{"castro", "Castro"},
+		{"castbox", "Castbox"},
+		{"podcastaddict", "Podcast Addict"},
+		{"podcast addict", "Podcast Addict"},
+		{"antennapod", "AntennaPod"},
+		{"stitcher", "Stitcher"},
+		{"podbean", "Podbean"},
+		{"deezer", "Deezer"},
+		{"amazonmusic", "Amazon Music"},
+		{"amazon music", "Amazon Music"},
+		{"googlepodcasts", "Google Podcasts"},
+		{"gsa/", "Google Podcasts"},
+		{"player fm", "Player FM"},
+		{"playerfm", "Player FM"},
+		{"downcast", "Downcast"},
+		{"icatcher", "iCatcher"},
+		{"podverse", "Podverse"},
+		{"fountain", "Fountain"},
+	}
+)
+
+// ClientName returns the name of the podcast app that sent a request, ClientBot, ClientBrowser or ClientOther
+func ClientName(userAgent string) string {
+	ua := strings.ToLower(strings.TrimSpace(userAgent))
+	if ua == "" {
+		return ClientBot // real clients identify themselves
+	}
+	// apps are checked first, some of them include e.g. 'fetch' in their user agent
+	for _, c := range podcastClients {
+		if strings.Contains(ua, c.fragment) {
+			return c.name
+		}
+	}
+	for _, f := range botFragments {
+		if strings.Contains(ua, f) {
+			return ClientBot
+		}
+	}
+	if strings.HasPrefix(ua, "mozilla/") {
+		return ClientBrowser
+	}
+	return ClientOther
+}
+
+// IsBot returns true if the user agent belongs to a crawler, script or library
+func IsBot(userAgent string) bool {
+	return ClientName(userAgent) == ClientBot
+}
+
+// Listener returns an anonymous ID of the listener, based on IP address and user agent
+func (r *Request) Listener() string {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	return id.Checksum(ip + "/" + r.UserAgent + "/" + r.Production + "/" + r.Asset)
+}
+
+// IsCandidate returns true if the request can contribute to a download: the asset was served to a
+// client that is not a bot, and it was not a probe for just the first few bytes of the file.
+func (r *Request) IsCandidate() bool {
+	if r.Status != 200 && r.Status != 206 {
+		return false
+	}
+	if r.Bytes <= 0 || IsBot(r.UserAgent) {
+		return false
+	}
+	// e.g. Apple Podcasts requests 'bytes=0-1' to check the file before downloading it
+	if r.Range == "bytes=0-1" || r.Range == "bytes=0-0" {
+		return false
+	}
+	return true
+}
+
+// Threshold returns the number of bytes a listener has to download before a download is counted
+func (r *Request) Threshold() int64 {
+	if r.Size > 0 && r.Size < MinDownloadBytes {
+		return r.Size
+	}
+	return MinDownloadBytes
+}
+
+// Day returns the UTC day of the request, e.g. '2021-03-01'
+func (r *Request) Day() string {
+	return DayOf(r.Timestamp)
+}
+
+// DayOf returns the UTC day of a timestamp, e.g. '2021-03-01'
+func DayOf(ts int64) string {
+	return time.Unix(ts, 0).UTC().Format("2006-01-02")
+}
+
+// Since returns the first day of a period of days that ends today
+func Since(days int) string {
+	return DayOf(time.Now().Add(-time.Duration(days-1) * 24 * time.Hour).Unix())
+}
+
+// SizeFromContentRange returns the total size from a Content-Range header, e.g. 'bytes 0-1023/146515', or 0
+func SizeFromContentRange(contentRange string) int64 {
+	i := strings.LastIndex(contentRange, "/")
+	if i < 0 {
+		return 0
+	}
+	size, err := strconv.ParseInt(contentRange[i+1:], 10, 64)
+	if err != nil {
+		return 0
+	}
+	return size
+}
@@ -0,0 +1,80 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientName(t *testing.T) {
+	clients := map[string]string{
+		"":                                     ClientBot,
+		"Podcasts/1510.13 CFNetwork/1240.0.4":  "Apple Podcasts",
+		"AppleCoreMedia/1.0.0.18E199 (iPhone)": "Apple Podcasts",
+		"Spotify/8.6.26 iOS/14.4":              "Spotify",
+		"Overcast/3.0 (+http://overcast.fm/)":  "Overcast",
+		"PocketCasts/1.0 (Pocket Casts Feed Parser; +http://pocketcasts.com/)": "Pocket Casts",
+		"curl/7.64.1": ClientBot,
+		"Mozilla/5.0 (compatible; Googlebot/2.1)":                              ClientBot,
+		"Go-http-client/1.1":                                                   ClientBot,
+		"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15": ClientBrowser,
+		"VLC/3.0.12 LibVLC/3.0.12":                                             ClientOther,
+	}
+	for ua, name := range clients {
+		assert.Equal(t, name, ClientName(ua), ua)
+	}
+	assert.True(t, IsBot("python-requests/2.25.1"))
+	assert.False(t, IsBot("Overcast/3.0"))
+}
+
+func TestIsCandidate(t *testing.T) {
+	requests := []struct {
+		r         Request
+		candidate bool
+	}{
+		{Request{UserAgent: "Overcast/3.0", Status: 200, Bytes: 1024}, true},
+		{Request{UserAgent: "Overcast/3.0", Status: 206, Bytes: 1024, Range: "bytes=1024-"}, true},
+		{Request{UserAgent: "Overcast/3.0", Status: 206, Bytes: 2, Range: "bytes=0-1"}, false},
+		{Request{UserAgent: "Overcast/3.0", Status: 304}, false},
+		{Request{UserAgent: "Overcast/3.0", Status: 404, Bytes: 1024}, false},
+		{Request{UserAgent: "Overcast/3.0", Status: 200}, false},
+		{Request{UserAgent: "curl/7.64.1", Status: 200, Bytes: 1024}, false},
+	}
+	for _, c := range requests {
+		assert.Equal(t, c.candidate, c.r.IsCandidate(), c.r)
+	}
+}
+
+func TestThreshold(t *testing.T) {
+	assert.Equal(t, int64(MinDownloadBytes), (&Request{}).Threshold())
+	assert.Equal(t, int64(MinDownloadBytes), (&Request{Size: 10 * MinDownloadBytes}).Threshold())
+	assert.Equal(t, int64(1024), (&Request{Size: 1024}).Threshold())
+}
+
+func TestListener(t *testing.T) {
+	r1 := Request{Production: "prod", Asset: "episode1.mp3", UserAgent: "Overcast/3.0", RemoteAddr: "10.0.0.1:4321"}
+	r2 := r1
+	r2.RemoteAddr = "10.0.0.1:5678"
+	assert.Equal(t, r1.Listener(), r2.Listener(), "the port is ignored")
+
+	r2.Asset = "episode2.mp3"
+	assert.NotEqual(t, r1.Listener(), r2.Listener())
+	r2 = r1
+	r2.UserAgent = "Spotify/8.6.26"
+	assert.NotEqual(t, r1.Listener(), r2.Listener())
+}
+
+func TestDays(t *testing.T) {
+	ts := time.Date(2021, 3, 1, 23, 59, 59, 0, time.UTC).Unix()
+	assert.Equal(t, "2021-03-01", DayOf(ts))
+	assert.Equal(t, "2021-03-01", (&Request{Timestamp: ts}).Day())
+	assert.Equal(t, DayOf(time.Now().Unix()), Since(1))
+	assert.Equal(t, DayOf(time.Now().Add(-24*time.Hour).Unix()), Since(2))
+}
+
+func TestSizeFromContentRange(t *testing.T) {
+	assert.Equal(t, int64(146515), SizeFromContentRange("bytes 0-1023/146515"))
+	assert.Equal(t, int64(0), SizeFromContentRange("bytes 0-1023/*"))
+	assert.Equal(t, int64(0), SizeFromContentRange(""))
+}
@@ -68,6 +68,12 @@ func (a *authProviderImpl) ProvideAuthorizationToken(ctx context.Context, realm,
 	return nil
 }
 
+// InvitationNotification lets a new member know about the production they were added to
+func InvitationNotification(ctx context.Context, userID, invitedBy, show, role string) error {
+	body := fmt.Sprintf(messagedef.MsgMemberInvitation, invitedBy, role, show, userID)
+	return SendEmail(env.GetString("EMAIL_FROM", "hello@podops.dev"), userID, fmt.Sprintf("You were added to '%s'", show), body)
+}
+
 func (a *authProviderImpl) Options() *provider.AuthenticationProviderConfig {
 	return &provider.AuthenticationProviderConfig{
 		Scope:                    defaultScope,
@@ -10,6 +10,7 @@ import (
 
 	"github.com/podops/podops"
 	"github.com/podops/podops/backend"
+	"github.com/podops/podops/feed"
 	"github.com/podops/podops/internal/errordef"
 )
 
@@ -29,6 +30,17 @@ func FeedEndpoint(c echo.Context) error { // FIXME not needed !
 	}
 
 	if prod == nil {
+		// the production might have been renamed, podcast apps update their subscription on a permanent redirect
+		if prod, err = backend.FindProductionByAlias(platform.NewHttpContext(c.Request()), name); err != nil {
+			return api.ErrorResponse(c, http.StatusInternalServerError, err)
+		}
+		if prod != nil {
+			return c.Redirect(http.StatusMovedPermanently, backend.FeedAliasURL(prod.Name))
+		}
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchProduction)
+	}
+
+	if prod.Deleted > 0 {
 		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchProduction)
 	}
 
@@ -39,3 +51,51 @@ func FeedEndpoint(c echo.Context) error { // FIXME not needed !
 
 	return c.Redirect(http.StatusTemporaryRedirect, redirectTo)
 }
+
+// PrivateFeedEndpoint serves the private feed of a production to a subscriber. Its enclosures carry the subscriber's token.
+func PrivateFeedEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	name := c.Param("name")
+	token := c.Param("token")
+	if name == "" || token == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+
+	prod, err := backend.FindProductionByName(ctx, name)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if prod == nil {
+		// the production might have been renamed
+		if prod, err = backend.FindProductionByAlias(ctx, name); err != nil {
+			return api.ErrorResponse(c, http.StatusInternalServerError, err)
+		}
+		if prod != nil {
+			return c.Redirect(http.StatusMovedPermanently, backend.PrivateFeedURL(prod.Name, token))
+		}
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchProduction)
+	}
+
+	if prod.Deleted > 0 {
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchProduction)
+	}
+
+	valid, err := backend.ValidSubscription(ctx, prod.GUID, token)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !valid {
+		return api.ErrorResponse(c, http.StatusForbidden, errordef.ErrNoSuchSubscription)
+	}
+
+	data, err := backend.DefaultBlobStore().Read(ctx, fmt.Sprintf("%s/private.xml", prod.GUID))
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusNotFound, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "cdn.feed.private", "production", prod.GUID, "user-agent", c.Request().UserAgent(), "remote_addr", c.Request().RemoteAddr)
+
+	return c.Blob(http.StatusOK, "application/rss+xml; charset=utf-8", feed.PrivateFeed(data, token))
+}
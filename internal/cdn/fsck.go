@@ -0,0 +1,528 @@
+package cdn
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/api"
+	"github.com/txsvc/platform/v2/pkg/authentication"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/apiv1"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/messagedef"
+	"github.com/podops/podops/internal/metadata"
+)
+
+var (
+	// orphanGracePeriod protects files written by uploads and imports that are still running from the orphan sweep
+	orphanGracePeriod = time.Hour
+)
+
+type (
+	// reconciler collects the issues of a production and repairs them if asked to
+	reconciler struct {
+		ctx    context.Context
+		prod   string
+		repair bool
+		report *podops.ReconciliationReport
+		// modified is the modification time of the files on the CDN
+		modified map[string]time.Time
+	}
+)
+
+// ReconcileEndpoint compares the inventory, the production bucket and the files on the CDN.
+// GET only reports the issues, POST queues a task that also repairs them as far as possible.
+func ReconcileEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" || filepath.Base(prod) != prod {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := apiv1.AuthorizeAccessProduction(ctx, c, authentication.ScopeAPIAdmin, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	if c.Request().Method == http.MethodPost {
+		return queueRepair(c, prod)
+	}
+
+	report, err := ReconcileProduction(ctx, prod, false)
+	if err != nil {
+		if err == errordef.ErrNoSuchProduction {
+			return api.ErrorResponse(c, http.StatusNotFound, err)
+		}
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "cdn.fsck", "production", prod)
+
+	return api.StandardResponse(c, http.StatusOK, report)
+}
+
+// RepairReportEndpoint returns the report of the latest repair of a production
+func RepairReportEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" || filepath.Base(prod) != prod {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := apiv1.AuthorizeAccessProduction(ctx, c, authentication.ScopeAPIAdmin, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	var report podops.ReconciliationReport
+	found, err := backend.ReadReport(ctx, prod, backend.CheckRepair, &report)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if !found {
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchObject)
+	}
+	return api.StandardResponse(c, http.StatusOK, &report)
+}
+
+// RepairTaskEndpoint runs a repair queued by ReconcileEndpoint and stores its report
+func RepairTaskEndpoint(c echo.Context) error {
+	var req *podops.CheckRequest = new(podops.CheckRequest)
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := c.Bind(req); err != nil {
+		// just report and return, resending will not change anything
+		platform.ReportError(err)
+		return c.NoContent(http.StatusOK)
+	}
+	if err := apiv1.AuthorizeAccess(ctx, c, authentication.ScopeAPIAdmin); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	if req.GUID == "" || filepath.Base(req.GUID) != req.GUID {
+		platform.ReportError(errordef.ErrInvalidParameters)
+		return c.NoContent(http.StatusOK)
+	}
+
+	report, err := ReconcileProduction(ctx, req.GUID, true)
+	if err == errordef.ErrNoSuchProduction {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusOK) // purged in the meantime
+	}
+	if err != nil {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusServiceUnavailable) // the task will be retried
+	}
+	report.Created = req.Created
+	report.Finished = timestamp.Now()
+	if err := backend.WriteReport(ctx, req.GUID, backend.CheckRepair, report); err != nil {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusServiceUnavailable)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// queueRepair stores a pending report and queues the repair
+func queueRepair(c echo.Context, prod string) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	p, err := backend.GetProduction(ctx, prod)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if p == nil {
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchProduction)
+	}
+
+	// the pending report tells the client that the repair is running
+	report := podops.ReconciliationReport{
+		GUID:    prod,
+		Created: timestamp.Now(),
+		Issues:  make([]*podops.ReconciliationIssue, 0),
+	}
+	if err := backend.WriteReport(ctx, prod, backend.CheckRepair, &report); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if err := backend.QueueRepair(ctx, prod, report.Created); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "cdn.fsck.repair", "production", prod)
+
+	return api.StandardResponse(c, http.StatusAccepted, &report)
+}
+
+// ReconcileProduction finds orphans, missing files and mismatched sizes between the RESOURCES and METADATA entities,
+// the production bucket and the files on the CDN. Background tasks that failed leave these behind.
+func ReconcileProduction(ctx context.Context, prod string, repair bool) (*podops.ReconciliationReport, error) {
+	p, err := backend.GetProduction(ctx, prod)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errordef.ErrNoSuchProduction
+	}
+
+	rsrc, err := backend.ListResources(ctx, prod, podops.ResourceALL)
+	if err != nil {
+		return nil, err
+	}
+	trash, err := backend.ListTrash(ctx, prod)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := backend.ListMetadata(ctx, prod)
+	if err != nil {
+		return nil, err
+	}
+	objects, err := backend.DefaultBlobStore().List(ctx, prod+"/")
+	if err != nil {
+		return nil, err
+	}
+	files, err := ioutil.ReadDir(filepath.Join(podops.StorageLocation, prod))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	r := reconciler{
+		ctx:    ctx,
+		prod:   prod,
+		repair: repair,
+		report: &podops.ReconciliationReport{
+			GUID:   prod,
+			Issues: make([]*podops.ReconciliationIssue, 0),
+		},
+		modified: make(map[string]time.Time),
+	}
+
+	// the .yaml of shows and episodes, the build artifacts and feeds are in the bucket
+	buckets := make(map[string]bool)
+	for _, o := range objects {
+		buckets[o] = true
+	}
+	r.resources(rsrc, trash, objects, buckets)
+
+	// assets and the public feed are on the CDN
+	sizes := make(map[string]int64)
+	for _, fi := range files {
+		if !fi.IsDir() && !strings.HasPrefix(fi.Name(), ".") {
+			sizes[fi.Name()] = fi.Size()
+			r.modified[fi.Name()] = fi.ModTime()
+		}
+	}
+	r.assets(rsrc, trash, meta, files, sizes, buckets)
+	if !p.Private && buckets[prod+"/feed.xml"] {
+		if err := r.feed(sizes); err != nil {
+			return nil, err
+		}
+	}
+
+	return r.report, nil
+}
+
+// resources compares the inventory entries of shows and episodes with their .yaml in the production bucket
+func (r *reconciler) resources(rsrc []*podops.Resource, trash []*podops.TrashItem, objects []string, buckets map[string]bool) {
+	locations := make(map[string]bool)
+	for _, res := range rsrc {
+		if res.Kind == podops.ResourceAsset {
+			continue
+		}
+		r.report.Checked++
+		locations[res.Location] = true
+
+		if !buckets[res.Location] {
+			r.issue(podops.StoreBucket, res.Location, podops.IntegrityMissing, messagedef.MsgFsckNoObject, r.restoreRevision(res))
+		}
+	}
+
+	trashed := make(map[string]bool)
+	for _, t := range trash {
+		trashed[t.GUID] = true
+	}
+	for _, o := range objects {
+		name := strings.TrimPrefix(o, r.prod+"/")
+		if strings.Contains(name, "/") || filepath.Ext(name) != ".yaml" {
+			continue // revisions, the trash and build artifacts are tracked elsewhere
+		}
+		r.report.Checked++
+		if locations[o] {
+			continue
+		}
+
+		// show-<guid>.yaml, episode-<guid>.yaml. Without the kind, the guid is unknown and the object can't be in the trash.
+		i := strings.Index(name, "-")
+		if i < 0 {
+			r.issue(podops.StoreBucket, o, podops.IntegrityOrphan, messagedef.MsgFsckUnknownObject, r.reindexResource(o))
+			continue
+		}
+		guid := strings.TrimSuffix(name[i+1:], ".yaml")
+		if trashed[guid] {
+			r.issue(podops.StoreBucket, o, podops.IntegrityOrphan, messagedef.MsgFsckTrashedObject, r.removeObject(o))
+		} else {
+			r.issue(podops.StoreBucket, o, podops.IntegrityOrphan, messagedef.MsgFsckUnknownObject, r.reindexResource(o))
+		}
+	}
+}
+
+// assets compares the inventory entries and metadata of assets with the files on the CDN
+func (r *reconciler) assets(rsrc []*podops.Resource, trash []*podops.TrashItem, meta []*metadata.Metadata, files []os.FileInfo, sizes map[string]int64, buckets map[string]bool) {
+	tracked := make(map[string]bool)
+	trashed := make(map[string]bool)
+	assets := make(map[string]*podops.Resource)
+	for _, t := range trash {
+		if t.Kind == podops.ResourceAsset {
+			trashed[t.GUID] = true
+			tracked[metadata.LocalNamePart(t.Location)] = true
+		}
+	}
+	described := make(map[string]*metadata.Metadata)
+	for _, m := range meta {
+		described[m.GUID] = m
+	}
+
+	for _, res := range rsrc {
+		if res.Kind != podops.ResourceAsset {
+			continue
+		}
+		r.report.Checked++
+		name := metadata.LocalNamePart(res.Location)
+		tracked[name] = true
+		assets[res.GUID] = res
+
+		m := described[res.GUID]
+		if _, ok := sizes[name]; !ok {
+			if m != nil {
+				continue // reported with the metadata
+			}
+			r.issue(podops.StoreCDN, name, podops.IntegrityMissing, messagedef.MsgFsckNoFile, nil)
+			continue
+		}
+		if m == nil {
+			r.issue(podops.StoreInventory, name, podops.IntegrityOrphan, messagedef.MsgFsckNoMetadata, r.reindexAsset(name, res.GUID, assetRel(res), nil, true))
+		}
+	}
+
+	for _, m := range meta {
+		r.report.Checked++
+		tracked[m.Name] = true
+		res := assets[m.GUID]
+		size, ok := sizes[m.Name]
+
+		if res == nil && !trashed[m.GUID] {
+			if r.fresh(m.Name) {
+				continue // the upload or import might still be running
+			}
+			// the upload or import stopped half-way, or the asset was purged but its metadata was not
+			fix := r.deleteMetadata(m.GUID)
+			if ok {
+				fix = r.reindexAsset(m.Name, m.GUID, metadataRel(m), m, true)
+			}
+			r.issue(podops.StoreInventory, m.Name, podops.IntegrityOrphan, messagedef.MsgFsckNoResource, fix)
+			continue
+		}
+		if !ok {
+			// only assets that are in use are imported again, the trash is left alone
+			var fix func() error
+			if res != nil && isRemote(m.Origin) {
+				fix = r.reimport(m.Origin)
+			}
+			r.issue(podops.StoreCDN, m.Name, podops.IntegrityMissing, messagedef.MsgFsckNoFile, fix)
+			continue
+		}
+		if m.Size != size {
+			fix := r.reindexAsset(m.Name, m.GUID, metadataRel(m), m, res != nil)
+			if res != nil && isRemote(m.Origin) {
+				fix = r.reimport(m.Origin)
+			}
+			r.issue(podops.StoreCDN, m.Name, podops.IntegritySize, fmt.Sprintf(messagedef.MsgFsckSizeMismatch, m.Size, size), fix)
+		}
+	}
+
+	// whatever is left on the CDN was not removed when its asset was purged
+	for _, fi := range files {
+		name := fi.Name()
+		if _, ok := sizes[name]; !ok || tracked[name] || buckets[r.prod+"/"+name] || r.fresh(name) {
+			continue
+		}
+		r.report.Checked++
+		r.issue(podops.StoreCDN, name, podops.IntegrityOrphan, messagedef.MsgFsckUnknownFile, r.removeFile(name))
+	}
+}
+
+// feed compares the public feed on the CDN with the one in the production bucket
+func (r *reconciler) feed(sizes map[string]int64) error {
+	r.report.Checked++
+
+	data, err := backend.DefaultBlobStore().Read(r.ctx, r.prod+"/feed.xml")
+	if err != nil {
+		return err
+	}
+	size, ok := sizes["feed.xml"]
+	if !ok {
+		r.issue(podops.StoreCDN, "feed.xml", podops.IntegrityMissing, messagedef.MsgFsckNoFile, r.sync("feed.xml"))
+	} else if size != int64(len(data)) {
+		r.issue(podops.StoreCDN, "feed.xml", podops.IntegritySize, fmt.Sprintf(messagedef.MsgFsckSizeMismatch, len(data), size), r.sync("feed.xml"))
+	}
+	return nil
+}
+
+// fresh returns true if a file on the CDN was written within the grace period, e.g. by an upload or import that is still running
+func (r *reconciler) fresh(name string) bool {
+	t, ok := r.modified[name]
+	return ok && time.Since(t) < orphanGracePeriod
+}
+
+// issue records a problem and repairs it if fix is not nil
+func (r *reconciler) issue(store, name, problem, msg string, fix func() error) {
+	issue := podops.ReconciliationIssue{
+		Store:   store,
+		Name:    name,
+		Problem: problem,
+		Message: msg,
+	}
+	if r.repair {
+		if fix == nil {
+			issue.Message = fmt.Sprintf(messagedef.MsgFsckNotRepairable, msg)
+		} else if err := fix(); err != nil {
+			platform.ReportError(err)
+			issue.Message = fmt.Sprintf(messagedef.MsgFsckRepairFailed, msg, err)
+		} else {
+			issue.Repaired = true
+			r.report.Repaired++
+		}
+	}
+	r.report.Issues = append(r.report.Issues, &issue)
+}
+
+// restoreRevision writes the latest revision of a show or episode back to the bucket
+func (r *reconciler) restoreRevision(res *podops.Resource) func() error {
+	return func() error {
+		revisions, err := backend.ListRevisions(r.ctx, res.GUID)
+		if err != nil {
+			return err
+		}
+		if len(revisions) == 0 {
+			return errordef.ErrNoSuchRevision
+		}
+		data, err := backend.ReadRevisionContent(r.ctx, revisions[0])
+		if err != nil {
+			return err
+		}
+		return backend.DefaultBlobStore().Write(r.ctx, res.Location, data)
+	}
+}
+
+// reindexResource creates the inventory entry of a show or episode from its .yaml
+func (r *reconciler) reindexResource(location string) func() error {
+	return func() error {
+		rsrc, _, _, err := backend.ReadResourceContent(r.ctx, location)
+		if err != nil {
+			return err
+		}
+		switch v := rsrc.(type) {
+		case *podops.Show:
+			return backend.UpdateShow(r.ctx, location, v)
+		case *podops.Episode:
+			return backend.UpdateEpisode(r.ctx, location, v)
+		}
+		return fmt.Errorf(messagedef.MsgResourceIsInvalid, location)
+	}
+}
+
+// reindexAsset extracts the metadata of a file on the CDN again. The inventory entry is only
+// updated if inventory is true, assets in the trash keep their metadata only.
+func (r *reconciler) reindexAsset(name, guid, rel string, old *metadata.Metadata, inventory bool) func() error {
+	return func() error {
+		location := r.prod + "/" + name
+		meta, err := metadata.ExtractMetadataFromFile(filepath.Join(podops.StorageLocation, location))
+		if err != nil {
+			return err
+		}
+		meta.GUID = guid
+		meta.ParentGUID = r.prod
+		meta.Origin = location
+		if old != nil {
+			meta.Origin = old.Origin
+		}
+
+		if !inventory {
+			return backend.UpdateMetadata(r.ctx, meta)
+		}
+		return backend.UpdateAsset(r.ctx, meta, r.prod, location, rel)
+	}
+}
+
+// reimport downloads an asset from its origin again
+func (r *reconciler) reimport(origin string) func() error {
+	return func() error {
+		if status := ImportResource(r.ctx, r.prod, origin); status != http.StatusOK {
+			return fmt.Errorf(messagedef.MsgResourceImportError, origin)
+		}
+		return nil
+	}
+}
+
+// sync copies a file from the production bucket to the CDN again
+func (r *reconciler) sync(src string) func() error {
+	return func() error {
+		if status := SyncResource(r.ctx, r.prod, src); status != http.StatusOK {
+			return fmt.Errorf(messagedef.MsgResourceImportError, src)
+		}
+		return nil
+	}
+}
+
+// deleteMetadata removes the metadata of an asset that no longer exists
+func (r *reconciler) deleteMetadata(guid string) func() error {
+	return func() error {
+		return backend.DeleteMetadata(r.ctx, guid)
+	}
+}
+
+// removeObject deletes an object from the production bucket
+func (r *reconciler) removeObject(location string) func() error {
+	return func() error {
+		return backend.DefaultBlobStore().Remove(r.ctx, location)
+	}
+}
+
+// removeFile deletes a file from the CDN
+func (r *reconciler) removeFile(name string) func() error {
+	return func() error {
+		return os.Remove(filepath.Join(podops.StorageLocation, r.prod, name))
+	}
+}
+
+// assetRel returns how an asset got into the CDN, based on its inventory entry
+func assetRel(res *podops.Resource) string {
+	if res.EnclosureRel != "" {
+		return res.EnclosureRel
+	}
+	if res.ImageRel != "" {
+		return res.ImageRel
+	}
+	return podops.ResourceTypeLocal
+}
+
+// metadataRel returns how an asset got into the CDN, based on its origin
+func metadataRel(m *metadata.Metadata) string {
+	if isRemote(m.Origin) {
+		return podops.ResourceTypeImport
+	}
+	return podops.ResourceTypeLocal
+}
+
+func isRemote(origin string) bool {
+	return strings.HasPrefix(origin, "http://") || strings.HasPrefix(origin, "https://")
+}
@@ -0,0 +1,97 @@
+package cdn
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/metadata"
+)
+
+func TestReconcileProduction(t *testing.T) {
+	setupInventory(t)
+	ctx := context.TODO()
+
+	assert.NoError(t, backend.UpdateProduction(ctx, &podops.Production{GUID: "prod", Name: "prod"}))
+
+	cdn := filepath.Join(podops.StorageLocation, "prod")
+	os.MkdirAll(cdn, os.ModePerm)
+	// files are older than the grace period unless they are written afterwards
+	old := time.Now().Add(-2 * orphanGracePeriod)
+	write := func(name, content string) {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(cdn, name), []byte(content), 0644))
+		assert.NoError(t, os.Chtimes(filepath.Join(cdn, name), old, old))
+	}
+	register := func(name, content string) *metadata.Metadata {
+		write(name, content)
+		meta, err := metadata.ExtractMetadataFromFile(filepath.Join(cdn, name))
+		if assert.NoError(t, err) {
+			meta.GUID = metadata.FingerprintURI("prod", name)
+			meta.ParentGUID = "prod"
+			assert.NoError(t, backend.UpdateAsset(ctx, meta, "prod", "prod/"+name, podops.ResourceTypeLocal))
+		}
+		return meta
+	}
+
+	// the show's .yaml got lost, its revision did not
+	show := podops.Show{
+		Kind:     podops.ResourceShow,
+		Metadata: podops.Metadata{Name: "prod", Labels: map[string]string{podops.LabelGUID: "prod"}},
+	}
+	_, err := backend.WriteRevision(ctx, "prod", "prod", "test", "prod/show-prod.yaml", true, false, nil, &show)
+	assert.NoError(t, err)
+	assert.NoError(t, backend.UpdateShow(ctx, "prod/show-prod.yaml", &show))
+	assert.NoError(t, backend.DefaultBlobStore().Remove(ctx, "prod/show-prod.yaml"))
+
+	register("intact.mp3", "intact")
+	register("resized.mp3", "original")
+	write("resized.mp3", "something else")
+	meta := register("unindexed.mp3", "unindexed")
+	assert.NoError(t, backend.DeleteMetadata(ctx, meta.GUID))
+	write("halfway.mp3", "halfway")
+	meta, _ = metadata.ExtractMetadataFromFile(filepath.Join(cdn, "halfway.mp3"))
+	meta.GUID = metadata.FingerprintURI("prod", "halfway.mp3")
+	meta.ParentGUID = "prod"
+	assert.NoError(t, backend.UpdateMetadata(ctx, meta))
+	write("purged.mp3", "purged")
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(cdn, "uploading.mp3"), []byte("uploading"), 0644))
+
+	report, err := ReconcileProduction(ctx, "prod", false)
+	if !assert.NoError(t, err) {
+		return
+	}
+	problems := make(map[string]string)
+	for _, issue := range report.Issues {
+		problems[issue.Name] = issue.Store + ":" + issue.Problem
+		assert.False(t, issue.Repaired)
+	}
+	assert.Equal(t, map[string]string{
+		"prod/show-prod.yaml": "bucket:missing",
+		"resized.mp3":         "cdn:size",
+		"unindexed.mp3":       "inventory:orphan",
+		"halfway.mp3":         "inventory:orphan",
+		"purged.mp3":          "cdn:orphan",
+	}, problems)
+
+	report, err = ReconcileProduction(ctx, "prod", true)
+	if assert.NoError(t, err) {
+		assert.Equal(t, len(report.Issues), report.Repaired)
+	}
+	_, err = os.Stat(filepath.Join(cdn, "purged.mp3"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(cdn, "uploading.mp3"))
+	assert.NoError(t, err)
+
+	// everything is in sync again
+	report, err = ReconcileProduction(ctx, "prod", false)
+	if assert.NoError(t, err) {
+		assert.Empty(t, report.Issues)
+	}
+}
@@ -0,0 +1,111 @@
+package cdn
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"path/filepath"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/api"
+	"github.com/txsvc/platform/v2/pkg/authentication"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/apiv1"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/messagedef"
+)
+
+// HubEndpoint implements a minimal WebSub hub for the public feeds. Subscriptions are verified before
+// the request returns. Only the builder can publish, the feed is then sent to each subscriber in a task.
+func HubEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	mode := c.FormValue("hub.mode")
+	switch mode {
+	case "subscribe":
+		lease, _ := strconv.Atoi(c.FormValue("hub.lease_seconds"))
+		s, err := backend.SubscribeHub(ctx, c.FormValue("hub.topic"), c.FormValue("hub.callback"), c.FormValue("hub.secret"), lease)
+		if err != nil {
+			return api.ErrorResponse(c, http.StatusBadRequest, err)
+		}
+
+		// track api access for billing etc
+		platform.Meter(ctx, "cdn.hub.subscribe", "production", s.GUID)
+
+		return c.NoContent(http.StatusAccepted)
+	case "unsubscribe":
+		if err := backend.UnsubscribeHub(ctx, c.FormValue("hub.topic"), c.FormValue("hub.callback")); err != nil {
+			return api.ErrorResponse(c, http.StatusBadRequest, err)
+		}
+		return c.NoContent(http.StatusAccepted)
+	case "publish":
+		if err := apiv1.AuthorizeAccess(ctx, c, authentication.ScopeAPIAdmin); err != nil {
+			return api.ErrorResponse(c, http.StatusUnauthorized, err)
+		}
+
+		topic := c.FormValue("hub.url")
+		if topic == "" {
+			topic = c.FormValue("hub.topic")
+		}
+		prod, err := backend.FindProductionByTopic(ctx, topic)
+		if err != nil {
+			return api.ErrorResponse(c, http.StatusInternalServerError, err)
+		}
+		if prod == nil {
+			return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidTopic)
+		}
+		if err := backend.QueueDistribution(ctx, prod.GUID); err != nil {
+			return api.ErrorResponse(c, http.StatusInternalServerError, err)
+		}
+
+		// track api access for billing etc
+		platform.Meter(ctx, "cdn.hub.publish", "production", prod.GUID)
+
+		return c.NoContent(http.StatusAccepted)
+	}
+
+	return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgParameterIsInvalid, mode))
+}
+
+// DistributeTaskEndpoint sends the current public feed of a production to one subscriber of the built-in hub
+func DistributeTaskEndpoint(c echo.Context) error {
+	var req *podops.HubDeliveryRequest = new(podops.HubDeliveryRequest)
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := c.Bind(req); err != nil {
+		// just report and return, resending will not change anything
+		platform.ReportError(err)
+		return c.NoContent(http.StatusOK)
+	}
+	if err := apiv1.AuthorizeAccess(ctx, c, authentication.ScopeAPIAdmin); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	s, err := backend.GetHubSubscription(ctx, req.SubscriptionID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if s == nil {
+		return c.NoContent(http.StatusOK) // the subscriber unsubscribed in the meantime
+	}
+
+	// the hub is part of the CDN, the feed that was just synced is the one to distribute
+	content, err := ioutil.ReadFile(filepath.Join(podops.StorageLocation, s.GUID, "feed.xml"))
+	if err != nil {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusOK)
+	}
+	if err := backend.DeliverFeed(ctx, s, content); err != nil {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusServiceUnavailable) // the task will be retried
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "cdn.hub.distribute", "production", s.GUID)
+
+	return c.NoContent(http.StatusOK)
+}
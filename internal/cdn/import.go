@@ -2,6 +2,8 @@ package cdn
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
@@ -49,7 +51,8 @@ func ImportTaskEndpoint(c echo.Context) error {
 func ImportResource(ctx context.Context, prod, src string) int {
 	resp, err := http.Get(src)
 	if err != nil {
-		return resp.StatusCode
+		platform.ReportError(err)
+		return http.StatusBadRequest
 	}
 	defer resp.Body.Close()
 
@@ -61,7 +64,7 @@ func ImportResource(ctx context.Context, prod, src string) int {
 	// update the inventory
 	meta := metadata.ExtractMetadataFromResponse(resp)
 
-	meta.Name = metadata.LocalNamePart(metadata.FingerprintWithExt(prod, src))
+	meta.Name = metadata.LocalNamePart(podops.FingerprintWithExt(prod, src))
 	meta.Origin = src
 	meta.GUID = metadata.FingerprintURI(prod, src)
 	meta.ParentGUID = prod
@@ -79,9 +82,10 @@ func ImportResource(ctx context.Context, prod, src string) int {
 	}
 	defer out.Close()
 
-	// transfer using a buffer
+	// transfer using a buffer, hashing the content on the way
 	buffer := make([]byte, 65536)
-	l, err := io.CopyBuffer(out, resp.Body, buffer)
+	h := sha256.New()
+	l, err := io.CopyBuffer(io.MultiWriter(out, h), resp.Body, buffer)
 
 	// error handling & verification
 	if err != nil {
@@ -96,9 +100,13 @@ func ImportResource(ctx context.Context, prod, src string) int {
 	// explicitly close the file here
 	out.Close()
 
-	// calculate the length of an audio file, if it is an audio file
-	if meta.IsAudio() {
-		meta.Duration, _ = metadata.CalculateLength(path)
+	// the origin's etag is replaced with the digest of what the CDN serves
+	meta.Digest = hex.EncodeToString(h.Sum(nil))
+	meta.Etag = meta.ETAG()
+
+	// duration, dimensions etc. of audio, video and images, the content type sent by the origin is not always reliable
+	if info, err := metadata.ProbeFile(path); err == nil {
+		meta.UpdateMediaInfo(info)
 	}
 
 	// update the inventory
@@ -106,6 +114,7 @@ func ImportResource(ctx context.Context, prod, src string) int {
 		platform.ReportError(err)
 		return http.StatusBadRequest
 	}
+	backend.Emit(ctx, prod, podops.EventAssetImported, meta.GUID, meta)
 
 	return http.StatusOK
 }
@@ -0,0 +1,42 @@
+package modules
+
+import (
+	"context"
+	"sync"
+
+	"github.com/txsvc/platform/v2"
+
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/analytics"
+)
+
+const (
+	// requests waiting to be added to the download statistics, more are dropped
+	trackingQueueSize = 1024
+)
+
+var (
+	trackingQueue chan *analytics.Request
+	startTracking sync.Once
+)
+
+// track queues a request for the download statistics. Requests are recorded one by one in the
+// background, this keeps the response times low and avoids conflicting updates of the statistics.
+func track(r *analytics.Request) {
+	startTracking.Do(func() {
+		trackingQueue = make(chan *analytics.Request, trackingQueueSize)
+		go func() {
+			for r := range trackingQueue {
+				if err := backend.RecordRequest(context.Background(), r); err != nil {
+					platform.ReportError(err)
+				}
+			}
+		}()
+	})
+
+	select {
+	case trackingQueue <- r:
+	default:
+		// the statistics are falling behind, don't block the CDN
+	}
+}
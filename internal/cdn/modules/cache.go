@@ -0,0 +1,48 @@
+package modules
+
+import (
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+)
+
+type (
+	// ttlCache is a bounded LRU cache whose entries expire after a fixed period
+	ttlCache struct {
+		entries *lru.Cache
+		ttl     time.Duration
+	}
+
+	ttlEntry struct {
+		value   interface{}
+		expires time.Time
+	}
+)
+
+// newTTLCache returns a cache that keeps at most size entries for ttl
+func newTTLCache(size int, ttl time.Duration) *ttlCache {
+	entries, err := lru.New(size)
+	if err != nil {
+		panic(err) // only if size <= 0
+	}
+	return &ttlCache{entries: entries, ttl: ttl}
+}
+
+// get returns a cached value, expired entries are removed
+func (c *ttlCache) get(key string) (interface{}, bool) {
+	v, ok := c.entries.Get(key)
+	if !ok {
+		return nil, false
+	}
+	e := v.(ttlEntry)
+	if time.Now().After(e.expires) {
+		c.entries.Remove(key)
+		return nil, false
+	}
+	return e.value, true
+}
+
+// add caches a value, evicting the least recently used entry if the cache is full
+func (c *ttlCache) add(key string, value interface{}) {
+	c.entries.Add(key, ttlEntry{value: value, expires: time.Now().Add(c.ttl)})
+}
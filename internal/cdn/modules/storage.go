@@ -2,18 +2,54 @@ package modules
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/caddyserver/caddy/v2"
 	"github.com/caddyserver/caddy/v2/caddyconfig/caddyfile"
 	"github.com/caddyserver/caddy/v2/caddyconfig/httpcaddyfile"
 	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
 	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/analytics"
+	"github.com/podops/podops/internal/metadata"
+	"github.com/podops/podops/internal/transport"
+)
+
+const (
+	// how long a decision to grant or deny access to an asset is cached
+	accessCacheTTL = time.Minute
+	// the maximum number of cached access decisions
+	accessCacheSize = 10000
+	// how long the entity tag of an asset is cached
+	etagCacheTTL = time.Minute
+	// the maximum number of cached entity tags
+	etagCacheSize = 10000
 )
 
 type (
 	StorageModuleImpl struct {
 	}
+
+	// accessCache remembers recent access checks, podcast apps request the same enclosure many times using range requests
+	accessCache struct {
+		entries *ttlCache
+	}
+
+	// etagCache remembers the entity tags of recently requested assets
+	etagCache struct {
+		entries *ttlCache
+	}
+
+	// etagWriter replaces the file server's ETag, based on size and modification time, with the digest of the asset
+	etagWriter struct {
+		*caddyhttp.ResponseWriterWrapper
+		etag        string
+		wroteHeader bool
+	}
 )
 
 var (
@@ -22,6 +58,9 @@ var (
 	_ caddy.Provisioner           = (*StorageModuleImpl)(nil)
 	_ caddyhttp.MiddlewareHandler = (*StorageModuleImpl)(nil)
 	_ caddyfile.Unmarshaler       = (*StorageModuleImpl)(nil)
+
+	access = &accessCache{entries: newTTLCache(accessCacheSize, accessCacheTTL)}
+	etags  = &etagCache{entries: newTTLCache(etagCacheSize, etagCacheTTL)}
 )
 
 func init() {
@@ -31,25 +70,128 @@ func init() {
 
 func (m StorageModuleImpl) ServeHTTP(w http.ResponseWriter, r *http.Request, next caddyhttp.Handler) error {
 
-	parts := strings.Split(r.RequestURI, "/")
+	parts := strings.Split(r.URL.Path, "/")
 	if len(parts) > 2 {
-		// this assumes r.RequestURI starts with a "/" e.g. "/16304cda8338/bc982aa5.mp3"
+		// this assumes r.URL.Path starts with a "/" e.g. "/16304cda8338/bc982aa5.mp3"
 		prod := parts[1]
 		asset := parts[2]
-		userAgent := r.UserAgent()
-		remoteAddr := r.RemoteAddr
-		contentType := "unknown"
-		contentRange := r.Header.Get("Range")
-		size := "0"
+
+		// enclosures of private episodes require a subscriber token
+		if !access.granted(r, prod, asset, r.URL.Query().Get("token")) {
+			w.WriteHeader(http.StatusForbidden)
+			return nil
+		}
+
+		// assets in the inventory have a strong entity tag, the digest of their content
+		if etag := etags.lookup(r, prod, asset); etag != "" {
+			if backend.MatchVersion(strings.Trim(etag, "\""), transport.ParseETags(r.Header.Get(transport.HeaderIfNoneMatch))) {
+				w.Header().Set("Etag", etag)
+				w.WriteHeader(http.StatusNotModified)
+				return nil
+			}
+			w = &etagWriter{ResponseWriterWrapper: &caddyhttp.ResponseWriterWrapper{ResponseWriter: w}, etag: etag}
+		}
+
+		rec := caddyhttp.NewResponseRecorder(w, nil, nil)
+		err := next.ServeHTTP(rec, r)
+
+		req := &analytics.Request{
+			Production: prod,
+			Asset:      asset,
+			UserAgent:  r.UserAgent(),
+			RemoteAddr: r.RemoteAddr,
+			Range:      r.Header.Get("Range"),
+			Status:     rec.Status(),
+			Bytes:      int64(rec.Size()),
+			Timestamp:  timestamp.Now(),
+		}
+		if req.Status == http.StatusPartialContent {
+			req.Size = analytics.SizeFromContentRange(rec.Header().Get("Content-Range"))
+		} else {
+			req.Size = req.Bytes
+		}
+		contentType := rec.Header().Get("Content-Type")
 
 		// track api access for billing etc
-		platform.Meter(platform.NewHttpContext(r), "cdn.storage", "production", prod, "user-agent", userAgent, "remote_addr", remoteAddr, "type", contentType, "range", contentRange, "name", asset, "size", size)
+		platform.Meter(platform.NewHttpContext(r), "cdn.storage", "production", prod, "user-agent", req.UserAgent, "remote_addr", req.RemoteAddr, "type", contentType, "range", req.Range, "name", asset, "size", strconv.FormatInt(req.Bytes, 10), "status", strconv.Itoa(req.Status))
+
+		// only media files count as downloads
+		switch metadata.ClassOf(contentType) {
+		case metadata.MediaClassAudio, metadata.MediaClassVideo:
+			track(req)
+		}
+
+		return err
 	}
 
-	//os.Stdout.Write([]byte(r.RequestURI + "\n"))
 	return next.ServeHTTP(w, r)
 }
 
+// granted returns true if the asset is public or token is a valid subscriber token of the production
+func (c *accessCache) granted(r *http.Request, prod, asset, token string) bool {
+	key := prod + "/" + asset + "/" + token
+
+	if granted, ok := c.entries.get(key); ok {
+		return granted.(bool)
+	}
+
+	ctx := platform.NewHttpContext(r)
+	granted := true
+	private, err := backend.IsPrivateAsset(ctx, prod, asset)
+	if err != nil {
+		platform.ReportError(err)
+		return false // fail closed, but don't cache the decision
+	}
+	if private {
+		if granted, err = backend.ValidSubscription(ctx, prod, token); err != nil {
+			platform.ReportError(err)
+			return false
+		}
+	}
+
+	c.entries.add(key, granted)
+
+	return granted
+}
+
+// lookup returns the entity tag of an asset, or an empty string if the asset is not in the inventory
+func (c *etagCache) lookup(r *http.Request, prod, asset string) string {
+	key := prod + "/" + asset
+
+	if etag, ok := c.entries.get(key); ok {
+		return etag.(string)
+	}
+
+	meta, err := backend.FindAssetMetadata(platform.NewHttpContext(r), prod, asset)
+	if err != nil {
+		platform.ReportError(err)
+		return "" // the file server's ETag will do, but don't cache it
+	}
+	etag := ""
+	if meta != nil {
+		etag = meta.ETAG()
+	}
+
+	c.entries.add(key, etag)
+
+	return etag
+}
+
+func (w *etagWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		w.Header().Set("Etag", w.etag)
+	}
+	w.ResponseWriterWrapper.WriteHeader(status)
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriterWrapper.Write(b)
+}
+
 func (StorageModuleImpl) CaddyModule() caddy.ModuleInfo {
 	return caddy.ModuleInfo{
 		ID:  "http.handlers.podops",
@@ -0,0 +1,262 @@
+package cdn
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/txsvc/platform/v2/pkg/id"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+// uploadExpiration is the time after which an upload that did not receive a chunk is removed from the staging area
+const uploadExpiration = 24 * 3600
+
+type (
+	// upload is an upload in progress. The data received so far is kept next to it in the staging area.
+	upload struct {
+		ID       string `json:"id"`
+		GUID     string `json:"guid"` // the production
+		Name     string `json:"name"`
+		Length   int64  `json:"length"`
+		Checksum string `json:"checksum,omitempty"` // of the complete file, optional
+		Created  int64  `json:"created"`
+		Updated  int64  `json:"updated"`
+	}
+)
+
+var (
+	// uploadLocks serializes the chunks of an upload
+	uploadLocks sync.Map
+)
+
+// createUpload reserves space for a file in the staging area
+func createUpload(prod, name string, length int64, checksum string) (*upload, error) {
+	uploadID, err := id.SimpleUUID()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(podops.UploadLocation, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	now := timestamp.Now()
+	u := upload{
+		ID:       uploadID,
+		GUID:     prod,
+		Name:     name,
+		Length:   length,
+		Checksum: checksum,
+		Created:  now,
+		Updated:  now,
+	}
+	f, err := os.Create(u.dataPath())
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+
+	if err := u.store(); err != nil {
+		os.Remove(u.dataPath())
+		return nil, err
+	}
+	return &u, nil
+}
+
+// getUpload returns an upload in progress, or nil if it does not exist or has expired
+func getUpload(uploadID string) (*upload, error) {
+	if !validUploadID(uploadID) {
+		return nil, nil
+	}
+
+	data, err := ioutil.ReadFile(filepath.Join(podops.UploadLocation, uploadID+".json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var u upload
+	if err := json.Unmarshal(data, &u); err != nil {
+		return nil, err
+	}
+	if u.Updated+uploadExpiration < timestamp.Now() {
+		return nil, removeUpload(uploadID)
+	}
+	return &u, nil
+}
+
+// removeUpload discards an upload and all data received so far
+func removeUpload(uploadID string) error {
+	if !validUploadID(uploadID) {
+		return nil
+	}
+	uploadLocks.Delete(uploadID)
+	os.Remove(filepath.Join(podops.UploadLocation, uploadID))
+	if err := os.Remove(filepath.Join(podops.UploadLocation, uploadID+".json")); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// sweepUploads removes all expired uploads from the staging area
+func sweepUploads() {
+	files, err := filepath.Glob(filepath.Join(podops.UploadLocation, "*.json"))
+	if err != nil {
+		return
+	}
+	for _, f := range files {
+		getUpload(strings.TrimSuffix(filepath.Base(f), ".json")) // removes the upload if it has expired
+	}
+}
+
+// lockUpload returns the lock of an upload, or nil if there is no such upload. Only uploads in the staging area
+// get a lock, i.e. made-up ids can't fill uploadLocks.
+func lockUpload(uploadID string) *sync.Mutex {
+	if !validUploadID(uploadID) {
+		return nil
+	}
+	if _, err := os.Stat(filepath.Join(podops.UploadLocation, uploadID+".json")); err != nil {
+		return nil
+	}
+	l, _ := uploadLocks.LoadOrStore(uploadID, &sync.Mutex{})
+	return l.(*sync.Mutex)
+}
+
+// offset returns the number of bytes received so far
+func (u *upload) offset() (int64, error) {
+	info, err := os.Stat(u.dataPath())
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// writeChunk appends a chunk at offset. The chunk is discarded if it is incomplete or does not match its checksum.
+func (u *upload) writeChunk(offset int64, r io.Reader, checksum string) (int64, error) {
+	current, err := u.offset()
+	if err != nil {
+		return 0, err
+	}
+	if offset != current {
+		return current, errordef.ErrInvalidOffset
+	}
+
+	f, err := os.OpenFile(u.dataPath(), os.O_WRONLY, 0644)
+	if err != nil {
+		return current, err
+	}
+	defer f.Close()
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return current, err
+	}
+
+	h := sha256.New()
+	n, err := io.Copy(io.MultiWriter(f, h), io.LimitReader(r, u.Length-offset))
+	if err == nil && checksum != "" && checksum != podops.FormatChecksum(h.Sum(nil)) {
+		err = errordef.ErrChecksumMismatch
+	}
+	if err != nil {
+		f.Truncate(offset) // the client resends the whole chunk
+		return current, err
+	}
+	if err := f.Close(); err != nil {
+		os.Truncate(u.dataPath(), offset)
+		return current, err
+	}
+
+	u.Updated = timestamp.Now()
+	if err := u.store(); err != nil {
+		return current, err
+	}
+	return offset + n, nil
+}
+
+// commit verifies the complete upload and moves it to its location in the CDN. The upload is removed from the staging area.
+func (u *upload) commit() (string, error) {
+	defer removeUpload(u.ID)
+
+	if u.Checksum != "" {
+		f, err := os.Open(u.dataPath())
+		if err != nil {
+			return "", err
+		}
+		h := sha256.New()
+		_, err = io.Copy(h, f)
+		f.Close()
+		if err != nil {
+			return "", err
+		}
+		if u.Checksum != podops.FormatChecksum(h.Sum(nil)) {
+			return "", errordef.ErrChecksumMismatch
+		}
+	}
+
+	location := u.GUID + "/" + u.Name
+	if err := installFile(u.dataPath(), location); err != nil {
+		return "", err
+	}
+	return location, nil
+}
+
+func (u *upload) store() error {
+	data, err := json.Marshal(u)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(podops.UploadLocation, u.ID+".json"), data, 0644)
+}
+
+func (u *upload) dataPath() string {
+	return filepath.Join(podops.UploadLocation, u.ID)
+}
+
+// installFile atomically replaces the file at location in the CDN with the file at src
+func installFile(src, location string) error {
+	path := filepath.Join(podops.StorageLocation, location)
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	if err := os.Rename(src, path); err == nil {
+		return nil
+	}
+
+	// the staging area is on another volume, copy the file next to its destination first
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := ioutil.TempFile(filepath.Dir(path), ".tmp-")
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Remove(src)
+}
+
+func validUploadID(uploadID string) bool {
+	return uploadID != "" && filepath.Base(uploadID) == uploadID && !strings.ContainsAny(uploadID, ".")
+}
@@ -0,0 +1,119 @@
+package cdn
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+func setupStaging(t *testing.T) {
+	dir := t.TempDir()
+	podops.UploadLocation = filepath.Join(dir, "uploads")
+	podops.StorageLocation = filepath.Join(dir, "cdn")
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return podops.FormatChecksum(sum[:])
+}
+
+func TestResumableUpload(t *testing.T) {
+	setupStaging(t)
+
+	data := []byte("0123456789abcdefghij")
+	u, err := createUpload("prod", "episode.mp3", int64(len(data)), checksum(data))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	n, err := u.writeChunk(0, bytes.NewReader(data[:8]), checksum(data[:8]))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), n)
+
+	// chunks have to continue where the upload stopped
+	_, err = u.writeChunk(4, bytes.NewReader(data[4:12]), "")
+	assert.Equal(t, errordef.ErrInvalidOffset, err)
+
+	// corrupted chunks are discarded
+	n, err = u.writeChunk(8, bytes.NewReader([]byte("XXXXXX")), checksum(data[8:14]))
+	assert.Equal(t, errordef.ErrChecksumMismatch, err)
+	assert.Equal(t, int64(8), n)
+
+	// the upload survives a restart of the CDN
+	u, err = getUpload(u.ID)
+	if !assert.NoError(t, err) || !assert.NotNil(t, u) {
+		return
+	}
+	offset, err := u.offset()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), offset)
+
+	n, err = u.writeChunk(8, bytes.NewReader(data[8:]), checksum(data[8:]))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(len(data)), n)
+
+	location, err := u.commit()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "prod/episode.mp3", location)
+		installed, err := ioutil.ReadFile(filepath.Join(podops.StorageLocation, location))
+		assert.NoError(t, err)
+		assert.Equal(t, data, installed)
+	}
+	u, err = getUpload(u.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, u)
+}
+
+func TestResumableUploadChecksum(t *testing.T) {
+	setupStaging(t)
+
+	data := []byte("0123456789")
+	u, err := createUpload("prod", "episode.mp3", int64(len(data)), checksum([]byte("something else")))
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, err = u.writeChunk(0, bytes.NewReader(data), "")
+	assert.NoError(t, err)
+
+	_, err = u.commit()
+	assert.Equal(t, errordef.ErrChecksumMismatch, err)
+	_, err = ioutil.ReadFile(filepath.Join(podops.StorageLocation, "prod", "episode.mp3"))
+	assert.Error(t, err)
+}
+
+func TestLockUpload(t *testing.T) {
+	setupStaging(t)
+
+	u, err := createUpload("prod", "episode.mp3", 10, "")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotNil(t, lockUpload(u.ID))
+
+	// unknown or malformed ids don't get a lock
+	assert.Nil(t, lockUpload("unknown"))
+	assert.Nil(t, lockUpload("../uploads"))
+	assert.Nil(t, lockUpload(""))
+
+	assert.NoError(t, removeUpload(u.ID))
+	assert.Nil(t, lockUpload(u.ID))
+}
+
+func TestParseUploadMetadata(t *testing.T) {
+	header := "filename " + base64.StdEncoding.EncodeToString([]byte("episode.mp3")) + ",is_confidential"
+	meta := parseUploadMetadata(header)
+
+	assert.Equal(t, "episode.mp3", meta["filename"])
+	_, ok := meta["is_confidential"]
+	assert.True(t, ok)
+	assert.False(t, validAssetName("../episode.mp3"))
+	assert.False(t, validAssetName(".hidden"))
+}
@@ -1,22 +1,24 @@
 package cdn
 
 import (
+	"bytes"
 	"context"
-	"io"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/txsvc/platform/v2"
 	"github.com/txsvc/platform/v2/pkg/api"
 	"github.com/txsvc/platform/v2/pkg/authentication"
-	ds "github.com/txsvc/platform/v2/pkg/datastore"
-	"github.com/txsvc/platform/v2/pkg/validate"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
 
 	"github.com/podops/podops"
 	"github.com/podops/podops/apiv1"
+	"github.com/podops/podops/backend"
 	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/metadata"
 )
 
 // SyncTaskEndpoint syncs files between the cloud storage and the CDN
@@ -44,16 +46,20 @@ func SyncTaskEndpoint(c echo.Context) error {
 	return c.NoContent(status)
 }
 
-// DeleteTaskEndpoint removes a file from the CDN
+// DeleteTaskEndpoint removes a file of a production from the CDN
 func DeleteTaskEndpoint(c echo.Context) error {
 	ctx := platform.NewHttpContext(c.Request())
 
 	prod := c.Param("prod")
 	location := c.QueryParam("l")
 
-	if !validate.NotEmpty(prod, location) {
+	if prod == "" || filepath.Base(prod) != prod {
 		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
 	}
+	// only a single file, never the production's folder or anything outside of it
+	if !validLocation(prod, location) {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidParameters)
+	}
 	if err := apiv1.AuthorizeAccessProduction(ctx, c, authentication.ScopeAPIAdmin, prod); err != nil {
 		// validate against production only, the resource is already gone by now
 		return api.ErrorResponse(c, http.StatusUnauthorized, err)
@@ -63,12 +69,51 @@ func DeleteTaskEndpoint(c echo.Context) error {
 	return c.NoContent(status)
 }
 
-// SyncResource imports a resource from the cloud storage and places it into the CDN
+// PurgeProductionTaskEndpoint removes all files of a production from the CDN once the production was purged
+func PurgeProductionTaskEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" || filepath.Base(prod) != prod || prod == "." {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := apiv1.AuthorizeAccessProduction(ctx, c, authentication.ScopeAPIAdmin, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	// only productions whose time in the trash has ended are purged, anything else is a bad task
+	p, err := backend.GetProduction(ctx, prod)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err) // the task will be retried
+	}
+	if p != nil && (p.Deleted == 0 || p.Expires > timestamp.Now()) {
+		return api.ErrorResponse(c, http.StatusConflict, errordef.ErrInvalidParameters)
+	}
+
+	return c.NoContent(DeleteProduction(ctx, prod))
+}
+
+// validLocation returns true if location is a file in the production's folder on the CDN
+func validLocation(prod, location string) bool {
+	if location == "" {
+		return false
+	}
+	clean := filepath.ToSlash(filepath.Clean(location))
+	if !strings.HasPrefix(clean, prod+"/") || filepath.Base(clean) == prod {
+		return false
+	}
+	fi, err := os.Stat(filepath.Join(podops.StorageLocation, clean))
+	if err != nil {
+		return os.IsNotExist(err) // nothing to do, but not a bad request either
+	}
+	return !fi.IsDir()
+}
+
+// SyncResource imports a resource from the production bucket and places it into the CDN
 func SyncResource(ctx context.Context, prod, src string) int {
 	relPath := prod + "/" + src
 
-	bkt := ds.Storage().Bucket(podops.BucketProduction)
-	reader, err := bkt.Object(relPath).NewReader(ctx)
+	data, err := backend.DefaultBlobStore().Read(ctx, relPath)
 	if err != nil {
 		platform.ReportError(err)
 		return http.StatusBadRequest
@@ -85,20 +130,56 @@ func SyncResource(ctx context.Context, prod, src string) int {
 	defer out.Close()
 
 	// transfer the file
-	_, err = io.Copy(out, reader)
+	_, err = out.Write(data)
 	if err != nil {
 		platform.ReportError(err)
 		return http.StatusBadRequest
 	}
+	out.Close()
+
+	// keep the digest in the inventory current if the file is an asset
+	if err := updateDigest(ctx, prod, src, data); err != nil {
+		platform.ReportError(err)
+	}
+
+	// the new feed is live, subscribers of the hubs get it right away
+	if src == "feed.xml" {
+		backend.NotifyHubs(ctx, prod)
+	}
 
 	return http.StatusOK
 }
 
+// updateDigest records the digest of a file in the inventory, if it has an entry
+func updateDigest(ctx context.Context, prod, src string, data []byte) error {
+	meta, err := backend.GetMetadata(ctx, metadata.FingerprintURI(prod, src))
+	if err != nil || meta == nil {
+		return err
+	}
+	digest, _ := metadata.Digest(bytes.NewReader(data))
+	if meta.Digest == digest {
+		return nil
+	}
+	meta.Digest = digest
+	meta.Size = int64(len(data))
+	meta.Etag = meta.ETAG()
+	return backend.UpdateMetadata(ctx, meta)
+}
+
+// DeleteProduction removes all files of a production from the CDN
+func DeleteProduction(ctx context.Context, prod string) int {
+	if err := os.RemoveAll(filepath.Join(podops.StorageLocation, prod)); err != nil {
+		platform.ReportError(err)
+		return http.StatusInternalServerError
+	}
+	return http.StatusOK
+}
+
 // DeleteResource removes a resource from the CDN
 func DeleteResource(ctx context.Context, location string) int {
 	path := filepath.Join(podops.StorageLocation, location)
 	err := os.Remove(path)
-	if err != nil {
+	if err != nil && !os.IsNotExist(err) {
 		return http.StatusInternalServerError
 	}
 	return http.StatusOK
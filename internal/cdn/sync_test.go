@@ -0,0 +1,31 @@
+package cdn
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+)
+
+func TestValidLocation(t *testing.T) {
+	setupStaging(t)
+
+	os.MkdirAll(filepath.Join(podops.StorageLocation, "prod", "folder"), os.ModePerm)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(podops.StorageLocation, "prod", "episode.mp3"), []byte("data"), 0644))
+
+	assert.True(t, validLocation("prod", "prod/episode.mp3"))
+	assert.True(t, validLocation("prod", "prod/gone.mp3"))
+
+	// never the production's folder, a sub-folder or another production
+	assert.False(t, validLocation("prod", ""))
+	assert.False(t, validLocation("prod", "prod"))
+	assert.False(t, validLocation("prod", "prod/"))
+	assert.False(t, validLocation("prod", "prod/."))
+	assert.False(t, validLocation("prod", "prod/folder"))
+	assert.False(t, validLocation("prod", "prod/../other/episode.mp3"))
+	assert.False(t, validLocation("prod", "other/episode.mp3"))
+}
@@ -1,11 +1,16 @@
 package cdn
 
 import (
+	"context"
+	"encoding/base64"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/labstack/echo/v4"
 	"github.com/txsvc/platform/v2"
@@ -15,7 +20,9 @@ import (
 	"github.com/podops/podops/apiv1"
 	"github.com/podops/podops/backend"
 	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/messagedef"
 	"github.com/podops/podops/internal/metadata"
+	"github.com/podops/podops/internal/transport"
 )
 
 // UploadEndpoint implements content upload
@@ -34,6 +41,9 @@ func UploadEndpoint(c echo.Context) error {
 	if err := apiv1.AuthorizeAccessProduction(ctx, c, apiv1.ScopeResourceWrite, prod); err != nil {
 		return api.ErrorResponse(c, http.StatusUnauthorized, err)
 	}
+	if err := apiv1.AssertWritable(ctx, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusConflict, err)
+	}
 
 	for {
 		part, err := mr.NextPart()
@@ -45,39 +55,277 @@ func UploadEndpoint(c echo.Context) error {
 		}
 
 		if part.FormName() == "asset" {
-			location := fmt.Sprintf("%s/%s", prod, part.FileName())
-			path := filepath.Join(podops.StorageLocation, location)
+			name := filepath.Base(part.FileName())
+			if !validAssetName(name) {
+				return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgParameterIsInvalid, part.FileName()))
+			}
 
-			os.MkdirAll(filepath.Dir(path), os.ModePerm) // make sure sub-folders exist
-			out, err := os.Create(path)
+			// receive the file in the staging area, a dropped connection must not leave a partial file in the CDN
+			os.MkdirAll(podops.UploadLocation, os.ModePerm)
+			out, err := ioutil.TempFile(podops.UploadLocation, ".upload-")
 			if err != nil {
 				return api.ErrorResponse(c, http.StatusInternalServerError, err)
 			}
-			defer out.Close()
-
-			if _, err := io.Copy(out, part); err != nil {
+			_, err = io.Copy(out, part)
+			out.Close()
+			if err != nil {
+				os.Remove(out.Name())
 				return api.ErrorResponse(c, http.StatusInternalServerError, err)
 			}
-			out.Close() // force close to have attributes like size etc correct
 
-			// extract the metadata from the file
-			meta, err := metadata.ExtractMetadataFromFile(path)
-			if err != nil {
+			location := fmt.Sprintf("%s/%s", prod, name)
+			if err := installFile(out.Name(), location); err != nil {
+				os.Remove(out.Name())
 				return api.ErrorResponse(c, http.StatusInternalServerError, err)
 			}
-			meta.GUID = metadata.FingerprintURI(prod, meta.Name)
-			meta.ParentGUID = prod
-			meta.Origin = location
-
-			// update the inventory
-			if err := backend.UpdateAsset(ctx, meta, prod, location, podops.ResourceTypeLocal); err != nil {
+			meta, err := registerAsset(ctx, prod, location)
+			if err != nil {
 				return api.ErrorResponse(c, http.StatusInternalServerError, err)
 			}
-
-			// track api access for billing etc
-			platform.Meter(ctx, "api.upload", "production", meta.ParentGUID, "resource", meta.GUID)
+			setETag(c, meta)
 		}
 	}
 
 	return c.NoContent(http.StatusCreated)
 }
+
+// CreateUploadEndpoint starts a resumable upload. The file's name and, optionally, its checksum are sent as
+// tus Upload-Metadata. The Location header of the response is the address of the upload.
+func CreateUploadEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := apiv1.AuthorizeAccessProduction(ctx, c, apiv1.ScopeResourceWrite, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	if err := apiv1.AssertWritable(ctx, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusConflict, err)
+	}
+
+	c.Response().Header().Set(podops.HeaderTusResumable, podops.TusVersion)
+
+	length, err := strconv.ParseInt(c.Request().Header.Get(podops.HeaderUploadLength), 10, 64)
+	if err != nil || length <= 0 {
+		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgParameterIsInvalid, podops.HeaderUploadLength))
+	}
+	meta := parseUploadMetadata(c.Request().Header.Get(podops.HeaderUploadMetadata))
+	name := meta["filename"]
+	if !validAssetName(name) {
+		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgParameterIsInvalid, name))
+	}
+	checksum := meta["checksum"]
+	if checksum != "" && !strings.HasPrefix(checksum, podops.ChecksumAlgorithm+" ") {
+		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgParameterIsInvalid, checksum))
+	}
+
+	sweepUploads()
+
+	u, err := createUpload(prod, name, length, checksum)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.upload.create", "production", prod)
+
+	c.Response().Header().Set("Location", fmt.Sprintf("%s/uploads/%s/%s", apiv1.WebhookNamespacePrefix, prod, u.ID))
+	return c.NoContent(http.StatusCreated)
+}
+
+// UploadOffsetEndpoint returns the number of bytes of an upload the CDN has received so far
+func UploadOffsetEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	uploadID := c.Param("id")
+	if prod == "" || !validUploadID(uploadID) {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := apiv1.AuthorizeAccessProduction(ctx, c, apiv1.ScopeResourceWrite, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	c.Response().Header().Set(podops.HeaderTusResumable, podops.TusVersion)
+	c.Response().Header().Set("Cache-Control", "no-store")
+
+	u, err := getUpload(uploadID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if u == nil || u.GUID != prod {
+		return c.NoContent(http.StatusNotFound)
+	}
+	offset, err := u.offset()
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	c.Response().Header().Set(podops.HeaderUploadOffset, strconv.FormatInt(offset, 10))
+	c.Response().Header().Set(podops.HeaderUploadLength, strconv.FormatInt(u.Length, 10))
+	return c.NoContent(http.StatusOK)
+}
+
+// UploadChunkEndpoint appends a chunk to an upload. The chunk's Upload-Checksum is verified if present.
+// The last chunk completes the upload, the file is moved into the CDN and added to the inventory.
+func UploadChunkEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	uploadID := c.Param("id")
+	if prod == "" || !validUploadID(uploadID) {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := apiv1.AuthorizeAccessProduction(ctx, c, apiv1.ScopeResourceWrite, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	if err := apiv1.AssertWritable(ctx, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusConflict, err)
+	}
+
+	c.Response().Header().Set(podops.HeaderTusResumable, podops.TusVersion)
+
+	if c.Request().Header.Get("Content-Type") != podops.ContentTypeOffsetStream {
+		return c.NoContent(http.StatusUnsupportedMediaType)
+	}
+	offset, err := strconv.ParseInt(c.Request().Header.Get(podops.HeaderUploadOffset), 10, 64)
+	if err != nil || offset < 0 {
+		return api.ErrorResponse(c, http.StatusBadRequest, fmt.Errorf(messagedef.MsgParameterIsInvalid, podops.HeaderUploadOffset))
+	}
+
+	lock := lockUpload(uploadID)
+	if lock == nil {
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchUpload)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
+	u, err := getUpload(uploadID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if u == nil || u.GUID != prod {
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchUpload)
+	}
+
+	offset, err = u.writeChunk(offset, c.Request().Body, c.Request().Header.Get(podops.HeaderUploadChecksum))
+	c.Response().Header().Set(podops.HeaderUploadOffset, strconv.FormatInt(offset, 10))
+	if err != nil {
+		switch err {
+		case errordef.ErrInvalidOffset:
+			return api.ErrorResponse(c, http.StatusConflict, err)
+		case errordef.ErrChecksumMismatch:
+			return api.ErrorResponse(c, podops.StatusChecksumMismatch, err)
+		}
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if offset < u.Length {
+		return c.NoContent(http.StatusNoContent)
+	}
+
+	// the upload is complete
+	location, err := u.commit()
+	if err != nil {
+		if err == errordef.ErrChecksumMismatch {
+			return api.ErrorResponse(c, podops.StatusChecksumMismatch, err)
+		}
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	meta, err := registerAsset(ctx, prod, location)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	// the client compares the digest with its own
+	setETag(c, meta)
+	return c.NoContent(http.StatusNoContent)
+}
+
+// setETag returns the digest of an asset as its entity tag, if the digest is known
+func setETag(c echo.Context, meta *metadata.Metadata) {
+	if etag := meta.ETAG(); etag != "" {
+		c.Response().Header().Set(transport.HeaderETag, etag)
+	}
+}
+
+// DeleteUploadEndpoint cancels an upload and discards all data received so far
+func DeleteUploadEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	uploadID := c.Param("id")
+	if prod == "" || !validUploadID(uploadID) {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := apiv1.AuthorizeAccessProduction(ctx, c, apiv1.ScopeResourceWrite, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	c.Response().Header().Set(podops.HeaderTusResumable, podops.TusVersion)
+
+	lock := lockUpload(uploadID)
+	if lock == nil {
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchUpload)
+	}
+	lock.Lock()
+	defer lock.Unlock()
+
+	u, err := getUpload(uploadID)
+	if err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if u == nil || u.GUID != prod {
+		return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchUpload)
+	}
+	if err := removeUpload(u.ID); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// registerAsset adds a file in the CDN to the inventory
+func registerAsset(ctx context.Context, prod, location string) (*metadata.Metadata, error) {
+	// extract the metadata from the file, including the digest of its content
+	meta, err := metadata.ExtractMetadataFromFile(filepath.Join(podops.StorageLocation, location))
+	if err != nil {
+		return nil, err
+	}
+	meta.GUID = metadata.FingerprintURI(prod, meta.Name)
+	meta.ParentGUID = prod
+	meta.Origin = location
+
+	// update the inventory
+	if err := backend.UpdateAsset(ctx, meta, prod, location, podops.ResourceTypeLocal); err != nil {
+		return nil, err
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "api.upload", "production", meta.ParentGUID, "resource", meta.GUID)
+
+	return meta, nil
+}
+
+// parseUploadMetadata decodes the tus Upload-Metadata header, a comma-separated list of keys and base64 encoded values
+func parseUploadMetadata(header string) map[string]string {
+	meta := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		kv := strings.SplitN(strings.TrimSpace(pair), " ", 2)
+		if kv[0] == "" {
+			continue
+		}
+		if len(kv) == 1 {
+			meta[kv[0]] = ""
+			continue
+		}
+		if v, err := base64.StdEncoding.DecodeString(kv[1]); err == nil {
+			meta[kv[0]] = string(v)
+		}
+	}
+	return meta
+}
+
+func validAssetName(name string) bool {
+	return name != "" && name != "." && name != ".." && !strings.HasPrefix(name, ".") && !strings.ContainsAny(name, "/\\")
+}
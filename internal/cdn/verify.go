@@ -0,0 +1,170 @@
+package cdn
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/txsvc/platform/v2"
+	"github.com/txsvc/platform/v2/pkg/api"
+	"github.com/txsvc/platform/v2/pkg/authentication"
+	"github.com/txsvc/platform/v2/pkg/timestamp"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/apiv1"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/metadata"
+)
+
+// VerifyEndpoint re-hashes the files of a production on the CDN and reports corruption or drift.
+// POST queues the check as a task, GET returns the report of the latest check.
+func VerifyEndpoint(c echo.Context) error {
+	ctx := platform.NewHttpContext(c.Request())
+
+	prod := c.Param("prod")
+	if prod == "" || filepath.Base(prod) != prod {
+		return api.ErrorResponse(c, http.StatusBadRequest, errordef.ErrInvalidRoute)
+	}
+	if err := apiv1.AuthorizeAccessProduction(ctx, c, authentication.ScopeAPIAdmin, prod); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+
+	if c.Request().Method == http.MethodGet {
+		var report podops.IntegrityReport
+		found, err := backend.ReadReport(ctx, prod, backend.CheckVerify, &report)
+		if err != nil {
+			return api.ErrorResponse(c, http.StatusInternalServerError, err)
+		}
+		if !found {
+			return api.ErrorResponse(c, http.StatusNotFound, errordef.ErrNoSuchObject)
+		}
+		return api.StandardResponse(c, http.StatusOK, &report)
+	}
+
+	// the pending report tells the client that the check is running
+	report := podops.IntegrityReport{
+		GUID:    prod,
+		Created: timestamp.Now(),
+		Issues:  make([]*podops.IntegrityIssue, 0),
+	}
+	if err := backend.WriteReport(ctx, prod, backend.CheckVerify, &report); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+	if err := backend.QueueVerification(ctx, prod, report.Created); err != nil {
+		return api.ErrorResponse(c, http.StatusInternalServerError, err)
+	}
+
+	// track api access for billing etc
+	platform.Meter(ctx, "cdn.verify", "production", prod)
+
+	return api.StandardResponse(c, http.StatusAccepted, &report)
+}
+
+// VerifyTaskEndpoint runs a check queued by VerifyEndpoint and stores its report
+func VerifyTaskEndpoint(c echo.Context) error {
+	var req *podops.CheckRequest = new(podops.CheckRequest)
+	ctx := platform.NewHttpContext(c.Request())
+
+	if err := c.Bind(req); err != nil {
+		// just report and return, resending will not change anything
+		platform.ReportError(err)
+		return c.NoContent(http.StatusOK)
+	}
+	if err := apiv1.AuthorizeAccess(ctx, c, authentication.ScopeAPIAdmin); err != nil {
+		return api.ErrorResponse(c, http.StatusUnauthorized, err)
+	}
+	if req.GUID == "" || filepath.Base(req.GUID) != req.GUID {
+		platform.ReportError(errordef.ErrInvalidParameters)
+		return c.NoContent(http.StatusOK)
+	}
+
+	report, err := VerifyProduction(ctx, req.GUID)
+	if err != nil {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusServiceUnavailable) // the task will be retried
+	}
+	report.Created = req.Created
+	report.Finished = timestamp.Now()
+	if err := backend.WriteReport(ctx, req.GUID, backend.CheckVerify, report); err != nil {
+		platform.ReportError(err)
+		return c.NoContent(http.StatusServiceUnavailable)
+	}
+
+	return c.NoContent(http.StatusOK)
+}
+
+// VerifyProduction compares the files of a production on the CDN with their digests in the inventory
+// and with their copies in the production bucket.
+func VerifyProduction(ctx context.Context, prod string) (*podops.IntegrityReport, error) {
+	report := podops.IntegrityReport{
+		GUID:   prod,
+		Issues: make([]*podops.IntegrityIssue, 0),
+	}
+
+	meta, err := backend.ListMetadata(ctx, prod)
+	if err != nil {
+		return nil, err
+	}
+
+	// everything in the inventory has to be on the CDN, unchanged
+	tracked := make(map[string]bool)
+	for _, m := range meta {
+		tracked[m.Name] = true
+		report.Checked++
+
+		digest, err := metadata.DigestFile(filepath.Join(podops.StorageLocation, prod, m.Name))
+		if err != nil {
+			if os.IsNotExist(err) {
+				report.Issues = append(report.Issues, &podops.IntegrityIssue{Name: m.Name, Problem: podops.IntegrityMissing, Expected: m.Digest})
+				continue
+			}
+			return nil, err
+		}
+		if m.Digest == "" {
+			report.Issues = append(report.Issues, &podops.IntegrityIssue{Name: m.Name, Problem: podops.IntegrityUnverified, Actual: digest})
+		} else if m.Digest != digest {
+			report.Issues = append(report.Issues, &podops.IntegrityIssue{Name: m.Name, Problem: podops.IntegrityCorrupted, Expected: m.Digest, Actual: digest})
+		}
+	}
+
+	// everything else on the CDN was synced from the production bucket
+	files, err := ioutil.ReadDir(filepath.Join(podops.StorageLocation, prod))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &report, nil
+		}
+		return nil, err
+	}
+	for _, fi := range files {
+		name := fi.Name()
+		if fi.IsDir() || strings.HasPrefix(name, ".") || tracked[name] {
+			continue // skip sub-folders and files that are still being written
+		}
+		report.Checked++
+
+		digest, err := metadata.DigestFile(filepath.Join(podops.StorageLocation, prod, name))
+		if err != nil {
+			return nil, err
+		}
+		data, err := backend.DefaultBlobStore().Read(ctx, prod+"/"+name)
+		if err != nil {
+			if err == errordef.ErrNoSuchObject {
+				report.Issues = append(report.Issues, &podops.IntegrityIssue{Name: name, Problem: podops.IntegrityUntracked, Actual: digest})
+				continue
+			}
+			return nil, err
+		}
+		expected, _ := metadata.Digest(bytes.NewReader(data))
+		if expected != digest {
+			report.Issues = append(report.Issues, &podops.IntegrityIssue{Name: name, Problem: podops.IntegrityDrift, Expected: expected, Actual: digest})
+		}
+	}
+
+	return &report, nil
+}
@@ -0,0 +1,72 @@
+package cdn
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/metadata"
+)
+
+func setupInventory(t *testing.T) {
+	setupStaging(t)
+	dir := t.TempDir()
+
+	r, err := backend.NewLocalRepository(filepath.Join(dir, "inventory.db"))
+	if assert.NoError(t, err) {
+		backend.RegisterStore(r, backend.NewLocalBlobStore(filepath.Join(dir, "bucket")))
+	}
+}
+
+func TestVerifyProduction(t *testing.T) {
+	setupInventory(t)
+	ctx := context.TODO()
+
+	cdn := filepath.Join(podops.StorageLocation, "prod")
+	os.MkdirAll(cdn, os.ModePerm)
+	write := func(name, content string) {
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(cdn, name), []byte(content), 0644))
+	}
+	register := func(name, content string) {
+		write(name, content)
+		meta, err := metadata.ExtractMetadataFromFile(filepath.Join(cdn, name))
+		if assert.NoError(t, err) {
+			meta.GUID = metadata.FingerprintURI("prod", name)
+			meta.ParentGUID = "prod"
+			assert.NoError(t, backend.UpdateMetadata(ctx, meta))
+		}
+	}
+
+	register("intact.mp3", "intact")
+	register("corrupted.mp3", "original")
+	write("corrupted.mp3", "bit rot")
+	register("missing.mp3", "missing")
+	os.Remove(filepath.Join(cdn, "missing.mp3"))
+	write("feed.xml", "<rss/>")
+	assert.NoError(t, backend.DefaultBlobStore().Write(ctx, "prod/feed.xml", []byte("<rss></rss>")))
+	write("stray.mp3", "stray")
+	write(".upload-123", "partial")
+
+	report, err := VerifyProduction(ctx, "prod")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, 5, report.Checked)
+
+	problems := make(map[string]string)
+	for _, issue := range report.Issues {
+		problems[issue.Name] = issue.Problem
+	}
+	assert.Equal(t, map[string]string{
+		"corrupted.mp3": podops.IntegrityCorrupted,
+		"missing.mp3":   podops.IntegrityMissing,
+		"feed.xml":      podops.IntegrityDrift,
+		"stray.mp3":     podops.IntegrityUntracked,
+	}, problems)
+}
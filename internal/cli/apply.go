@@ -0,0 +1,405 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v2"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/loader"
+	"github.com/podops/podops/internal/messagedef"
+)
+
+const (
+	// the steps of 'po apply'
+	applyCreate = iota
+	applyUpdate
+	applyUnchanged
+	applyPrune
+)
+
+type (
+	// localResource is a show or episode read from a file or URL
+	localResource struct {
+		source string
+		kind   string
+		guid   string
+		rsrc   interface{}
+	}
+
+	// applyStep is what 'po apply' does with a single resource
+	applyStep struct {
+		action  int
+		kind    string
+		guid    string
+		version string // the server copy the update is based on
+		diff    string
+		local   *localResource
+	}
+
+	// remoteFunc returns the server copy of a resource and its version, or nil if there is none
+	remoteFunc func(kind, guid string) (interface{}, string, error)
+)
+
+// ApplyCommand creates or updates all resources from a file, directory or URL that differ from the server copy
+func ApplyCommand(c *cli.Context) error {
+	src := c.String("filename")
+	if src == "" {
+		return fmt.Errorf(messagedef.MsgArgumentMissing, "filename")
+	}
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	resources, err := loadResources(src)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+	if len(resources) == 0 {
+		printMsg(messagedef.MsgNoResourcesFound)
+		return nil
+	}
+
+	var episodes []*podops.Resource
+	if c.Bool("prune") {
+		l, err := client.Resources(prod, podops.ResourceEpisode)
+		if err != nil {
+			printError(c, err)
+			return nil
+		}
+		episodes = l.Resources
+	}
+
+	steps, err := planApply(prod, resources, episodes, func(kind, guid string) (interface{}, string, error) {
+		return remoteResource(prod, kind, guid)
+	})
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	if c.Bool("dry-run") {
+		for _, s := range steps {
+			fmt.Print(s.diff)
+			printMsg(dryRunMessages[s.action], resourceName(prod, s.kind, s.guid))
+		}
+		return nil
+	}
+
+	// moving episodes to the trash has to be confirmed
+	if pruned := countSteps(steps, applyPrune); pruned > 0 && !c.Bool("yes") {
+		for _, s := range steps {
+			if s.action == applyPrune {
+				printMsg(messagedef.MsgResourceWouldTrash, resourceName(prod, s.kind, s.guid))
+			}
+		}
+		if !confirm(fmt.Sprintf(messagedef.MsgApplyConfirmPrune, pruned)) {
+			printMsg(messagedef.MsgApplyAborted)
+			return nil
+		}
+	}
+
+	for _, s := range steps {
+		name := resourceName(prod, s.kind, s.guid)
+
+		switch s.action {
+		case applyCreate:
+			version, err := client.CreateResource(prod, s.kind, s.guid, false, s.local.rsrc)
+			if err != nil {
+				printError(c, err)
+				return nil
+			}
+			storeVersion(s.guid, version)
+			printMsg(messagedef.MsgResourceCreated, name)
+		case applyUnchanged:
+			storeVersion(s.guid, s.version)
+			printMsg(messagedef.MsgResourceUnchanged, name)
+		case applyUpdate:
+			// the update only succeeds if the server copy is still the one we compared against
+			fmt.Print(s.diff)
+			version, err := client.UpdateResource(prod, s.kind, s.guid, s.version, false, s.local.rsrc)
+			if err == errordef.ErrPreconditionFailed {
+				printMsg(messagedef.MsgResourceChanged, name, s.guid)
+				return nil
+			}
+			if err != nil {
+				printError(c, err)
+				return nil
+			}
+			storeVersion(s.guid, version)
+			printMsg(messagedef.MsgResourceUpdated, name)
+		case applyPrune:
+			status, err := client.DeleteResource(prod, s.kind, s.guid, "")
+			if err != nil || status != http.StatusNoContent {
+				printMsg(messagedef.MsgResourceDeletingError, name)
+				continue
+			}
+			storeVersion(s.guid, "")
+			printMsg(messagedef.MsgResourceTrashed, name, s.guid)
+		}
+	}
+
+	return nil
+}
+
+var dryRunMessages = map[int]string{
+	applyCreate:    messagedef.MsgResourceWouldCreate,
+	applyUpdate:    messagedef.MsgResourceWouldUpdate,
+	applyUnchanged: messagedef.MsgResourceUnchanged,
+	applyPrune:     messagedef.MsgResourceWouldTrash,
+}
+
+// planApply compares the local resources with their server copies and decides what to do with each of them.
+// Episodes on the server that are missing locally are moved to the trash, pass nil to keep them.
+func planApply(prod string, resources []*localResource, episodes []*podops.Resource, remote remoteFunc) ([]*applyStep, error) {
+	steps := make([]*applyStep, 0, len(resources))
+	local := make(map[string]bool)
+
+	for _, r := range resources {
+		local[r.guid] = true
+
+		if parent := resourceParent(r); parent != prod {
+			return nil, fmt.Errorf(messagedef.MsgParameterMismatch, prod, parent)
+		}
+
+		s := &applyStep{kind: r.kind, guid: r.guid, local: r}
+		rsrc, version, err := remote(r.kind, r.guid)
+		if err != nil {
+			return nil, err
+		}
+		if rsrc == nil {
+			s.action = applyCreate
+			steps = append(steps, s)
+			continue
+		}
+
+		diff, err := diffResource(r, rsrc)
+		if err != nil {
+			return nil, err
+		}
+		s.version = version
+		s.diff = diff
+		s.action = applyUnchanged
+		if diff != "" {
+			s.action = applyUpdate
+		}
+		steps = append(steps, s)
+	}
+
+	for _, e := range episodes {
+		if !local[e.GUID] {
+			steps = append(steps, &applyStep{action: applyPrune, kind: podops.ResourceEpisode, guid: e.GUID})
+		}
+	}
+	return steps, nil
+}
+
+// countSteps returns the number of steps with a given action
+func countSteps(steps []*applyStep, action int) int {
+	n := 0
+	for _, s := range steps {
+		if s.action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// confirm asks a yes/no question on the terminal, anything but 'y' or 'yes' is a no
+func confirm(question string) bool {
+	fmt.Print(question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func resourceName(prod, kind, guid string) string {
+	return fmt.Sprintf("%s/%s-%s", prod, kind, guid)
+}
+
+// loadResources reads all shows and episodes from a file, a directory or a URL. Shows come first.
+func loadResources(src string) ([]*localResource, error) {
+	var docs []*localResource
+
+	if strings.HasPrefix(src, "http://") || strings.HasPrefix(src, "https://") {
+		resp, err := http.Get(src)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf(messagedef.MsgResourceImportError, src)
+		}
+		data, err := ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return nil, err
+		}
+		docs, err = unmarshalResources(src, data)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		fi, err := os.Stat(src)
+		if err != nil {
+			return nil, err
+		}
+
+		files := []string{src}
+		if fi.IsDir() {
+			shows, _ := filepath.Glob(filepath.Join(src, "show-*.yaml"))
+			episodes, _ := filepath.Glob(filepath.Join(src, "episode-*.yaml"))
+			files = append(shows, episodes...)
+		}
+
+		for _, f := range files {
+			data, err := ioutil.ReadFile(f)
+			if err != nil {
+				return nil, err
+			}
+			d, err := unmarshalResources(f, data)
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, d...)
+		}
+	}
+
+	// the show has to exist before its episodes
+	resources := make([]*localResource, 0, len(docs))
+	for _, d := range docs {
+		if d.kind == podops.ResourceShow {
+			resources = append(resources, d)
+		}
+	}
+	for _, d := range docs {
+		if d.kind != podops.ResourceShow {
+			resources = append(resources, d)
+		}
+	}
+	return resources, nil
+}
+
+// unmarshalResources reads one or more resources separated by '---'
+func unmarshalResources(source string, data []byte) ([]*localResource, error) {
+	var resources []*localResource
+
+	for _, doc := range bytes.Split(data, []byte("\n---")) {
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		r, kind, guid, err := loader.UnmarshalResource(doc)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, &localResource{
+			source: source,
+			kind:   kind,
+			guid:   guid,
+			rsrc:   r,
+		})
+	}
+	return resources, nil
+}
+
+// remoteResource retrieves the server copy of a show or episode and its version. A resource that doesn't exist is nil.
+func remoteResource(prod, kind, guid string) (interface{}, string, error) {
+	var rsrc interface{}
+
+	switch kind {
+	case podops.ResourceShow:
+		rsrc = &podops.Show{}
+	case podops.ResourceEpisode:
+		rsrc = &podops.Episode{}
+	default:
+		return nil, "", fmt.Errorf(messagedef.MsgResourceUnsupportedKind, kind)
+	}
+
+	version, err := client.GetResource(prod, kind, guid, rsrc)
+	if err == errordef.ErrNoSuchResource {
+		return nil, "", nil // the resource is new
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	return rsrc, version, nil
+}
+
+// resourceParent returns the production a show or episode belongs to
+func resourceParent(r *localResource) string {
+	switch rsrc := r.rsrc.(type) {
+	case *podops.Show:
+		return rsrc.GUID()
+	case *podops.Episode:
+		return rsrc.Parent()
+	}
+	return ""
+}
+
+// diffResource returns a unified diff between the server copy and the local resource, or an empty string if they match
+func diffResource(local *localResource, remote interface{}) (string, error) {
+	switch rsrc := local.rsrc.(type) {
+	case *podops.Show:
+		normalizeShow(rsrc, remote.(*podops.Show))
+	case *podops.Episode:
+		normalizeEpisode(rsrc, remote.(*podops.Episode))
+	}
+
+	a, err := yaml.Marshal(remote)
+	if err != nil {
+		return "", err
+	}
+	b, err := yaml.Marshal(local.rsrc)
+	if err != nil {
+		return "", err
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(a)),
+		B:        difflib.SplitLines(string(b)),
+		FromFile: fmt.Sprintf("server/%s-%s", local.kind, local.guid),
+		ToFile:   local.source,
+		Context:  3,
+	})
+}
+
+// normalizeShow reverts the attributes the API resolves on its own, so that only real changes are reported
+func normalizeShow(local, remote *podops.Show) {
+	normalizeAsset(&local.Image, &remote.Image, local.GUID())
+}
+
+// normalizeEpisode reverts the attributes the API resolves or calculates on its own, so that only real changes are reported
+func normalizeEpisode(local, remote *podops.Episode) {
+	normalizeAsset(&local.Image, &remote.Image, local.Parent())
+	normalizeAsset(&local.Enclosure, &remote.Enclosure, local.Parent())
+	remote.Enclosure.Size = local.Enclosure.Size
+	remote.Description.Duration = local.Description.Duration
+
+	if len(local.Transcripts) == len(remote.Transcripts) {
+		for i := range local.Transcripts {
+			normalizeAsset(&local.Transcripts[i], &remote.Transcripts[i], local.Parent())
+		}
+	}
+	if local.Chapters != nil && remote.Chapters != nil {
+		normalizeAsset(local.Chapters, remote.Chapters, local.Parent())
+	}
+}
+
+func normalizeAsset(local, remote *podops.Asset, parent string) {
+	if local.ResolveURI(podops.DefaultStorageEndpoint, parent) == remote.URI {
+		remote.URI = local.URI
+	}
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+)
+
+func testShow() *podops.Show {
+	return podops.DefaultShow("simple-podcast", "title", "summary", "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+}
+
+func testEpisode(name, guid string) *podops.Episode {
+	return podops.DefaultEpisode(name, "simple-podcast", guid, "show-guid", podops.DefaultEndpoint, podops.DefaultCDNEndpoint)
+}
+
+func TestPlanApply(t *testing.T) {
+	changed := testEpisode("episode1", "episode-guid-1")
+	changed.Description.Title = "a new title"
+
+	resources := []*localResource{
+		{source: "show.yaml", kind: podops.ResourceShow, guid: "show-guid", rsrc: testShow()},
+		{source: "episode1.yaml", kind: podops.ResourceEpisode, guid: "episode-guid-1", rsrc: changed},
+		{source: "episode2.yaml", kind: podops.ResourceEpisode, guid: "episode-guid-2", rsrc: testEpisode("episode2", "episode-guid-2")},
+	}
+	server := map[string]interface{}{
+		"show-guid":      testShow(),
+		"episode-guid-1": testEpisode("episode1", "episode-guid-1"),
+		"episode-guid-3": testEpisode("episode3", "episode-guid-3"),
+	}
+	remote := func(kind, guid string) (interface{}, string, error) {
+		if r, ok := server[guid]; ok {
+			return r, "v-" + guid, nil
+		}
+		return nil, "", nil
+	}
+	episodes := []*podops.Resource{{GUID: "episode-guid-1"}, {GUID: "episode-guid-3"}}
+
+	steps, err := planApply("show-guid", resources, episodes, remote)
+	if assert.NoError(t, err) && assert.Equal(t, 4, len(steps)) {
+		assert.Equal(t, applyUnchanged, steps[0].action)
+		assert.Equal(t, "v-show-guid", steps[0].version)
+		assert.Empty(t, steps[0].diff)
+
+		assert.Equal(t, applyUpdate, steps[1].action)
+		assert.Equal(t, "v-episode-guid-1", steps[1].version)
+		assert.Contains(t, steps[1].diff, "a new title")
+
+		assert.Equal(t, applyCreate, steps[2].action)
+		assert.Equal(t, "episode-guid-2", steps[2].guid)
+
+		assert.Equal(t, applyPrune, steps[3].action)
+		assert.Equal(t, "episode-guid-3", steps[3].guid)
+		assert.Equal(t, 1, countSteps(steps, applyPrune))
+	}
+
+	// without --prune, nothing is deleted
+	steps, err = planApply("show-guid", resources, nil, remote)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 3, len(steps))
+		assert.Equal(t, 0, countSteps(steps, applyPrune))
+	}
+}
+
+func TestPlanApplyErrors(t *testing.T) {
+	resources := []*localResource{
+		{source: "show.yaml", kind: podops.ResourceShow, guid: "show-guid", rsrc: testShow()},
+	}
+
+	// only a missing resource is created, any other error stops the plan
+	_, err := planApply("show-guid", resources, nil, func(kind, guid string) (interface{}, string, error) {
+		return nil, "", errordef.ErrNotAuthorized
+	})
+	assert.Equal(t, errordef.ErrNotAuthorized, err)
+
+	// resources of another production are rejected
+	_, err = planApply("other-guid", resources, nil, func(kind, guid string) (interface{}, string, error) {
+		return nil, "", nil
+	})
+	assert.Error(t, err)
+}
@@ -0,0 +1,161 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/messagedef"
+)
+
+const (
+	// buildPollInterval is the time between two checks of a build's state
+	buildPollInterval = 2 * time.Second
+	// buildWaitTimeout is the maximum time 'po build --wait' waits for a build to finish
+	buildWaitTimeout = 5 * time.Minute
+)
+
+// BuildCommand queues a build of the feed and optionally waits for it to finish
+func BuildCommand(c *cli.Context) error {
+
+	prod := getProduction(c)
+
+	build, err := client.Build(prod, c.Bool("strict"))
+	if err != nil {
+		return err
+	}
+
+	if !c.Bool("wait") {
+		printMsg(messagedef.MsgBuildQueued, build.ID, prod, build.ID)
+		return nil
+	}
+
+	timeout := time.Now().Add(buildWaitTimeout)
+	for !build.IsFinished() {
+		if time.Now().After(timeout) {
+			printMsg(messagedef.MsgBuildTimeout, build.ID, build.State, build.ID)
+			return nil
+		}
+		time.Sleep(buildPollInterval)
+
+		if build, err = client.GetBuild(prod, build.ID); err != nil {
+			return err
+		}
+	}
+
+	if build.State == podops.BuildFailed {
+		printBuildIssues(build)
+		return fmt.Errorf(messagedef.MsgBuildFailed, build.ID, build.Message) // non-zero exit code, e.g. to fail a CI pipeline
+	}
+
+	printMsg(messagedef.MsgBuildSuccess, prod, build.FeedAliasURL)
+	return nil
+}
+
+// RollbackCommand republishes the feed of an earlier build
+func RollbackCommand(c *cli.Context) error {
+	if c.NArg() > 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	b, err := client.Rollback(prod, c.Args().First())
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	printMsg(messagedef.MsgRollbackSuccess, prod, b.RollbackOf, b.FeedAliasURL)
+	return nil
+}
+
+// ListBuildsCommand lists the most recent builds of the current production
+func ListBuildsCommand(c *cli.Context) error {
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	l, err := client.ListBuilds(prod)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	if len(l.Builds) == 0 {
+		printMsg(messagedef.MsgNoBuilds)
+		return nil
+	}
+	printMsg(buildListing("ID", "STATE", "CREATED", "DURATION", "ERRORS", "WARNINGS"))
+	for _, b := range l.Builds {
+		printMsg(buildListing(b.ID, b.State, time.Unix(b.Created, 0).Local().Format(time.RFC1123), buildDuration(b), fmt.Sprintf("%d", b.Errors), fmt.Sprintf("%d", b.Warnings)))
+	}
+	return nil
+}
+
+// BuildLogsCommand shows the state and all issues of a build
+func BuildLogsCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	b, err := client.GetBuild(prod, c.Args().First())
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	printMsg("build:    %s", b.ID)
+	printMsg("state:    %s", b.State)
+	printMsg("created:  %s", time.Unix(b.Created, 0).Local().Format(time.RFC1123))
+	printMsg("duration: %s", buildDuration(b))
+	if b.RollbackOf != "" {
+		printMsg("rollback: %s", b.RollbackOf)
+	}
+	if b.Message != "" {
+		printMsg("message:  %s", b.Message)
+	}
+	if b.State == podops.BuildSucceeded && !b.ValidateOnly {
+		printMsg("feed:     %s", b.FeedAliasURL)
+	}
+	printMsg("")
+	printBuildIssues(b)
+
+	return nil
+}
+
+func printBuildIssues(b *podops.Build) {
+	if len(b.Issues) == 0 {
+		return
+	}
+	printMsg(lintListing("SEVERITY", "RULE", "MESSAGE"))
+	for _, issue := range b.Issues {
+		printMsg(lintListing(issue.Severity, issue.Rule, issue.Message))
+	}
+	printMsg(messagedef.MsgLintSummary, b.GUID, b.Errors, b.Warnings)
+}
+
+func buildDuration(b *podops.Build) string {
+	if b.Started == 0 || b.Finished == 0 {
+		return "-"
+	}
+	return (time.Duration(b.Finished-b.Started) * time.Second).String()
+}
+
+func buildListing(id, state, created, duration, errors, warnings string) string {
+	return fmt.Sprintf("  %-14s%-11s%-31s%-10s%-8s%s", id, state, created, duration, errors, warnings)
+}
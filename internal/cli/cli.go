@@ -8,6 +8,7 @@ import (
 	"io/ioutil"
 	"log"
 	"net/http"
+	"path/filepath"
 	"strings"
 
 	"gopkg.in/yaml.v2"
@@ -22,6 +23,8 @@ import (
 
 const (
 	machineEntry = "api.podops.dev"
+	versionsFile = ".podops_versions"
+	uploadsFile  = ".podops_uploads"
 )
 
 var (
@@ -136,6 +139,57 @@ func storeDefaultProduction(production string) error {
 	return ioutil.WriteFile(podops.DefaultConfigPath(), data, 0644)
 }
 
+// loadVersions returns the versions of all resources the CLI has read or written
+func loadVersions() map[string]string {
+	return loadState(versionsFile)
+}
+
+// storeVersion remembers the version of a resource the CLI has read or written. An empty version forgets the resource.
+func storeVersion(guid, version string) error {
+	return storeState(versionsFile, guid, version)
+}
+
+// loadUploads returns the locations of all interrupted uploads
+func loadUploads() map[string]string {
+	return loadState(uploadsFile)
+}
+
+// storeUpload remembers the location of an upload until it is complete. An empty location forgets the upload.
+func storeUpload(key, location string) error {
+	return storeState(uploadsFile, key, location)
+}
+
+// loadState reads a map the CLI keeps next to its credentials
+func loadState(file string) map[string]string {
+	state := make(map[string]string)
+
+	data, err := ioutil.ReadFile(statePath(file))
+	if err == nil {
+		json.Unmarshal(data, &state)
+	}
+	return state
+}
+
+// storeState updates an entry of a map the CLI keeps next to its credentials. An empty value removes the entry.
+func storeState(file, key, value string) error {
+	state := loadState(file)
+	if value == "" {
+		delete(state, key)
+	} else {
+		state[key] = value
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(statePath(file), data, 0644)
+}
+
+func statePath(file string) string {
+	return filepath.Join(filepath.Dir(podops.DefaultConfigPath()), file)
+}
+
 // GITHUB_ISSUE #15
 func loadResource(path string) (interface{}, string, string, error) {
 	data, err := ioutil.ReadFile(path)
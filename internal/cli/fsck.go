@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/messagedef"
+)
+
+// FsckCommand compares the inventory, the production bucket and the CDN of the current production and optionally repairs them
+func FsckCommand(c *cli.Context) error {
+	if c.NArg() > 0 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 0, c.NArg())
+	}
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	report, err := reconcileReport(prod, c.Bool("repair"))
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+	if c.Bool("repair") && report.Finished == 0 {
+		printMsg(messagedef.MsgFsckRunning, prod)
+		return nil
+	}
+
+	if len(report.Issues) == 0 {
+		printMsg(messagedef.MsgFsckSuccess, report.Checked)
+		return nil
+	}
+
+	printMsg(fsckListing("STORE", "PROBLEM", "REPAIRED", "NAME", "MESSAGE"))
+	for _, issue := range report.Issues {
+		repaired := "-"
+		if issue.Repaired {
+			repaired = "yes"
+		}
+		printMsg(fsckListing(issue.Store, issue.Problem, repaired, issue.Name, issue.Message))
+	}
+	printMsg(messagedef.MsgFsckSummary, len(report.Issues), report.Checked, report.Repaired)
+
+	if open := len(report.Issues) - report.Repaired; open > 0 {
+		return fmt.Errorf(messagedef.MsgFsckFailed, open) // non-zero exit code, like lint
+	}
+	return nil
+}
+
+// reconcileReport returns the issues of a production. A repair runs in the background on the CDN, the report is polled until it is finished.
+func reconcileReport(prod string, repair bool) (*podops.ReconciliationReport, error) {
+	queued, err := client.Reconcile(prod, repair)
+	if err != nil || !repair {
+		return queued, err
+	}
+
+	timeout := time.Now().Add(checkWaitTimeout)
+	report := queued
+	for report.Finished == 0 || report.Created < queued.Created {
+		if time.Now().After(timeout) {
+			return queued, nil // still running
+		}
+		time.Sleep(checkPollInterval)
+
+		if report, err = client.RepairReport(prod); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+func fsckListing(store, problem, repaired, name, msg string) string {
+	return fmt.Sprintf("  %-11s%-9s%-10s%-40s%s", store, problem, repaired, name, msg)
+}
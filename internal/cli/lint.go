@@ -0,0 +1,61 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/feed"
+	"github.com/podops/podops/internal/messagedef"
+)
+
+// LintCommand checks the current production, or the resources in DIR, against the Apple Podcasts and RSS 2.0 compliance rules
+func LintCommand(c *cli.Context) error {
+	if c.NArg() > 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+
+	var report *podops.LintReport
+	if c.NArg() == 1 {
+		dir := c.Args().First()
+		v, err := feed.LintResources(dir)
+		if err != nil {
+			printError(c, err)
+			return nil
+		}
+		report = feed.NewLintReport(dir, v)
+	} else {
+		prod := getProduction(c)
+		if prod == "" {
+			printMsg(messagedef.MsgErrorNoProduction)
+			return nil
+		}
+		r, err := client.Lint(prod)
+		if err != nil {
+			printError(c, err)
+			return nil
+		}
+		report = r
+	}
+
+	if len(report.Issues) == 0 {
+		printMsg(messagedef.MsgLintSuccess, report.GUID)
+		return nil
+	}
+
+	printMsg(lintListing("SEVERITY", "RULE", "MESSAGE"))
+	for _, issue := range report.Issues {
+		printMsg(lintListing(issue.Severity, issue.Rule, issue.Message))
+	}
+	printMsg(messagedef.MsgLintSummary, report.GUID, report.Errors, report.Warnings)
+
+	if report.Errors > 0 {
+		return fmt.Errorf(messagedef.MsgLintFailed, report.Errors) // non-zero exit code, e.g. to fail a CI pipeline
+	}
+	return nil
+}
+
+func lintListing(severity, rule, msg string) string {
+	return fmt.Sprintf("  %-10s%-26s%s", severity, rule, msg)
+}
@@ -2,8 +2,14 @@ package cli
 
 import (
 	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/podops/podops"
+	"github.com/podops/podops/backend"
 	"github.com/podops/podops/internal/messagedef"
 	"github.com/urfave/cli/v2"
 )
@@ -111,16 +117,477 @@ func SetProductionCommand(c *cli.Context) error {
 	return nil
 }
 
-// BuildCommand starts a new build of the feed
-func BuildCommand(c *cli.Context) error {
+// RenameProductionCommand changes the name of the current production
+func RenameProductionCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	p, err := client.RenameProduction(prod, c.Args().First())
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	printMsg(messagedef.MsgProductionRenamed, prod, p.Name, backend.FeedAliasURL(p.Name))
+	return nil
+}
+
+// ArchiveProductionCommand makes the current production read-only
+func ArchiveProductionCommand(c *cli.Context) error {
+	return archiveProduction(c, true)
+}
+
+// UnarchiveProductionCommand makes the current production writable again
+func UnarchiveProductionCommand(c *cli.Context) error {
+	return archiveProduction(c, false)
+}
+
+func archiveProduction(c *cli.Context, archived bool) error {
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	p, err := client.ArchiveProduction(prod, archived)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	if archived {
+		printMsg(messagedef.MsgProductionArchived, p.Name)
+	} else {
+		printMsg(messagedef.MsgProductionUnarchived, p.Name)
+	}
+	return nil
+}
+
+// TransferProductionCommand makes another account the owner of the current production
+func TransferProductionCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	userID := c.Args().First()
+	p, err := client.TransferProduction(prod, userID)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	// the production is not ours anymore
+	if prod == client.DefaultProduction() {
+		storeDefaultProduction("")
+	}
+
+	printMsg(messagedef.MsgProductionTransferred, p.Name, userID)
+	return nil
+}
+
+// DeleteProductionCommand removes a production with all its resources. The ID has to be given explicitly.
+func DeleteProductionCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	prod := c.Args().First()
+
+	status, err := client.DeleteProduction(prod)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+	if status != http.StatusNoContent {
+		printMsg(messagedef.MsgResourceDeletingError, prod)
+		return nil
+	}
+
+	if prod == client.DefaultProduction() {
+		storeDefaultProduction("")
+	}
+
+	printMsg(messagedef.MsgProductionDeleted, prod, prod, prod)
+	return nil
+}
+
+// ScheduleCommand lists the upcoming episodes of the current production
+func ScheduleCommand(c *cli.Context) error {
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	l, err := client.Schedule(prod)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	if len(l.Resources) == 0 {
+		printMsg(messagedef.MsgNoScheduledEpisodes)
+		return nil
+	}
+	printMsg(assetListing("ID", "NAME", "PUBLISH DATE"))
+	for _, r := range l.Resources {
+		printMsg(assetListing(r.GUID, r.Name, time.Unix(r.Published, 0).Local().Format(time.RFC1123)))
+	}
+	return nil
+}
+
+// ImportFeedCommand creates the show and episodes of the current production from an existing RSS feed
+func ImportFeedCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	l, err := client.ImportFeed(prod, c.Args().First(), c.Bool("force"))
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	episodes := 0
+	for _, r := range l.Resources {
+		if r.Kind == podops.ResourceEpisode {
+			episodes++
+		}
+	}
+	printMsg(messagedef.MsgImportSuccess, episodes, prod)
+	return nil
+}
+
+// SubscribeCommand issues a subscriber token for the current production
+func SubscribeCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	s, err := client.CreateSubscription(prod, c.Args().First())
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	printMsg(messagedef.MsgSubscriptionCreated, s.Name, s.FeedURL)
+	return nil
+}
+
+// ListSubscribersCommand lists the subscribers of the current production
+func ListSubscribersCommand(c *cli.Context) error {
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	l, err := client.ListSubscriptions(prod)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	if len(l.Subscriptions) == 0 {
+		printMsg(messagedef.MsgNoSubscriptions)
+		return nil
+	}
+	printMsg(subscriptionListing("TOKEN", "NAME", "FEED"))
+	for _, s := range l.Subscriptions {
+		printMsg(subscriptionListing(s.Token, s.Name, s.FeedURL))
+	}
+	return nil
+}
+
+// UnsubscribeCommand revokes a subscriber token
+func UnsubscribeCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	token := c.Args().First()
+	if _, err := client.RevokeSubscription(prod, token); err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	printMsg(messagedef.MsgSubscriptionRevoked, token)
+	return nil
+}
 
+// ListMembersCommand lists the members of the current production
+func ListMembersCommand(c *cli.Context) error {
 	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
 
-	build, err := client.Build(prod)
+	l, err := client.ListMembers(prod)
 	if err != nil {
-		return err
+		printError(c, err)
+		return nil
 	}
 
-	printMsg(messagedef.MsgBuildSuccess, prod, build.FeedAliasURL)
+	if len(l.Members) == 0 {
+		printMsg(messagedef.MsgNoMembers)
+		return nil
+	}
+	printMsg(memberListing("USER", "ROLE", "INVITED BY"))
+	for _, m := range l.Members {
+		printMsg(memberListing(m.UserID, m.Role, m.InvitedBy))
+	}
+	return nil
+}
+
+// AddMemberCommand invites an account to work on the current production
+func AddMemberCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	m, err := client.AddMember(prod, c.Args().First(), strings.ToLower(c.String("role")))
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	printMsg(messagedef.MsgMemberAdded, m.UserID, m.Role, prod)
+	return nil
+}
+
+// RemoveMemberCommand revokes the access of a member to the current production
+func RemoveMemberCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	userID := c.Args().First()
+	status, err := client.RemoveMember(prod, userID)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+	if status != http.StatusNoContent {
+		printMsg(messagedef.MsgResourceDeletingError, userID)
+		return nil
+	}
+
+	printMsg(messagedef.MsgMemberRemoved, userID, prod)
+	return nil
+}
+
+// ListWebhooksCommand lists the webhooks of the current production
+func ListWebhooksCommand(c *cli.Context) error {
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	l, err := client.ListWebhooks(prod)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	if len(l.Webhooks) == 0 {
+		printMsg(messagedef.MsgNoWebhooks)
+		return nil
+	}
+	printMsg(webhookListing("ID", "URL", "EVENTS"))
+	for _, w := range l.Webhooks {
+		events := "*"
+		if len(w.Events) > 0 {
+			events = strings.Join(w.Events, ",")
+		}
+		printMsg(webhookListing(w.ID, w.URL, events))
+	}
+	return nil
+}
+
+// AddWebhookCommand sends events of the current production to a URL
+func AddWebhookCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	w, err := client.CreateWebhook(prod, c.Args().First(), c.StringSlice("event"))
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	printMsg(messagedef.MsgWebhookCreated, w.ID, w.URL, w.Secret)
+	return nil
+}
+
+// RemoveWebhookCommand stops sending events to a webhook
+func RemoveWebhookCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	webhookID := c.Args().First()
+	status, err := client.DeleteWebhook(prod, webhookID)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+	if status != http.StatusNoContent {
+		printMsg(messagedef.MsgResourceDeletingError, webhookID)
+		return nil
+	}
+
+	printMsg(messagedef.MsgWebhookRemoved, webhookID)
+	return nil
+}
+
+// WebhookLogCommand shows the most recent deliveries of events of the current production
+func WebhookLogCommand(c *cli.Context) error {
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	l, err := client.ListDeliveries(prod)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	if len(l.Deliveries) == 0 {
+		printMsg(messagedef.MsgNoDeliveries)
+		return nil
+	}
+	printMsg(deliveryListing("CREATED", "EVENT", "WEBHOOK", "ATTEMPT", "STATUS", "MESSAGE"))
+	for _, d := range l.Deliveries {
+		status := "-"
+		if d.Status > 0 {
+			status = strconv.FormatInt(int64(d.Status), 10)
+		}
+		printMsg(deliveryListing(time.Unix(d.Created, 0).Local().Format(time.RFC1123), d.Event, d.WebhookID, strconv.FormatInt(int64(d.Attempt), 10), status, d.Message))
+	}
+	return nil
+}
+
+func webhookListing(id, url, events string) string {
+	return fmt.Sprintf("  %-14s%-50s%s", id, url, events)
+}
+
+func deliveryListing(created, event, webhook, attempt, status, message string) string {
+	return fmt.Sprintf("  %-31s%-19s%-14s%-9s%-8s%s", created, event, webhook, attempt, status, message)
+}
+
+func memberListing(user, role, invitedBy string) string {
+	return fmt.Sprintf("  %-40s%-12s%s", user, role, invitedBy)
+}
+
+func statsListing(day, downloads, requests string) string {
+	return fmt.Sprintf("  %-14s%-12s%s", day, downloads, requests)
+}
+
+func subscriptionListing(token, name, feed string) string {
+	return fmt.Sprintf("  %-34s%-30s%s", token, name, feed)
+}
+
+// StatsCommand lists the downloads of the current production per episode, or of one episode per day
+func StatsCommand(c *cli.Context) error {
+	if c.NArg() > 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	days := c.Int("days")
+	episode := c.Args().First()
+	l, err := client.Stats(prod, episode, days)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	if len(l.Stats) == 0 {
+		printMsg(messagedef.MsgNoDownloads, days)
+		return nil
+	}
+
+	if episode != "" {
+		printMsg(statsListing("DAY", "DOWNLOADS", "REQUESTS"))
+		for _, s := range l.Stats {
+			printMsg(statsListing(s.Day, strconv.FormatInt(s.Downloads, 10), strconv.FormatInt(s.Requests, 10)))
+		}
+		return nil
+	}
+
+	// sum up the days of each episode
+	downloads := make(map[string]*podops.DownloadStats)
+	episodes := make([]*podops.DownloadStats, 0)
+	for _, s := range l.Stats {
+		if e, ok := downloads[s.Episode]; ok {
+			e.Downloads += s.Downloads
+			continue
+		}
+		e := *s
+		downloads[s.Episode] = &e
+		episodes = append(episodes, &e)
+	}
+	sort.SliceStable(episodes, func(i, j int) bool {
+		return episodes[i].Downloads > episodes[j].Downloads
+	})
+
+	printMsg(assetListing("ID", "NAME", "DOWNLOADS"))
+	for _, e := range episodes {
+		printMsg(assetListing(e.Episode, e.Name, strconv.FormatInt(e.Downloads, 10)))
+	}
 	return nil
 }
@@ -3,7 +3,11 @@ package cli
 import (
 	"fmt"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/txsvc/platform/v2/pkg/id"
 	"github.com/urfave/cli/v2"
@@ -11,6 +15,7 @@ import (
 
 	"github.com/podops/podops"
 	"github.com/podops/podops/backend"
+	"github.com/podops/podops/internal/errordef"
 	"github.com/podops/podops/internal/messagedef"
 	"github.com/podops/podops/internal/metadata"
 )
@@ -66,11 +71,12 @@ func GetResourcesCommand(c *cli.Context) error {
 		guid := c.Args().First()
 
 		var rsrc interface{}
-		err := client.FindResource(guid, &rsrc)
+		version, err := client.FindResource(guid, &rsrc)
 		if err != nil {
 			fmt.Println(messagedef.MsgNoResourcesFound)
 			return nil
 		}
+		storeVersion(guid, version)
 
 		data, err := yaml.Marshal(rsrc)
 		if err != nil {
@@ -97,12 +103,13 @@ func CreateCommand(c *cli.Context) error {
 		return err
 	}
 
-	_, err = client.CreateResource(getProduction(c), kind, guid, force, r)
+	version, err := client.CreateResource(getProduction(c), kind, guid, force, r)
 	if err != nil {
 		return err
 	}
+	storeVersion(guid, version)
 
-	fmt.Println(messagedef.MsgResourceCreated, fmt.Sprintf("%s-%s", kind, guid))
+	printMsg(messagedef.MsgResourceCreated, fmt.Sprintf("%s-%s", kind, guid))
 	return nil
 }
 
@@ -120,12 +127,30 @@ func UpdateCommand(c *cli.Context) error {
 		return err
 	}
 
-	_, err = client.UpdateResource(getProduction(c), kind, guid, force, r)
+	// only update what we have seen before, unless forced to
+	version := ""
+	if !force {
+		version = loadVersions()[guid]
+		if version == "" {
+			// never read, at least don't overwrite changes made while we update
+			var current interface{}
+			if version, err = client.GetResource(getProduction(c), kind, guid, &current); err != nil {
+				return err
+			}
+		}
+	}
+
+	version, err = client.UpdateResource(getProduction(c), kind, guid, version, force, r)
+	if err == errordef.ErrPreconditionFailed {
+		printMsg(messagedef.MsgResourceChanged, fmt.Sprintf("%s-%s", kind, guid), guid)
+		return nil
+	}
 	if err != nil {
 		return err
 	}
+	storeVersion(guid, version)
 
-	fmt.Println(messagedef.MsgResourceUpdated, fmt.Sprintf("%s-%s", kind, guid))
+	printMsg(messagedef.MsgResourceUpdated, fmt.Sprintf("%s-%s", kind, guid))
 	return nil
 }
 
@@ -140,18 +165,143 @@ func DeleteResourcesCommand(c *cli.Context) error {
 	kind := strings.ToLower(c.Args().First())
 	guid := c.Args().Get(1)
 
-	status, err := client.DeleteResource(prod, kind, guid)
+	version := ""
+	if !c.Bool("force") {
+		version = loadVersions()[guid]
+	}
+
+	status, err := client.DeleteResource(prod, kind, guid, version)
+	if err == errordef.ErrPreconditionFailed {
+		printMsg(messagedef.MsgResourceChanged, fmt.Sprintf("%s/%s-%s", prod, kind, guid), guid)
+		return nil
+	}
 	if err != nil {
 		printError(c, err)
 		return err
 	}
+	storeVersion(guid, "")
 
 	if status != http.StatusNoContent {
 		printMsg(messagedef.MsgResourceDeletingError, fmt.Sprintf("%s/%s-%s", prod, kind, guid))
 		return nil
 	}
 
-	printMsg(messagedef.MsgResourceDeleted, fmt.Sprintf("%s/%s-%s", prod, kind, guid))
+	printMsg(messagedef.MsgResourceTrashed, fmt.Sprintf("%s/%s-%s", prod, kind, guid), guid)
+	return nil
+}
+
+// TrashCommand lists the deleted resources of a production
+func TrashCommand(c *cli.Context) error {
+	prod := getProduction(c)
+
+	l, err := client.Trash(prod)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	if len(l.Items) == 0 {
+		printMsg(messagedef.MsgTrashEmpty)
+		return nil
+	}
+	printMsg(trashListing("ID", "NAME", "KIND", "DELETED", "EXPIRES"))
+	for _, t := range l.Items {
+		deleted := time.Unix(t.Deleted, 0).Local().Format("2006-01-02 15:04")
+		expires := time.Unix(t.Expires, 0).Local().Format("2006-01-02")
+		printMsg(trashListing(t.GUID, t.Name, t.Kind, deleted, expires))
+	}
+	return nil
+}
+
+// UndeleteCommand restores a deleted resource from the trash
+func UndeleteCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	prod := getProduction(c)
+	guid := c.Args().First()
+
+	r, err := client.Undelete(prod, guid)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	printMsg(messagedef.MsgResourceUndeleted, fmt.Sprintf("%s/%s-%s", prod, r.Kind, guid))
+	return nil
+}
+
+// HistoryCommand lists the revisions of a show or episode
+func HistoryCommand(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	guid := c.Args().First()
+
+	l, err := client.Revisions(guid)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	if len(l.Revisions) == 0 {
+		printMsg(messagedef.MsgNoRevisions, guid)
+		return nil
+	}
+	printMsg(revisionListing("REV", "CREATED", "AUTHOR", "HASH"))
+	for _, r := range l.Revisions {
+		printMsg(revisionListing(strconv.Itoa(r.Revision), time.Unix(r.Created, 0).Local().Format(time.RFC1123), r.Author, r.Hash[:12]))
+	}
+	return nil
+}
+
+// DiffCommand shows the changes between two revisions of a show or episode
+func DiffCommand(c *cli.Context) error {
+	if c.NArg() != 3 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 3, c.NArg())
+	}
+	guid := c.Args().First()
+	from, err := parseRevision(c.Args().Get(1))
+	if err != nil {
+		return err
+	}
+	to, err := parseRevision(c.Args().Get(2))
+	if err != nil {
+		return err
+	}
+
+	d, err := client.Diff(guid, from, to)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	if d.Diff == "" {
+		printMsg(messagedef.MsgNoDifferences, from, to)
+		return nil
+	}
+	fmt.Print(d.Diff)
+	return nil
+}
+
+// RestoreCommand restores a show or episode to an earlier revision
+func RestoreCommand(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 2, c.NArg())
+	}
+	guid := c.Args().First()
+	rev, err := parseRevision(c.Args().Get(1))
+	if err != nil {
+		return err
+	}
+
+	r, err := client.Restore(guid, rev)
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+
+	printMsg(messagedef.MsgRevisionRestored, rev, guid, r.Revision)
 	return nil
 }
 
@@ -207,13 +357,82 @@ func UploadCommand(c *cli.Context) error {
 
 	prod := getProduction(c)
 	name := c.Args().First()
-	force := c.Bool("force")
 
-	err := client.Upload(prod, name, force)
+	info, err := os.Stat(name)
 	if err != nil {
 		return err
 	}
+	path, err := filepath.Abs(name)
+	if err != nil {
+		return err
+	}
+
+	// an interrupted upload of the same, unchanged file is resumed, unless --force starts it over
+	key := fmt.Sprintf("%s:%s:%d:%d", prod, path, info.Size(), info.ModTime().Unix())
+	location := ""
+	if !c.Bool("force") {
+		location = loadUploads()[key]
+	}
+	if location != "" {
+		printMsg(messagedef.MsgUploadResuming, name)
+	}
+
+	progress := func(offset, length int64) {
+		fmt.Printf("\r  %s %3d%% (%s of %s)", name, offset*100/length, formatBytes(offset), formatBytes(length))
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		if location == "" {
+			if location, err = client.CreateUpload(prod, name); err != nil {
+				return err
+			}
+			storeUpload(key, location)
+		}
+
+		err = client.ResumeUpload(location, name, progress)
+		if err != errordef.ErrNoSuchUpload {
+			break
+		}
+		// the upload has expired, start over
+		storeUpload(key, "")
+		location = ""
+	}
+	fmt.Println()
+	if err != nil {
+		printMsg(messagedef.MsgUploadInterrupted, name)
+		return err
+	}
 
-	fmt.Println(fmt.Sprintf(messagedef.MsgResourceUploadSuccess, name))
+	storeUpload(key, "")
+	printMsg(messagedef.MsgResourceUploadSuccess, name)
 	return nil
 }
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func parseRevision(s string) (int, error) {
+	rev, err := strconv.Atoi(s)
+	if err != nil || rev < 1 {
+		return 0, fmt.Errorf(messagedef.MsgRevisionInvalid, s)
+	}
+	return rev, nil
+}
+
+func revisionListing(rev, created, author, hash string) string {
+	return fmt.Sprintf("  %-6s%-31s%-34s%s", rev, created, author, hash)
+}
+
+func trashListing(guid, name, kind, deleted, expires string) string {
+	return fmt.Sprintf("  %-20s%-40s%-10s%-20s%s", guid, name, kind, deleted, expires)
+}
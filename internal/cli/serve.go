@@ -0,0 +1,119 @@
+package cli
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/podops/podops/feed"
+	"github.com/podops/podops/internal/messagedef"
+)
+
+const (
+	// check the resources for changes every watchInterval
+	watchInterval = time.Second
+)
+
+type (
+	// preview holds the most recent feed built from the resources in dir
+	preview struct {
+		dir     string
+		baseURL string
+
+		mu        sync.RWMutex
+		feed      []byte
+		err       error
+		signature string
+	}
+)
+
+// ServeCommand builds the feed from the resources in DIR and serves it together with the local assets
+func ServeCommand(c *cli.Context) error {
+	if c.NArg() > 1 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 1, c.NArg())
+	}
+	dir := "."
+	if c.NArg() == 1 {
+		dir = c.Args().First()
+	}
+	if fi, err := os.Stat(dir); err != nil || !fi.IsDir() {
+		return fmt.Errorf(messagedef.MsgResourceNotFound, dir)
+	}
+
+	addr := fmt.Sprintf("localhost:%d", c.Int("port"))
+	p := &preview{
+		dir:     dir,
+		baseURL: "http://" + addr,
+	}
+	p.rebuild()
+	go p.watch()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/feed.xml", p.feedHandler)
+	mux.Handle("/", http.FileServer(http.Dir(dir)))
+
+	printMsg(messagedef.MsgServeListening, p.baseURL+"/feed.xml")
+	return http.ListenAndServe(addr, mux)
+}
+
+func (p *preview) feedHandler(w http.ResponseWriter, r *http.Request) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.err != nil {
+		http.Error(w, p.err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.Write(p.feed)
+}
+
+// watch rebuilds the feed whenever a resource or asset in dir changes
+func (p *preview) watch() {
+	for range time.Tick(watchInterval) {
+		if p.changed() {
+			p.rebuild()
+		}
+	}
+}
+
+func (p *preview) rebuild() {
+	p.changed() // remember the current state of dir
+
+	f, err := feed.BuildPreview(p.dir, p.baseURL)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err != nil {
+		p.err = err
+		printMsg(messagedef.MsgServeBuildError, err)
+		return
+	}
+	p.feed = f.Bytes()
+	p.err = nil
+	printMsg(messagedef.MsgServeBuildSuccess, len(f.Items))
+}
+
+// changed compares the names, sizes and timestamps of all files in dir with the previous call
+func (p *preview) changed() bool {
+	files, _ := filepath.Glob(filepath.Join(p.dir, "*"))
+
+	signature := ""
+	for _, f := range files {
+		if fi, err := os.Stat(f); err == nil && !fi.IsDir() {
+			signature = signature + fmt.Sprintf("%s:%d:%d;", fi.Name(), fi.Size(), fi.ModTime().UnixNano())
+		}
+	}
+
+	if signature == p.signature {
+		return false
+	}
+	p.signature = signature
+	return true
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
+	"github.com/podops/podops/internal/messagedef"
+)
+
+const (
+	// checkPollInterval is the time between two checks of a report's state
+	checkPollInterval = 2 * time.Second
+	// checkWaitTimeout is the maximum time the CLI waits for a check on the CDN to finish
+	checkWaitTimeout = 5 * time.Minute
+)
+
+// VerifyCommand re-hashes the files of the current production on the CDN and lists the ones that are corrupted or out of sync
+func VerifyCommand(c *cli.Context) error {
+	if c.NArg() > 0 {
+		return fmt.Errorf(messagedef.MsgArgumentCountMismatch, 0, c.NArg())
+	}
+	prod := getProduction(c)
+	if prod == "" {
+		printMsg(messagedef.MsgErrorNoProduction)
+		return nil
+	}
+
+	report, err := verifyReport(prod, c.Bool("last"))
+	if err == errordef.ErrNoSuchObject {
+		printMsg(messagedef.MsgVerifyNoReport, prod)
+		return nil
+	}
+	if err != nil {
+		printError(c, err)
+		return nil
+	}
+	if report.Finished == 0 {
+		printMsg(messagedef.MsgVerifyRunning, prod)
+		return nil
+	}
+
+	if len(report.Issues) == 0 {
+		printMsg(messagedef.MsgVerifySuccess, report.Checked)
+		return nil
+	}
+
+	printMsg(verifyListing("PROBLEM", "EXPECTED", "ACTUAL", "NAME"))
+	for _, issue := range report.Issues {
+		printMsg(verifyListing(issue.Problem, shortDigest(issue.Expected), shortDigest(issue.Actual), issue.Name))
+	}
+	return fmt.Errorf(messagedef.MsgVerifyFailed, len(report.Issues), report.Checked) // non-zero exit code, like lint
+}
+
+// verifyReport queues a check and waits for its report, or returns the report of the latest check if last is true
+func verifyReport(prod string, last bool) (*podops.IntegrityReport, error) {
+	if last {
+		return client.VerifyReport(prod)
+	}
+
+	queued, err := client.Verify(prod)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := time.Now().Add(checkWaitTimeout)
+	report := queued
+	for report.Finished == 0 || report.Created < queued.Created {
+		if time.Now().After(timeout) {
+			return queued, nil // still running
+		}
+		time.Sleep(checkPollInterval)
+
+		if report, err = client.VerifyReport(prod); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+func verifyListing(problem, expected, actual, name string) string {
+	return fmt.Sprintf("  %-12s%-14s%-14s%s", problem, expected, actual, name)
+}
+
+// shortDigest abbreviates a digest the way git abbreviates commit hashes
+func shortDigest(digest string) string {
+	if digest == "" {
+		return "-"
+	}
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
@@ -19,18 +19,71 @@ var (
 	ErrValidationFailed = errors.New("validation failed")
 
 	// ErrNoSuch... indicates that the requested resource does not exist
-	ErrNoSuchProduction = errors.New("production doesn't exist")
-	ErrNoSuchEpisode    = errors.New("episode doesn't exist")
-	ErrNoSuchAsset      = errors.New("asset doesn't exist")
-	ErrNoSuchResource   = errors.New("resource doesn't exist")
+	ErrNoSuchProduction   = errors.New("production doesn't exist")
+	ErrNoSuchEpisode      = errors.New("episode doesn't exist")
+	ErrNoSuchAsset        = errors.New("asset doesn't exist")
+	ErrNoSuchResource     = errors.New("resource doesn't exist")
+	ErrNoSuchEntity       = errors.New("entity doesn't exist")
+	ErrNoSuchObject       = errors.New("object doesn't exist")
+	ErrNoSuchSubscription = errors.New("subscription doesn't exist")
+	ErrNoSuchBuild        = errors.New("build doesn't exist")
+	ErrNoSuchRevision     = errors.New("revision doesn't exist")
+	ErrNoSuchAccount      = errors.New("account doesn't exist")
+	ErrNoSuchMember       = errors.New("member doesn't exist")
+	ErrNoSuchWebhook      = errors.New("webhook doesn't exist")
+	ErrNoSuchUpload       = errors.New("upload doesn't exist")
+
+	// ErrChecksumMismatch indicates that uploaded data was corrupted on the way
+	ErrChecksumMismatch = errors.New("checksum mismatch")
+
+	// ErrInvalidOffset indicates that a chunk does not continue an upload where it stopped
+	ErrInvalidOffset = errors.New("upload offset mismatch")
+
+	// ErrEntityExists indicates that the key of a new entity is already taken
+	ErrEntityExists = errors.New("entity already exists")
+
+	// ErrPreconditionFailed indicates that a resource was changed since the caller last read it
+	ErrPreconditionFailed = errors.New("resource was changed by someone else")
+
+	// ErrProductionArchived indicates that a production is read-only
+	ErrProductionArchived = errors.New("production is archived")
+
+	// ErrProductionDeleted indicates that a production is in the trash
+	ErrProductionDeleted = errors.New("production is in the trash")
+
+	// ErrPrivateCallback indicates that a callback URL does not point to a public https endpoint
+	ErrPrivateCallback = errors.New("callback is not a public https endpoint")
+
+	// ErrPrivateURL indicates that a URL submitted for a server-side fetch does not point to a public host
+	ErrPrivateURL = errors.New("url is not a public endpoint")
+
+	// ErrInvalidTopic indicates that a WebSub topic is not a feed hosted by PodOps
+	ErrInvalidTopic = errors.New("topic is not a public feed")
+
+	// ErrIntentNotVerified indicates that a WebSub subscriber did not confirm a (un)subscription
+	ErrIntentNotVerified = errors.New("subscriber did not verify the intent")
+
+	// ErrNotInTrash indicates that a resource can not be restored because it is not in the trash
+	ErrNotInTrash = errors.New("resource is not in the trash")
 
 	// ErrMissingResource indicates that a resource required for an operation can not be found
 	ErrMissingResource = errors.New("can't find resource")
 
+	// ErrUnsupportedMediaFormat indicates that the container format of a media file is not known to the media probe
+	ErrUnsupportedMediaFormat = errors.New("unsupported media format")
+	// ErrInvalidMediaFormat indicates that a media file is truncated or its container is malformed
+	ErrInvalidMediaFormat = errors.New("invalid media format")
+
 	// ErrBuildFailed indicates that there was an error while building the feed
 	ErrBuildFailed = errors.New("build failed")
+	// ErrBuildTimeout indicates that a build did not finish in time, e.g. because its worker died
+	ErrBuildTimeout = errors.New("build timed out")
+	// ErrRollbackFailed indicates that a build has no feed that can be republished
+	ErrRollbackFailed = errors.New("can't roll back to this build")
 	// ErrFeedFailed indicates that some pre-requisites for building the feed are not met
 	ErrFeedFailed = errors.New("can't build feed.xml")
+	// ErrNoLocalShow indicates that a directory does not contain exactly one show-*.yaml to preview or lint
+	ErrNoLocalShow = errors.New("expected exactly one show-*.yaml")
 
 	// ErrInvalidClientConfiguration indicates that the client configuration is in invalid
 	ErrInvalidClientConfiguration = errors.New("invalid configuration")
@@ -13,11 +13,18 @@ const (
 	MsgResourceInvalidGUID           = "invalid guid '%s'"
 
 	MsgResourceImportError = "error transfering '%s'"
+	MsgResourceTooLarge    = "'%s' exceeds %d bytes"
 	MsgResourceUploadError = "error uploading '%s'"
 
+	MsgArtworkNotVerified = "Can't verify artwork '%s', '%s' is not in the CDN"
+
 	MsgParameterIsInvalid = "invalid parameter '%s'"
 	MsgParameterMismatch  = "parameters mismatch. expected '%s', got '%s'"
 
+	MsgUnknownField        = "unknown field '%s' in '%s'"
+	MsgUnsupportedOperator = "unsupported operator '%s'"
+	MsgTypeMismatch        = "type mismatch: can't compare '%v' and '%v'"
+
 	MsgAuthenticationNotFound     = "account '%s' not found"
 	MsgAuthenticationTokenExpired = "token expired"
 	MsgAuthenticationTokenInvalid = "token is invalid"
@@ -40,16 +47,97 @@ const (
 
 	MsgResourceCreated       = "created resource '%s'"
 	MsgResourceUpdated       = "updated resource '%s'"
+	MsgResourceUnchanged     = "unchanged resource '%s'"
 	MsgResourceDeleted       = "deleted resource '%s'"
+	MsgResourceTrashed       = "moved resource '%s' to the trash. use 'po undelete %s' to restore it"
+	MsgResourceUndeleted     = "restored resource '%s'"
 	MsgResourceUnknown       = "unknown resource '%s'"
 	MsgResourceDeletingError = "error deleting resource '%s'"
+	MsgResourceWouldCreate   = "would create resource '%s'"
+	MsgResourceWouldUpdate   = "would update resource '%s'"
+	MsgResourceWouldTrash    = "would move resource '%s' to the trash"
+	MsgResourceChanged       = "resource '%s' was changed by someone else since you last read it. use 'po get %s' to see the latest version or --force to overwrite it"
 	MsgResourceUploadSuccess = "uploaded '%s'"
+	MsgUploadResuming        = "resuming the upload of '%s'"
+	MsgUploadInterrupted     = "the upload of '%s' was interrupted, run the same command again to resume it"
+
+	MsgApplyConfirmPrune = "move %d episode(s) that are not part of the resources to the trash? [y/N] "
+	MsgApplyAborted      = "aborted, nothing was changed"
+
+	MsgTrashEmpty = "the trash is empty"
 
-	MsgNoProductionsFound = "production(s) not found"
-	MsgNoResourcesFound   = "resource(s) not found"
+	MsgNoRevisions      = "no revisions of '%s'"
+	MsgNoDifferences    = "no differences between revision %d and %d"
+	MsgRevisionRestored = "restored revision %d of '%s' as revision %d"
+	MsgRevisionInvalid  = "invalid revision '%s'"
+
+	MsgNoProductionsFound  = "production(s) not found"
+	MsgNoResourcesFound    = "resource(s) not found"
+	MsgNoScheduledEpisodes = "no episodes scheduled"
+	MsgNoSubscriptions     = "no subscribers"
+	MsgNoDownloads         = "no downloads in the last %d day(s)"
+
+	MsgProductionRenamed     = "renamed production '%s' to '%s'.\nThe feed is at %s"
+	MsgProductionArchived    = "archived production '%s'. Use 'po unarchive' to make changes again"
+	MsgProductionUnarchived  = "production '%s' is no longer archived"
+	MsgProductionTransferred = "transferred production '%s' to '%s'"
+	MsgProductionDeleted     = "moved production '%s' to the trash. Restore it with 'po undelete --prod %s %s' within 30 days"
 
 	MsgErrorNoProduction        = "no production set. Use 'po show [ID|name]' first"
 	MsgErrorCanNotSetProduction = "no production set. Use 'po shows' to find available productions"
 
-	MsgBuildSuccess = "build production '%s' successful.\nAccess the feed at %s"
+	MsgBuildSuccess    = "build production '%s' successful.\nAccess the feed at %s"
+	MsgBuildQueued     = "queued build '%s' of production '%s'.\nUse 'po build logs %s' to follow its progress"
+	MsgBuildFailed     = "build '%s' failed: %s"
+	MsgBuildTimeout    = "build '%s' is still %s. Use 'po build logs %s' to follow its progress"
+	MsgRollbackSuccess = "rolled back production '%s' to build '%s'.\nAccess the feed at %s"
+	MsgNoBuilds        = "no builds"
+	MsgBuildNoEpisodes = "no published episodes"
+	MsgImportSuccess   = "imported show and %d episode(s) into production '%s'"
+
+	MsgMemberAdded      = "added '%s' as %s of production '%s'"
+	MsgMemberRemoved    = "removed '%s' from production '%s'"
+	MsgNoMembers        = "no members"
+	MsgWebhookCreated   = "created webhook '%s' for '%s'. Verify the signature of its requests with the secret:\n\n  %s\n\nThe secret is shown only once."
+	MsgWebhookRemoved   = "removed webhook '%s'"
+	MsgNoWebhooks       = "no webhooks"
+	MsgNoDeliveries     = "no deliveries"
+	MsgMemberInvitation = "%s added you as %s of the podcast '%s' on PodOps.\n\nLog in with 'po login %s' to start working on it."
+
+	MsgSubscriptionCreated = "added subscriber '%s'.\nThe private feed is at %s"
+	MsgSubscriptionRevoked = "revoked token '%s'"
+
+	MsgLintSuccess = "'%s' complies with all rules"
+	MsgLintSummary = "'%s' has %d error(s), %d warning(s)"
+	MsgLintFailed  = "lint failed with %d error(s)"
+
+	MsgVerifySuccess  = "verified %d file(s), no issues"
+	MsgVerifyFailed   = "%d issue(s) in %d file(s)"
+	MsgVerifyRunning  = "the files of '%s' are still being checked. Use 'po verify --last' to see the report later"
+	MsgVerifyNoReport = "'%s' was never verified. Use 'po verify' to check its files"
+
+	MsgFsckSuccess       = "checked %d item(s), the inventory, bucket and CDN are in sync"
+	MsgFsckSummary       = "%d issue(s) in %d item(s), %d repaired"
+	MsgFsckFailed        = "%d issue(s) not repaired"
+	MsgFsckRunning       = "the repair of '%s' is still running. Run 'po fsck' later to see what is left"
+	MsgFsckNoMetadata    = "the asset has no metadata"
+	MsgFsckNoResource    = "the metadata has no inventory entry"
+	MsgFsckNoFile        = "the file is not on the CDN"
+	MsgFsckNoObject      = "the object is not in the production bucket"
+	MsgFsckUnknownFile   = "the file is not in the inventory"
+	MsgFsckUnknownObject = "the object is not in the inventory"
+	MsgFsckTrashedObject = "the object was moved to the trash but not removed"
+	MsgFsckSizeMismatch  = "%d bytes expected, found %d bytes"
+	MsgFsckRepairFailed  = "%s, repair failed: %v"
+	MsgFsckNotRepairable = "%s, can't be repaired"
+
+	MsgAssetNotInventoried = "%s: '%s' is not in the CDN inventory"
+	MsgAssetNotImported    = "%s: '%s' is not imported yet"
+	MsgAssetUnreachable    = "%s: can't download '%s' (%s)"
+	MsgAssetSizeMismatch   = "%s: the size of '%s' is %d bytes, expected %d"
+	MsgAssetTypeMismatch   = "%s: the type of '%s' is '%s', expected '%s'"
+
+	MsgServeListening    = "serving the feed at %s, press Ctrl+C to stop"
+	MsgServeBuildSuccess = "feed rebuilt with %d episode(s)"
+	MsgServeBuildError   = "error building the feed: %v"
 )
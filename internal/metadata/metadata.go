@@ -1,25 +1,69 @@
 package metadata
 
 import (
-	"crypto/md5"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
-	"github.com/tcolgate/mp3"
 	"github.com/txsvc/platform/v2/pkg/id"
+
+	"github.com/podops/podops"
+	"github.com/podops/podops/internal/errordef"
 )
 
 const (
 	defaultContentType = "application/octet-stream"
+
+	// MediaClassAudio is used for audio enclosures
+	MediaClassAudio MediaClass = "audio"
+	// MediaClassVideo is used for video enclosures
+	MediaClassVideo MediaClass = "video"
+	// MediaClassDocument is used for PDF and EPUB enclosures
+	MediaClassDocument MediaClass = "document"
+	// MediaClassImage is used for cover art
+	MediaClassImage MediaClass = "image"
+	// MediaClassText is used for transcripts and chapters
+	MediaClassText MediaClass = "text"
+	// MediaClassUnknown is used for everything else
+	MediaClassUnknown MediaClass = "unknown"
+)
+
+var (
+	// text based formats that http.DetectContentType can't tell apart, e.g. transcripts and chapters
+	textTypeMap = map[string]string{
+		".srt":  podops.TranscriptTypeSRT,
+		".vtt":  podops.TranscriptTypeVTT,
+		".json": podops.TranscriptTypeJSON,
+		".html": podops.TranscriptTypeHTML,
+	}
+
+	// the enclosure types of the feed (see mediaTypeMap in feed/feed.go) and the image types we know of
+	mediaClassMap = map[string]MediaClass{
+		"audio/x-m4a":     MediaClassAudio,
+		"audio/mpeg":      MediaClassAudio,
+		"audio/ogg":       MediaClassAudio,
+		"audio/flac":      MediaClassAudio,
+		"video/x-m4v":     MediaClassVideo,
+		"video/mp4":       MediaClassVideo,
+		"video/quicktime": MediaClassVideo,
+		"application/pdf": MediaClassDocument,
+		"document/x-epub": MediaClassDocument,
+		"image/png":       MediaClassImage,
+		"image/jpeg":      MediaClassImage,
+	}
 )
 
 type (
+	// MediaClass groups content types by their use in a podcast
+	MediaClass string
+
 	// Metadata keeps basic metadata of a cdn resource
 	Metadata struct {
 		Name        string `json:"name"`
@@ -28,7 +72,15 @@ type (
 		ParentGUID  string `json:"parent_guid"`
 		Size        int64  `json:"size"`
 		Duration    int64  `json:"duration"`
+		Bitrate     int64  `json:"bitrate,omitempty"`
+		Channels    int    `json:"channels,omitempty"`
+		SampleRate  int    `json:"sample_rate,omitempty"`
+		Width       int    `json:"width,omitempty"`
+		Height      int    `json:"height,omitempty"`
+		Format      string `json:"format,omitempty"`
+		ColorModel  string `json:"color_model,omitempty"`
 		ContentType string `json:"content_type"`
+		Digest      string `json:"digest,omitempty"` // SHA-256 of the content, hex encoded
 		Etag        string `json:"etag"`
 		Timestamp   int64  `json:"timestamp"`
 	}
@@ -44,6 +96,9 @@ func ExtractMetadataFromResponse(resp *http.Response) *Metadata {
 		ContentType: resp.Header.Get("content-type"),
 		Etag:        resp.Header.Get("etag"),
 	}
+	if resp.Request != nil && resp.Request.URL != nil {
+		meta.ContentType = contentTypeByExtension(resp.Request.URL.Path, meta.ContentType)
+	}
 	l, err := strconv.ParseInt(resp.Header.Get("content-length"), 10, 64)
 	if err == nil {
 		meta.Size = l
@@ -56,7 +111,24 @@ func ExtractMetadataFromResponse(resp *http.Response) *Metadata {
 	return &meta
 }
 
+// ExtractMetadataFromFile probes the file at path and calculates the digest of its content
 func ExtractMetadataFromFile(path string) (*Metadata, error) {
+	meta, err := ProbeMetadataFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// calculate our etag from the content
+	if meta.Digest, err = DigestFile(path); err != nil {
+		return nil, err
+	}
+	meta.Etag = meta.ETAG()
+	return meta, nil
+}
+
+// ProbeMetadataFromFile reads the media type, duration, dimensions etc. from the headers of the file at path.
+// Unlike ExtractMetadataFromFile it does not read the entire file, i.e. Digest and Etag are left empty.
+func ProbeMetadataFromFile(path string) (*Metadata, error) {
 	file, err := os.Open(path)
 	if err != nil {
 		return nil, err
@@ -77,9 +149,6 @@ func ExtractMetadataFromFile(path string) (*Metadata, error) {
 		Timestamp:   fi.ModTime().Unix(),
 	}
 
-	// calculate our etag
-	meta.Etag = meta.ETAG()
-
 	// try to detect the media type
 	// thanks to https://gist.github.com/rayrutjes/db9b9ea8e02255d62ce2
 	buffer := make([]byte, 512)
@@ -87,53 +156,96 @@ func ExtractMetadataFromFile(path string) (*Metadata, error) {
 	if err != nil {
 		return nil, err
 	}
-	meta.ContentType = http.DetectContentType(buffer)
+	meta.ContentType = contentTypeByExtension(path, http.DetectContentType(buffer))
 	// reset the read pointer
 	file.Seek(0, 0)
 
-	// in case it is a .mp3, calculate the play time.
-	// thanks to https://stackoverflow.com/questions/60281655/how-to-find-the-length-of-mp3-file-in-golang
-	if meta.IsAudio() {
-		d := mp3.NewDecoder(file)
-
-		var f mp3.Frame
-		skipped := 0
-		t := 0.0
-
-		for {
-			if err := d.Decode(&f, &skipped); err != nil {
-				if err == io.EOF {
-					break
-				}
-				return nil, err
-			}
-			t = t + f.Duration().Seconds()
-		}
-		meta.Duration = int64(t) // duration in seconds
+	// duration, dimensions etc. of audio, video and images
+	info, err := Probe(file, meta.Size)
+	if err == nil {
+		meta.UpdateMediaInfo(info)
+	} else if err != errordef.ErrUnsupportedMediaFormat {
+		return nil, err
 	}
 	return &meta, nil
 }
 
+// ETAG returns the strong entity tag of the content, or an empty string if its digest is unknown
 func (m *Metadata) ETAG() string {
-	hash := md5.Sum([]byte(fmt.Sprintf("%s%d%d", m.Name, m.Size, m.Timestamp)))
-	return hex.EncodeToString(hash[:])
+	if m.Digest == "" {
+		return ""
+	}
+	return fmt.Sprintf("\"%s\"", m.Digest)
+}
+
+// Digest returns the SHA-256 of everything read from r, hex encoded
+func Digest(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// DigestFile returns the SHA-256 of a file, hex encoded
+func DigestFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	return Digest(f)
+}
+
+// UpdateMediaInfo copies the results of a media probe
+func (m *Metadata) UpdateMediaInfo(info *MediaInfo) {
+	m.ContentType = info.ContentType
+	m.Duration = int64(info.Duration)
+	m.Bitrate = info.Bitrate
+	m.Channels = info.Channels
+	m.SampleRate = info.SampleRate
+	m.Width = info.Width
+	m.Height = info.Height
+	m.Format = info.Format
+	m.ColorModel = info.ColorModel
 }
 
-func (m *Metadata) IsAudio() bool {
-	return m.ContentType == "audio/mpeg" // FIXME to include other types also
+// MediaClass returns the class of the asset based on its content type
+func (m *Metadata) MediaClass() MediaClass {
+	return ClassOf(m.ContentType)
 }
 
-func (m *Metadata) IsImage() bool {
-	return !m.IsAudio()
+// ClassOf returns the media class of a content type, e.g. 'audio/mpeg' -> MediaClassAudio
+func ClassOf(contentType string) MediaClass {
+	ct := strings.ToLower(strings.TrimSpace(strings.Split(contentType, ";")[0]))
+
+	if c, ok := mediaClassMap[ct]; ok {
+		return c
+	}
+	for _, t := range textTypeMap {
+		if ct == t {
+			return MediaClassText
+		}
+	}
+	// types we can't use in a feed but can still tell apart
+	switch strings.Split(ct, "/")[0] {
+	case "audio":
+		return MediaClassAudio
+	case "video":
+		return MediaClassVideo
+	case "image":
+		return MediaClassImage
+	}
+	return MediaClassUnknown
 }
 
 // CalculateLength returns the play duration of a media file like a .mp3
 func CalculateLength(path string) (int64, error) {
-	m, err := ExtractMetadataFromFile(path)
+	info, err := ProbeFile(path)
 	if err != nil {
 		return 0, err
 	}
-	return m.Duration, nil
+	return int64(info.Duration), nil
 }
 
 // FingerprintURI creates a unique uri based on the input
@@ -141,14 +253,17 @@ func FingerprintURI(parent, uri string) string {
 	return id.Checksum(parent + uri)
 }
 
-// FingerprintWithExt creates a unique uri based on the input
-func FingerprintWithExt(parent, uri string) string {
-	id := id.Checksum(parent + uri)
-	parts := strings.Split(uri, ".")
-	if len(parts) == 0 {
-		return id
+// contentTypeByExtension returns the content type of known text formats based on the file extension, detected otherwise
+func contentTypeByExtension(path, detected string) string {
+	if ct := TextTypeOf(path); ct != "" {
+		return ct
 	}
-	return fmt.Sprintf("%s/%s.%s", parent, id, parts[len(parts)-1])
+	return detected
+}
+
+// TextTypeOf returns the MIME type of a transcript or chapters file based on its extension, or an empty string
+func TextTypeOf(path string) string {
+	return textTypeMap[strings.ToLower(filepath.Ext(path))]
 }
 
 // LocalNamePart returns the part after the last /, if any
@@ -1,7 +1,9 @@
 package metadata
 
 import (
-	"strings"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -10,8 +12,6 @@ import (
 const (
 	testFile     = "testfile.mp3"
 	testFilePath = "./testfile.mp3"
-	testFileExt  = ".mp3"
-	parent       = "abcd"
 )
 
 func TestExtractMetadataFromFile(t *testing.T) {
@@ -29,17 +29,50 @@ func TestExtractMetadataFromFile(t *testing.T) {
 	assert.Greater(t, meta.Timestamp, int64(0))
 	assert.Empty(t, meta.GUID, meta.ParentGUID)
 
-	assert.True(t, meta.IsAudio())
+	assert.Equal(t, MediaClassAudio, meta.MediaClass())
+}
+
+func TestProbeMetadataFromFile(t *testing.T) {
+	si := make([]byte, 34)
+	si[10], si[11], si[12], si[13] = 0x0A, 0xC4, 0x42, 0xF0
+	binary.BigEndian.PutUint32(si[14:18], 441000)
+	path := filepath.Join(t.TempDir(), "episode1.flac")
+	assert.NoError(t, ioutil.WriteFile(path, append([]byte{'f', 'L', 'a', 'C', 0x80, 0, 0, 34}, si...), 0644))
+
+	meta, err := ProbeMetadataFromFile(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "audio/flac", meta.ContentType)
+		assert.Equal(t, int64(10), meta.Duration)
+		assert.Empty(t, meta.Digest) // the content is not hashed
+	}
+
+	meta, err = ExtractMetadataFromFile(path)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(10), meta.Duration)
+		assert.NotEmpty(t, meta.Digest)
+		assert.Equal(t, meta.ETAG(), meta.Etag)
+	}
 }
 
 func TestLocalNamePart(t *testing.T) {
 	assert.Equal(t, LocalNamePart(testFilePath), testFile)
 }
 
-func TestFingerprintWithExt(t *testing.T) {
-	fp := FingerprintWithExt(parent, testFilePath)
+func TestContentTypeByExtension(t *testing.T) {
+	assert.Equal(t, "text/vtt", contentTypeByExtension("abcd/episode1.vtt", "text/plain; charset=utf-8"))
+	assert.Equal(t, "application/x-subrip", contentTypeByExtension("abcd/episode1.SRT", "text/plain; charset=utf-8"))
+	assert.Equal(t, "audio/mpeg", contentTypeByExtension(testFilePath, "audio/mpeg"))
+
+	meta := Metadata{ContentType: "text/vtt"}
+	assert.Equal(t, MediaClassText, meta.MediaClass())
+}
 
-	assert.NotEmpty(t, fp)
-	assert.True(t, strings.HasPrefix(fp, parent))
-	assert.True(t, strings.HasSuffix(fp, testFileExt))
+func TestClassOf(t *testing.T) {
+	assert.Equal(t, MediaClassAudio, ClassOf("audio/x-m4a"))
+	assert.Equal(t, MediaClassAudio, ClassOf("audio/mpeg; charset=binary"))
+	assert.Equal(t, MediaClassVideo, ClassOf("video/quicktime"))
+	assert.Equal(t, MediaClassDocument, ClassOf("application/pdf"))
+	assert.Equal(t, MediaClassImage, ClassOf("image/jpeg"))
+	assert.Equal(t, MediaClassText, ClassOf("application/x-subrip"))
+	assert.Equal(t, MediaClassUnknown, ClassOf(defaultContentType))
 }
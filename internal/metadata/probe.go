@@ -0,0 +1,537 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+
+	"github.com/tcolgate/mp3"
+
+	"github.com/podops/podops/internal/errordef"
+)
+
+const (
+	// ColorModelRGB is used for RGB images, including JPEG's YCbCr
+	ColorModelRGB = "rgb"
+	// ColorModelRGBA is used for RGB images with an alpha channel
+	ColorModelRGBA = "rgba"
+	// ColorModelGray is used for grayscale images, with or without alpha channel
+	ColorModelGray = "gray"
+	// ColorModelIndexed is used for images with a color palette
+	ColorModelIndexed = "indexed"
+	// ColorModelCMYK is used for print images
+	ColorModelCMYK = "cmyk"
+
+	// the part at the end of an Ogg file that is searched for the last page
+	oggTailSize = 65536
+	// MP4 boxes nested deeper than this are not inspected
+	maxBoxDepth = 8
+)
+
+type (
+	// MediaInfo holds the technical metadata of an audio, video or image file
+	MediaInfo struct {
+		ContentType string
+		Duration    float64 // in seconds
+		Bitrate     int64   // average bits per second
+		Channels    int
+		SampleRate  int
+		Width       int
+		Height      int
+		Format      string // the container or image format, e.g. 'mp4' or 'png'
+		ColorModel  string // images only
+	}
+
+	// probeFunc extracts the MediaInfo from a container of a known format
+	probeFunc func(r io.ReadSeeker, size int64) (*MediaInfo, error)
+
+	// mp4Track is what we need to know about a 'trak' box
+	mp4Track struct {
+		handler    string
+		channels   int
+		sampleRate int
+		width      int
+		height     int
+	}
+
+	// mp4Parser walks the box structure of an ISO-BMFF container (MP4, M4A, M4V, MOV)
+	mp4Parser struct {
+		r         io.ReadSeeker
+		brand     string
+		timescale uint32
+		duration  uint64
+		tracks    []*mp4Track
+		current   *mp4Track
+	}
+)
+
+var (
+	pngSignature  = []byte("\x89PNG\r\n\x1a\n")
+	jpegSignature = []byte{0xFF, 0xD8, 0xFF}
+
+	// boxes that can start an ISO-BMFF file
+	mp4TopLevelBoxes = map[string]bool{
+		"ftyp": true,
+		"moov": true,
+		"mdat": true,
+		"wide": true,
+		"free": true,
+		"skip": true,
+	}
+)
+
+// Probe detects the container format of r and extracts its technical metadata.
+//
+// Supported formats are MP3, MP4/M4A/M4V/MOV, Ogg (Opus, Vorbis), FLAC, PNG and JPEG.
+// Probe returns ErrUnsupportedMediaFormat for anything else.
+func Probe(r io.ReadSeeker, size int64) (*MediaInfo, error) {
+	header := make([]byte, 12)
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	n, err := io.ReadFull(r, header)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, errordef.ErrUnsupportedMediaFormat
+	}
+
+	probe := detectFormat(header[:n])
+	if probe == nil {
+		return nil, errordef.ErrUnsupportedMediaFormat
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	info, err := probe(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if info.Bitrate == 0 && info.Duration > 0 {
+		info.Bitrate = int64(float64(size*8) / info.Duration)
+	}
+	return info, nil
+}
+
+// ProbeFile opens the file at path and calls Probe
+func ProbeFile(path string) (*MediaInfo, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	fi, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return Probe(file, fi.Size())
+}
+
+// detectFormat returns the probe matching the magic bytes at the start of a file, or nil
+func detectFormat(header []byte) probeFunc {
+	switch {
+	case bytes.HasPrefix(header, pngSignature):
+		return probePNG
+	case bytes.HasPrefix(header, jpegSignature):
+		return probeJPEG
+	case bytes.HasPrefix(header, []byte("fLaC")):
+		return probeFLAC
+	case bytes.HasPrefix(header, []byte("OggS")):
+		return probeOgg
+	case len(header) >= 8 && mp4TopLevelBoxes[string(header[4:8])]:
+		return probeMP4
+	case bytes.HasPrefix(header, []byte("ID3")):
+		return probeMP3
+	case len(header) >= 2 && header[0] == 0xFF && header[1]&0xE0 == 0xE0: // MPEG frame sync
+		return probeMP3
+	}
+	return nil
+}
+
+// probeMP3 decodes all frames, MP3 has no container that tells the duration.
+// thanks to https://stackoverflow.com/questions/60281655/how-to-find-the-length-of-mp3-file-in-golang
+func probeMP3(r io.ReadSeeker, size int64) (*MediaInfo, error) {
+	info := &MediaInfo{ContentType: "audio/mpeg", Format: "mp3"}
+
+	d := mp3.NewDecoder(r)
+	var f mp3.Frame
+	skipped := 0
+	frames := int64(0)
+	t := 0.0
+
+	for {
+		if err := d.Decode(&f, &skipped); err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				break // a truncated last frame is not an error
+			}
+			return nil, err
+		}
+		if info.SampleRate == 0 {
+			h := f.Header()
+			info.SampleRate = int(h.SampleRate())
+			info.Channels = 2
+			if h.ChannelMode() == mp3.SingleChannel {
+				info.Channels = 1
+			}
+		}
+		frames = frames + int64(f.Size())
+		t = t + f.Duration().Seconds()
+	}
+
+	if t == 0 {
+		return nil, errordef.ErrInvalidMediaFormat
+	}
+	info.Duration = t
+	info.Bitrate = int64(float64(frames*8) / t) // excludes the ID3 tags
+	return info, nil
+}
+
+// probeMP4 reads the movie header for the duration and the sample descriptions of the tracks
+func probeMP4(r io.ReadSeeker, size int64) (*MediaInfo, error) {
+	p := &mp4Parser{
+		r:     r,
+		brand: "qt  ", // very old QuickTime files don't have a 'ftyp' box
+	}
+	if err := p.walk(0, size, 0); err != nil {
+		return nil, err
+	}
+	if p.timescale == 0 {
+		return nil, errordef.ErrInvalidMediaFormat // no 'mvhd' box
+	}
+
+	info := &MediaInfo{
+		ContentType: p.contentType(),
+		Format:      "mp4",
+		Duration:    float64(p.duration) / float64(p.timescale),
+	}
+	for _, t := range p.tracks {
+		if t.handler == "soun" && info.Channels == 0 {
+			info.Channels = t.channels
+			info.SampleRate = t.sampleRate
+		}
+		if t.handler == "vide" && info.Width == 0 {
+			info.Width = t.width
+			info.Height = t.height
+		}
+	}
+	return info, nil
+}
+
+// walk iterates over the boxes between start and end and descends into the containers we are interested in
+func (p *mp4Parser) walk(start, end int64, depth int) error {
+	for pos := start; pos+8 <= end; {
+		hdr, err := p.read(pos, 8)
+		if err != nil {
+			return err
+		}
+		boxSize := int64(binary.BigEndian.Uint32(hdr[0:4]))
+		boxType := string(hdr[4:8])
+		headerSize := int64(8)
+
+		switch boxSize {
+		case 0: // the box extends to the end of the file
+			boxSize = end - pos
+		case 1: // 64bit size
+			ext, err := p.read(pos+8, 8)
+			if err != nil {
+				return err
+			}
+			boxSize = int64(binary.BigEndian.Uint64(ext))
+			headerSize = 16
+		}
+		if boxSize < headerSize {
+			return errordef.ErrInvalidMediaFormat
+		}
+		if pos+boxSize > end {
+			return nil // truncated, use what we have so far
+		}
+		body := pos + headerSize
+
+		switch boxType {
+		case "ftyp":
+			b, err := p.read(body, 4)
+			if err != nil {
+				return err
+			}
+			p.brand = string(b)
+		case "moov", "mdia", "minf", "stbl":
+			if depth < maxBoxDepth {
+				if err := p.walk(body, pos+boxSize, depth+1); err != nil {
+					return err
+				}
+			}
+		case "trak":
+			p.current = &mp4Track{}
+			p.tracks = append(p.tracks, p.current)
+			if depth < maxBoxDepth {
+				if err := p.walk(body, pos+boxSize, depth+1); err != nil {
+					return err
+				}
+			}
+			p.current = nil
+		case "mvhd":
+			if err := p.parseMovieHeader(body); err != nil {
+				return err
+			}
+		case "hdlr":
+			if p.current != nil {
+				// version & flags, pre_defined, handler_type
+				b, err := p.read(body, 12)
+				if err != nil {
+					return err
+				}
+				p.current.handler = string(b[8:12])
+			}
+		case "stsd":
+			if p.current != nil {
+				if err := p.parseSampleDescription(body); err != nil {
+					return err
+				}
+			}
+		}
+
+		pos = pos + boxSize
+	}
+	return nil
+}
+
+func (p *mp4Parser) parseMovieHeader(body int64) error {
+	b, err := p.read(body, 32)
+	if err != nil {
+		return err
+	}
+	if b[0] == 1 {
+		// version 1 uses 64bit creation & modification times and duration
+		p.timescale = binary.BigEndian.Uint32(b[20:24])
+		p.duration = binary.BigEndian.Uint64(b[24:32])
+	} else {
+		p.timescale = binary.BigEndian.Uint32(b[12:16])
+		p.duration = uint64(binary.BigEndian.Uint32(b[16:20]))
+	}
+	return nil
+}
+
+// parseSampleDescription reads the first sample entry of a track
+func (p *mp4Parser) parseSampleDescription(body int64) error {
+	// version & flags, entry_count, entry size & format, reserved, data_reference_index, followed by 20 bytes of the entry
+	b, err := p.read(body, 44)
+	if err != nil {
+		return err
+	}
+	switch p.current.handler {
+	case "soun":
+		// version, revision, vendor, channel count, sample size, compression id, packet size, sample rate (16.16)
+		p.current.channels = int(binary.BigEndian.Uint16(b[32:34]))
+		p.current.sampleRate = int(binary.BigEndian.Uint32(b[40:44]) >> 16)
+	case "vide":
+		// pre_defined & reserved, width, height
+		p.current.width = int(binary.BigEndian.Uint16(b[40:42]))
+		p.current.height = int(binary.BigEndian.Uint16(b[42:44]))
+	}
+	return nil
+}
+
+// contentType maps the major brand and the tracks to the types in the feed's mediaTypeMap
+func (p *mp4Parser) contentType() string {
+	video := false
+	for _, t := range p.tracks {
+		if t.handler == "vide" {
+			video = true
+		}
+	}
+
+	switch {
+	case p.brand == "qt  ":
+		return "video/quicktime"
+	case !video:
+		return "audio/x-m4a"
+	case p.brand == "M4V " || p.brand == "M4VH" || p.brand == "M4VP":
+		return "video/x-m4v"
+	}
+	return "video/mp4"
+}
+
+func (p *mp4Parser) read(pos int64, n int) ([]byte, error) {
+	return readAt(p.r, pos, n)
+}
+
+// probeOgg reads the codec's identification header from the first page and the granule position of the last page
+func probeOgg(r io.ReadSeeker, size int64) (*MediaInfo, error) {
+	buf := make([]byte, 512)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		return nil, errordef.ErrInvalidMediaFormat
+	}
+	buf = buf[:n]
+	if len(buf) < 27 || len(buf) < 27+int(buf[26]) {
+		return nil, errordef.ErrInvalidMediaFormat
+	}
+	packet := buf[27+int(buf[26]):] // skip the page header and segment table
+
+	info := &MediaInfo{ContentType: "audio/ogg", Format: "ogg"}
+	rate := uint64(0)
+	preSkip := uint64(0)
+
+	switch {
+	case bytes.HasPrefix(packet, []byte("OpusHead")) && len(packet) >= 19:
+		info.Channels = int(packet[9])
+		info.SampleRate = int(binary.LittleEndian.Uint32(packet[12:16])) // the rate of the original input
+		preSkip = uint64(binary.LittleEndian.Uint16(packet[10:12]))
+		rate = 48000 // Opus granule positions are always 48kHz
+	case bytes.HasPrefix(packet, []byte("\x01vorbis")) && len(packet) >= 28:
+		info.Channels = int(packet[11])
+		info.SampleRate = int(binary.LittleEndian.Uint32(packet[12:16]))
+		if nominal := int32(binary.LittleEndian.Uint32(packet[20:24])); nominal > 0 {
+			info.Bitrate = int64(nominal)
+		}
+		rate = uint64(info.SampleRate)
+	default:
+		return nil, errordef.ErrUnsupportedMediaFormat
+	}
+	if rate == 0 {
+		return nil, errordef.ErrInvalidMediaFormat
+	}
+
+	// the granule position of the last page is the total number of samples
+	tail := int64(oggTailSize)
+	if size < tail {
+		tail = size
+	}
+	b, err := readAt(r, size-tail, int(tail))
+	if err != nil {
+		return nil, err
+	}
+	for i := bytes.LastIndex(b, []byte("OggS")); i >= 0; i = bytes.LastIndex(b[:i], []byte("OggS")) {
+		if i+14 > len(b) {
+			continue
+		}
+		granule := binary.LittleEndian.Uint64(b[i+6 : i+14])
+		if granule == ^uint64(0) {
+			continue // no packet finishes on this page
+		}
+		if granule > preSkip {
+			info.Duration = float64(granule-preSkip) / float64(rate)
+		}
+		break
+	}
+
+	return info, nil
+}
+
+// probeFLAC reads the STREAMINFO block which is always the first metadata block
+func probeFLAC(r io.ReadSeeker, size int64) (*MediaInfo, error) {
+	b, err := readAt(r, 0, 4+4+34) // marker, block header, STREAMINFO
+	if err != nil {
+		return nil, err
+	}
+	if b[4]&0x7F != 0 {
+		return nil, errordef.ErrInvalidMediaFormat
+	}
+	si := b[8:]
+
+	// 20 bits sample rate, 3 bits channels-1, 5 bits bits per sample-1, 36 bits total samples
+	sampleRate := int(si[10])<<12 | int(si[11])<<4 | int(si[12])>>4
+	channels := int((si[12]>>1)&0x07) + 1
+	samples := uint64(si[13]&0x0F)<<32 | uint64(binary.BigEndian.Uint32(si[14:18]))
+
+	info := &MediaInfo{
+		ContentType: "audio/flac",
+		Format:      "flac",
+		Channels:    channels,
+		SampleRate:  sampleRate,
+	}
+	if sampleRate > 0 {
+		info.Duration = float64(samples) / float64(sampleRate)
+	}
+	return info, nil
+}
+
+// probePNG reads the dimensions and color type from the IHDR chunk which is always the first chunk
+func probePNG(r io.ReadSeeker, size int64) (*MediaInfo, error) {
+	b, err := readAt(r, 0, 26) // signature, chunk length & type, width, height, bit depth, color type
+	if err != nil {
+		return nil, err
+	}
+	if string(b[12:16]) != "IHDR" {
+		return nil, errordef.ErrInvalidMediaFormat
+	}
+
+	info := &MediaInfo{
+		ContentType: "image/png",
+		Format:      "png",
+		Width:       int(binary.BigEndian.Uint32(b[16:20])),
+		Height:      int(binary.BigEndian.Uint32(b[20:24])),
+	}
+	switch b[25] {
+	case 0, 4:
+		info.ColorModel = ColorModelGray
+	case 2:
+		info.ColorModel = ColorModelRGB
+	case 3:
+		info.ColorModel = ColorModelIndexed
+	case 6:
+		info.ColorModel = ColorModelRGBA
+	}
+	return info, nil
+}
+
+// probeJPEG skips all segments until the start of frame
+func probeJPEG(r io.ReadSeeker, size int64) (*MediaInfo, error) {
+	for pos := int64(2); pos+4 <= size; {
+		b, err := readAt(r, pos, 4)
+		if err != nil {
+			return nil, err
+		}
+		if b[0] != 0xFF {
+			return nil, errordef.ErrInvalidMediaFormat
+		}
+
+		marker := b[1]
+		switch {
+		case marker == 0xFF: // fill byte
+			pos++
+			continue
+		case marker == 0x01 || (marker >= 0xD0 && marker <= 0xD7): // markers without a length
+			pos = pos + 2
+			continue
+		case marker == 0xD9 || marker == 0xDA: // end of image or start of scan before a frame
+			return nil, errordef.ErrInvalidMediaFormat
+		case marker >= 0xC0 && marker <= 0xCF && marker != 0xC4 && marker != 0xC8 && marker != 0xCC:
+			// length, precision, height, width, number of components
+			sof, err := readAt(r, pos+4, 6)
+			if err != nil {
+				return nil, err
+			}
+			info := &MediaInfo{
+				ContentType: "image/jpeg",
+				Format:      "jpeg",
+				Height:      int(binary.BigEndian.Uint16(sof[1:3])),
+				Width:       int(binary.BigEndian.Uint16(sof[3:5])),
+			}
+			switch sof[5] {
+			case 1:
+				info.ColorModel = ColorModelGray
+			case 3:
+				info.ColorModel = ColorModelRGB
+			case 4:
+				info.ColorModel = ColorModelCMYK
+			}
+			return info, nil
+		}
+		pos = pos + 2 + int64(binary.BigEndian.Uint16(b[2:4]))
+	}
+	return nil, errordef.ErrInvalidMediaFormat
+}
+
+// readAt reads exactly n bytes at pos
+func readAt(r io.ReadSeeker, pos int64, n int) ([]byte, error) {
+	if _, err := r.Seek(pos, io.SeekStart); err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, errordef.ErrInvalidMediaFormat
+	}
+	return b, nil
+}
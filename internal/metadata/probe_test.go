@@ -0,0 +1,114 @@
+package metadata
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/podops/podops/internal/errordef"
+)
+
+// box creates an ISO-BMFF box
+func box(typ string, payload ...[]byte) []byte {
+	body := bytes.Join(payload, nil)
+	b := make([]byte, 8, 8+len(body))
+	binary.BigEndian.PutUint32(b, uint32(8+len(body)))
+	copy(b[4:], typ)
+	return append(b, body...)
+}
+
+func be32(v uint32) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, v)
+	return b
+}
+
+func probeBytes(data []byte) (*MediaInfo, error) {
+	return Probe(bytes.NewReader(data), int64(len(data)))
+}
+
+func TestProbeMP4(t *testing.T) {
+	mvhd := bytes.Join([][]byte{make([]byte, 12), be32(1000), be32(90500), make([]byte, 80)}, nil) // 90.5s
+	hdlr := bytes.Join([][]byte{make([]byte, 8), []byte("soun"), make([]byte, 12)}, nil)
+	entry := bytes.Join([][]byte{make([]byte, 8), {0, 2, 0, 16}, make([]byte, 4), be32(44100 << 16)}, nil)
+	stsd := bytes.Join([][]byte{make([]byte, 4), be32(1), box("mp4a", make([]byte, 8), entry)}, nil)
+
+	data := bytes.Join([][]byte{
+		box("ftyp", []byte("M4A "), make([]byte, 4)),
+		box("moov",
+			box("mvhd", mvhd),
+			box("trak", box("mdia", box("hdlr", hdlr), box("minf", box("stbl", box("stsd", stsd)))))),
+		box("mdat", make([]byte, 1024)),
+	}, nil)
+
+	info, err := probeBytes(data)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "audio/x-m4a", info.ContentType)
+		assert.Equal(t, 90.5, info.Duration)
+		assert.Equal(t, 2, info.Channels)
+		assert.Equal(t, 44100, info.SampleRate)
+		assert.Greater(t, info.Bitrate, int64(0))
+	}
+}
+
+func TestProbeOgg(t *testing.T) {
+	page := func(granule uint64, packet []byte) []byte {
+		h := make([]byte, 27)
+		copy(h, "OggS")
+		binary.LittleEndian.PutUint64(h[6:14], granule)
+		h[26] = 1
+		return append(append(h, byte(len(packet))), packet...)
+	}
+	head := append([]byte("OpusHead"), 1, 2, 0x38, 0x01, 0x80, 0xBB, 0, 0, 0, 0, 0) // 2 channels, pre-skip 312, 48kHz
+	data := append(page(0, head), page(48000*60+312, make([]byte, 100))...)
+
+	info, err := probeBytes(data)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "audio/ogg", info.ContentType)
+		assert.Equal(t, 60.0, info.Duration)
+		assert.Equal(t, 2, info.Channels)
+		assert.Equal(t, 48000, info.SampleRate)
+	}
+}
+
+func TestProbeFLAC(t *testing.T) {
+	si := make([]byte, 34)
+	// 44100Hz, 2 channels, 16 bits per sample, 441000 samples
+	si[10], si[11], si[12], si[13] = 0x0A, 0xC4, 0x42, 0xF0
+	binary.BigEndian.PutUint32(si[14:18], 441000)
+	data := append([]byte{'f', 'L', 'a', 'C', 0x80, 0, 0, 34}, si...)
+
+	info, err := probeBytes(data)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "audio/flac", info.ContentType)
+		assert.Equal(t, 10.0, info.Duration)
+		assert.Equal(t, 2, info.Channels)
+		assert.Equal(t, 44100, info.SampleRate)
+	}
+}
+
+func TestProbeImages(t *testing.T) {
+	png := bytes.Join([][]byte{pngSignature, be32(13), []byte("IHDR"), be32(3000), be32(3000), {8, 2, 0, 0, 0}}, nil)
+	info, err := probeBytes(png)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "image/png", info.ContentType)
+		assert.Equal(t, 3000, info.Width)
+		assert.Equal(t, 3000, info.Height)
+		assert.Equal(t, ColorModelRGB, info.ColorModel)
+	}
+
+	// SOI, APP0 with 4 bytes payload, SOF0 with 1400x1600
+	jpeg := []byte{0xFF, 0xD8, 0xFF, 0xE0, 0, 6, 0, 0, 0, 0, 0xFF, 0xC0, 0, 17, 8, 0x06, 0x40, 0x05, 0x78, 3}
+	info, err = probeBytes(jpeg)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "image/jpeg", info.ContentType)
+		assert.Equal(t, 1400, info.Width)
+		assert.Equal(t, 1600, info.Height)
+		assert.Equal(t, "jpeg", info.Format)
+	}
+
+	_, err = probeBytes([]byte("WEBVTT\n\n00:00.000 --> 00:01.000\nHello"))
+	assert.Equal(t, errordef.ErrUnsupportedMediaFormat, err)
+}
@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
 	"net/http"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/txsvc/platform/v2/pkg/api"
 
@@ -31,6 +33,15 @@ const (
 	fixVersion = 2
 )
 
+const (
+	// HeaderETag carries the version of a resource in a response
+	HeaderETag = "ETag"
+	// HeaderIfMatch carries the version of a resource a request expects
+	HeaderIfMatch = "If-Match"
+	// HeaderIfNoneMatch carries the versions of a resource a client already has
+	HeaderIfNoneMatch = "If-None-Match"
+)
+
 var (
 	// UserAgentString identifies any http request podops makes
 	UserAgentString string = fmt.Sprintf("PodOps %d.%d.%d", majorVersion, minorVersion, fixVersion)
@@ -106,6 +117,30 @@ func Delete(url, cmd, token string, request interface{}) (int, error) {
 
 }
 
+// Exchange is used to invoke an API method with additional request headers. It returns the response headers too.
+func Exchange(method, url, cmd, token string, header http.Header, request, response interface{}) (int, http.Header, error) {
+	uri := url + cmd
+
+	var body io.Reader
+	if request != nil {
+		m, err := json.Marshal(&request)
+		if err != nil {
+			return http.StatusInternalServerError, nil, err
+		}
+		body = bytes.NewBuffer(m)
+	}
+
+	req, err := http.NewRequest(method, uri, body)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+
+	return do(token, req, response)
+}
+
 // GITHUB_ISSUE #13
 
 // Creates a new file upload http request with optional extra params
@@ -142,7 +177,50 @@ func Upload(url, cmd, token, guid, form, path string) (*http.Request, error) {
 	return req, err
 }
 
+// Send is used for requests with a raw body and protocol headers, e.g. chunks of an upload. It returns the response headers.
+func Send(method, uri, token string, header http.Header, body io.Reader) (int, http.Header, error) {
+	req, err := http.NewRequest(method, uri, body)
+	if err != nil {
+		return http.StatusBadRequest, nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("User-Agent", UserAgentString)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{}
+	resp, err := client.Do(req)
+	if err != nil {
+		return http.StatusInternalServerError, nil, err
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	return resp.StatusCode, resp.Header, nil
+}
+
+// ParseETags returns the entity tags of an If-Match or If-None-Match header without quotes.
+// Weak tags are treated like strong ones, '*' is returned as is.
+func ParseETags(header string) []string {
+	var tags []string
+
+	for _, tag := range strings.Split(header, ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			tags = append(tags, strings.Trim(strings.TrimPrefix(tag, "W/"), "\""))
+		}
+	}
+	return tags
+}
+
 func invoke(token string, req *http.Request, response interface{}) (int, error) {
+	status, _, err := do(token, req, response)
+	return status, err
+}
+
+func do(token string, req *http.Request, response interface{}) (int, http.Header, error) {
 
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 	req.Header.Set("User-Agent", UserAgentString)
@@ -155,9 +233,9 @@ func invoke(token string, req *http.Request, response interface{}) (int, error)
 	resp, err := client.Do(req)
 	if err != nil {
 		if resp == nil {
-			return http.StatusInternalServerError, err
+			return http.StatusInternalServerError, nil, err
 		}
-		return resp.StatusCode, err
+		return resp.StatusCode, nil, err
 	}
 
 	defer resp.Body.Close()
@@ -168,10 +246,10 @@ func invoke(token string, req *http.Request, response interface{}) (int, error)
 			// as we expect a response, there might be a StatusObject
 			status := api.StatusObject{}
 			err = json.NewDecoder(resp.Body).Decode(&status)
-			if err != nil {
-				return resp.StatusCode, fmt.Errorf(messagedef.MsgStatus, resp.StatusCode)
+			if err != nil || status.Status == 0 {
+				return resp.StatusCode, resp.Header, fmt.Errorf(messagedef.MsgStatus, resp.StatusCode)
 			}
-			return status.Status, fmt.Errorf(status.Message)
+			return status.Status, resp.Header, fmt.Errorf(status.Message)
 		}
 	}
 
@@ -179,9 +257,9 @@ func invoke(token string, req *http.Request, response interface{}) (int, error)
 	if response != nil {
 		err = json.NewDecoder(resp.Body).Decode(response)
 		if err != nil {
-			return http.StatusInternalServerError, err
+			return http.StatusInternalServerError, resp.Header, err
 		}
 	}
 
-	return resp.StatusCode, nil
+	return resp.StatusCode, resp.Header, nil
 }
@@ -0,0 +1,14 @@
+package transport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseETags(t *testing.T) {
+	assert.Empty(t, ParseETags(""))
+	assert.Equal(t, []string{"abc"}, ParseETags(`"abc"`))
+	assert.Equal(t, []string{"abc", "def"}, ParseETags(`"abc", W/"def"`))
+	assert.Equal(t, []string{"*"}, ParseETags("*"))
+}
@@ -12,12 +12,24 @@ const (
 	AssertionWarning = 0
 	// AssertionError indicates an error in the validation
 	AssertionError = 1
+
+	// MinArtworkSize is the minimum width and height of cover art
+	MinArtworkSize = 1400
+	// MaxArtworkSize is the maximum width and height of cover art
+	MaxArtworkSize = 3000
+)
+
+var (
+	// the image formats and color models Apple accepts for cover art
+	artworkFormats     = []string{"jpeg", "png"}
+	artworkColorModels = []string{"rgb", "rgba"}
 )
 
 type (
 	// Assertion is used to collect validation information
 	Assertion struct {
 		Type int    // 0 == warning, 1 == error
+		Rule string // ID of the compliance rule, empty for basic integrity checks
 		Txt  string // description of the problem
 		Err  error
 	}
@@ -59,7 +71,17 @@ func (v *Validator) AssertError(txt string) {
 // AssertWarning add an warning assertion
 func (v *Validator) AssertWarning(txt string) {
 	v.Issues = append(v.Issues, &Assertion{Type: AssertionWarning, Txt: txt})
-	v.Errors++
+	v.Warnings++
+}
+
+// AssertRule adds an assertion that violates a compliance rule
+func (v *Validator) AssertRule(rule string, severity int, txt string) {
+	v.Issues = append(v.Issues, &Assertion{Type: severity, Rule: rule, Txt: txt})
+	if severity == AssertionError {
+		v.Errors++
+	} else {
+		v.Warnings++
+	}
 }
 
 // AssertStringError verifies a string
@@ -104,11 +126,13 @@ func (v *Validator) AssertNotZero(src int, name string) {
 
 // AssertISO639 verifies that src complies with ISO 639-1
 func (v *Validator) AssertISO639(src string) {
-	lang := src
-	if !strings.Contains(src, "_") {
-		lang = src + "_" + strings.ToUpper(src)
+	valid := false
+	if strings.Contains(src, "_") {
+		valid = langreg.IsValidLangRegCode(src)
+	} else {
+		valid = langreg.IsValidLanguageCode(src)
 	}
-	if !langreg.IsValidLangRegCode(lang) {
+	if !valid {
 		v.AssertError(fmt.Sprintf("Invalid language code '%s'", src))
 	}
 }
@@ -124,6 +148,50 @@ func (v *Validator) AssertContains(src map[string]string, key, name string) {
 	}
 }
 
+// AssertInList verifies that src is one of the values in list
+func (v *Validator) AssertInList(src string, list []string, name string) {
+	for _, s := range list {
+		if src == s {
+			return
+		}
+	}
+	v.AssertError(fmt.Sprintf("Expected one of '%s' for attribute '%s', found '%s'", strings.Join(list, ", "), name, src))
+}
+
+// AssertArtwork verifies that an image complies with Apple's rules for cover art:
+// JPEG or PNG, RGB color model, square and between 1400x1400 and 3000x3000 pixels.
+func (v *Validator) AssertArtwork(width, height int, format, colorModel, name string) {
+	if width == 0 || height == 0 {
+		v.AssertWarning(fmt.Sprintf("Can't verify the dimensions of artwork '%s'", name))
+	} else {
+		if width != height {
+			v.AssertError(fmt.Sprintf("Expected square artwork '%s', found %dx%d", name, width, height))
+		}
+		if width < MinArtworkSize || height < MinArtworkSize {
+			v.AssertError(fmt.Sprintf("Expected artwork '%s' of at least %dx%d, found %dx%d", name, MinArtworkSize, MinArtworkSize, width, height))
+		}
+		if width > MaxArtworkSize || height > MaxArtworkSize {
+			v.AssertError(fmt.Sprintf("Expected artwork '%s' of at most %dx%d, found %dx%d", name, MaxArtworkSize, MaxArtworkSize, width, height))
+		}
+	}
+
+	if format != "" {
+		v.AssertInList(format, artworkFormats, name)
+	}
+	if colorModel == "" {
+		v.AssertWarning(fmt.Sprintf("Can't verify the color model of artwork '%s'", name))
+	} else if colorModel == "cmyk" {
+		v.AssertError(fmt.Sprintf("Expected RGB artwork '%s', found '%s'", name, colorModel))
+	} else {
+		for _, cm := range artworkColorModels {
+			if colorModel == cm {
+				return
+			}
+		}
+		v.AssertWarning(fmt.Sprintf("Expected RGB artwork '%s', found '%s'", name, colorModel))
+	}
+}
+
 // AssertExistsError verifies that a struct exists
 func (v *Validator) AssertExistsError(src interface{}, expected string) {
 	if src == nil {
@@ -167,12 +235,16 @@ func (v *Validator) Error() string {
 
 // Report returns a description of all issues
 func (v *Validator) Report() string {
-	if v.Errors == 0 {
-		return "validation '%s' has zero errors/warnings"
+	if len(v.Issues) == 0 {
+		return fmt.Sprintf("validation '%s' has zero errors/warnings", v.Name)
 	}
 	r := "\n"
 	for i, issue := range v.Issues {
-		r = r + fmt.Sprintf("Issue %d: %s\n", i+1, issue.Txt)
+		if issue.Rule == "" {
+			r = r + fmt.Sprintf("Issue %d: %s\n", i+1, issue.Txt)
+		} else {
+			r = r + fmt.Sprintf("Issue %d: [%s] %s\n", i+1, issue.Rule, issue.Txt)
+		}
 	}
 	return r
 }
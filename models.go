@@ -1,10 +1,92 @@
 package podops
 
 import (
+	"encoding/base64"
 	"fmt"
 )
 
+const (
+	// SeverityError the issue prevents the feed from being accepted by a directory
+	SeverityError = "error"
+	// SeverityWarning the issue should be fixed but does not invalidate the feed
+	SeverityWarning = "warning"
+
+	// BuildQueued the build is waiting to be picked up by a worker
+	BuildQueued = "queued"
+	// BuildRunning the build is in progress
+	BuildRunning = "running"
+	// BuildSucceeded the feed was built and published
+	BuildSucceeded = "succeeded"
+	// BuildFailed the build did not produce a feed, see its issues
+	BuildFailed = "failed"
+
+	// RoleOwner can do everything, including managing members, subscribers and the production itself
+	RoleOwner = "owner"
+	// RolePublisher can edit resources and build the feed
+	RolePublisher = "publisher"
+	// RoleEditor can edit resources
+	RoleEditor = "editor"
+	// RoleViewer can read the production and its resources
+	RoleViewer = "viewer"
+
+	// EventBuildSucceeded a build published the feed
+	EventBuildSucceeded = "build.succeeded"
+	// EventBuildFailed a build did not produce a feed
+	EventBuildFailed = "build.failed"
+	// EventEpisodePublished an episode is part of the feed for the first time
+	EventEpisodePublished = "episode.published"
+	// EventResourceUpdated a show or episode was created or changed
+	EventResourceUpdated = "resource.updated"
+	// EventAssetImported an asset was imported into the CDN
+	EventAssetImported = "asset.imported"
+	// EventAssetDeleted an asset was moved to the trash
+	EventAssetDeleted = "asset.deleted"
+
+	// IntegrityCorrupted the content of a file on the CDN does not match its digest
+	IntegrityCorrupted = "corrupted"
+	// IntegrityMissing a file in the inventory is not on the CDN
+	IntegrityMissing = "missing"
+	// IntegrityUntracked a file on the CDN is neither in the inventory nor in the production bucket
+	IntegrityUntracked = "untracked"
+	// IntegrityDrift a file on the CDN differs from its copy in the production bucket
+	IntegrityDrift = "drift"
+	// IntegrityUnverified a file in the inventory has no digest, e.g. it was added before digests were recorded
+	IntegrityUnverified = "unverified"
+	// IntegrityOrphan an entity or file is not referenced by the other places that track the same asset
+	IntegrityOrphan = "orphan"
+	// IntegritySize the size of a file differs from its size in the inventory or the production bucket
+	IntegritySize = "size"
+
+	// StoreInventory the RESOURCES and METADATA entities in the datastore
+	StoreInventory = "inventory"
+	// StoreBucket the production bucket
+	StoreBucket = "bucket"
+	// StoreCDN the files on the CDN host
+	StoreCDN = "cdn"
+
+	// TusVersion is the version of the tus protocol the CDN implements for resumable uploads
+	TusVersion = "1.0.0"
+	// UploadChunkSize is the size of the chunks the client sends
+	UploadChunkSize = 8 * 1024 * 1024
+	// ChecksumAlgorithm is the only algorithm supported in Upload-Checksum headers
+	ChecksumAlgorithm = "sha256"
+	// StatusChecksumMismatch is returned if a chunk or the complete upload does not match its checksum
+	StatusChecksumMismatch = 460
+
+	// headers of the tus protocol
+	HeaderTusResumable   = "Tus-Resumable"
+	HeaderUploadOffset   = "Upload-Offset"
+	HeaderUploadLength   = "Upload-Length"
+	HeaderUploadMetadata = "Upload-Metadata"
+	HeaderUploadChecksum = "Upload-Checksum"
+	// ContentTypeOffsetStream is the content type of uploaded chunks
+	ContentTypeOffsetStream = "application/offset+octet-stream"
+)
+
 type (
+	// UploadProgress is called after each chunk of an upload with the number of bytes the CDN has received
+	UploadProgress func(offset, length int64)
+
 	// Production is the parent struct of all other resources.
 	Production struct {
 		Name    string `json:"name" binding:"required"`
@@ -15,10 +97,18 @@ type (
 		// metadata
 		Published         bool  `json:"published"`           // the production is only visible if TRUE
 		LatestPublishDate int64 `json:"latest_publish_date"` // the timestamp of the most recent published episode
+		NextPublishDate   int64 `json:"next_publish_date"`   // the timestamp of the next scheduled episode, 0 if there is none
 		BuildDate         int64 `json:"build_date"`          // the timestamp of the build
+		Private           bool  `json:"private"`             // TRUE if the show is only available to subscribers
+		Archived          bool  `json:"archived"`            // TRUE if the production is read-only and its feed is frozen
+		Deleted           int64 `json:"deleted,omitempty"`   // the timestamp the production was moved to the trash, 0 if it wasn't
+		Expires           int64 `json:"expires,omitempty"`   // a deleted production is purged after this
+		// earlier names of the production, their feed URLs redirect to the current name
+		Aliases []string `json:"aliases,omitempty"`
 		// internal
-		Created int64 `json:"-"`
-		Updated int64 `json:"-"`
+		PrivateAssets []string `json:"-"` // enclosures that require a subscriber token
+		Created       int64    `json:"-"`
+		Updated       int64    `json:"-"`
 	}
 
 	// ProductionList returns a list of productions
@@ -26,6 +116,13 @@ type (
 		Productions []*Production `json:"productions" `
 	}
 
+	// ProductionRequest renames a production or transfers it to another account
+	ProductionRequest struct {
+		Name   string `json:"name,omitempty"`    // the new name
+		Realm  string `json:"realm,omitempty"`   // the realm of the new owner
+		UserID string `json:"user_id,omitempty"` // the new owner, e.g. an email
+	}
+
 	// Resource is used to maintain a repository of all existing resources across all shows
 	Resource struct {
 		Name       string `json:"name"`
@@ -42,6 +139,9 @@ type (
 		EnclosureRel string `json:"enclosure_rel"` // local, import, external
 		ImageURI     string `json:"image"`         // used in show, episode
 		ImageRel     string `json:"image_rel"`     // local, import, external
+		// transcripts & chapters
+		TranscriptURIs []string `json:"transcripts,omitempty"` // used in episode
+		ChaptersURI    string   `json:"chapters,omitempty"`    // used in episode
 		// internal
 		Index   int   `json:"index"` // A running number that can be used to sort resources, e.g. episode number
 		Created int64 `json:"-"`
@@ -53,13 +153,263 @@ type (
 		Resources []*Resource `json:"resources" `
 	}
 
+	// Revision is a version of a show or episode .yaml, kept whenever the resource is written
+	Revision struct {
+		GUID       string `json:"guid"` // the show or episode
+		ParentGUID string `json:"parent_guid"`
+		Revision   int    `json:"revision"` // 1, 2, 3 ...
+		Author     string `json:"author"`   // the client ID of the account that wrote the revision
+		Hash       string `json:"hash"`     // SHA-256 of the content
+		Location   string `json:"-"`        // path to the content in the production bucket
+		Created    int64  `json:"created"`
+	}
+
+	// RevisionList returns a list of revisions
+	RevisionList struct {
+		Revisions []*Revision `json:"revisions"`
+	}
+
+	// RevisionDiff is the unified diff of two revisions of a resource
+	RevisionDiff struct {
+		GUID string `json:"guid"`
+		From int    `json:"from"`
+		To   int    `json:"to"`
+		Diff string `json:"diff"` // empty if the revisions are identical
+	}
+
+	// TrashItem is a deleted resource that can be restored until it is purged
+	TrashItem struct {
+		GUID       string   `json:"guid"`
+		ParentGUID string   `json:"parent_guid"`
+		Kind       string   `json:"kind"`
+		Name       string   `json:"name"`
+		DeletedBy  string   `json:"deleted_by"` // the client ID of the account that deleted the resource
+		Location   string   `json:"-"`          // path to the .yaml or asset while in the trash
+		Resource   Resource `json:"-"`          // the inventory entry at the time of deletion
+		Deleted    int64    `json:"deleted"`
+		Expires    int64    `json:"expires"` // the resource is purged after this
+	}
+
+	// TrashList returns a list of deleted resources
+	TrashList struct {
+		Items []*TrashItem `json:"items"`
+	}
+
 	// BuildRequest initiates the build of the feed
 	BuildRequest struct {
 		GUID         string `json:"guid" binding:"required"`
+		ID           string `json:"id,omitempty"` // the build job, set by the API
 		FeedURL      string `json:"feed"`
 		FeedAliasURL string `json:"alias"`
 	}
 
+	// Build is a build job of a production's feed
+	Build struct {
+		ID           string      `json:"id"`
+		GUID         string      `json:"guid"`
+		State        string      `json:"state"` // queued, running, succeeded, failed
+		ValidateOnly bool        `json:"validate_only"`
+		Strict       bool        `json:"strict"`            // verify all enclosures and images before publishing
+		Message      string      `json:"message,omitempty"` // the reason a build failed
+		Errors       int         `json:"errors"`
+		Warnings     int         `json:"warnings"`
+		Issues       []LintIssue `json:"issues"`
+		FeedURL      string      `json:"feed"`
+		FeedAliasURL string      `json:"alias"`
+		Artifact     string      `json:"artifact,omitempty"`    // location of the feed.xml generated by the build
+		RollbackOf   string      `json:"rollback_of,omitempty"` // the build that was republished, if this is a rollback
+		Created      int64       `json:"created"`
+		Started      int64       `json:"started"`
+		Finished     int64       `json:"finished"`
+		// the state of the production after a successful build, restored on rollback
+		Private           bool     `json:"private"`
+		PrivateAssets     []string `json:"-"`
+		BuildDate         int64    `json:"build_date"`
+		LatestPublishDate int64    `json:"latest_publish_date"`
+	}
+
+	// BuildList returns a list of builds
+	BuildList struct {
+		Builds []*Build `json:"builds"`
+	}
+
+	// FeedImportRequest creates the show and episodes of a production from an existing feed
+	FeedImportRequest struct {
+		GUID    string `json:"guid" binding:"required"`
+		FeedURL string `json:"feed" binding:"required"`
+	}
+
+	// Subscription grants a subscriber access to the private feed of a production
+	Subscription struct {
+		Token   string `json:"token"`
+		GUID    string `json:"guid" binding:"required"` // the production
+		Name    string `json:"name" binding:"required"` // e.g. the subscriber's email
+		FeedURL string `json:"feed"`
+		Created int64  `json:"created"`
+	}
+
+	// SubscriptionList returns a list of subscriptions
+	SubscriptionList struct {
+		Subscriptions []*Subscription `json:"subscriptions"`
+	}
+
+	// HubSubscription is a subscription to the feed of a production at the built-in WebSub hub
+	HubSubscription struct {
+		ID       string `json:"id"`
+		GUID     string `json:"guid"`  // the production
+		Topic    string `json:"topic"` // the feed URL the subscriber asked for
+		Callback string `json:"callback"`
+		Secret   string `json:"-"`
+		Expires  int64  `json:"expires"`
+		Created  int64  `json:"created"`
+	}
+
+	// HubDeliveryRequest queues the delivery of a feed to a subscriber of the built-in hub
+	HubDeliveryRequest struct {
+		SubscriptionID string `json:"subscription" binding:"required"`
+	}
+
+	// CheckRequest queues a check of the files of a production on the CDN
+	CheckRequest struct {
+		GUID    string `json:"guid" binding:"required"`
+		Created int64  `json:"created"` // when the check was requested
+	}
+
+	// Member grants an account access to a production with a role
+	Member struct {
+		GUID      string `json:"guid" binding:"required"`    // the production
+		UserID    string `json:"user_id" binding:"required"` // the member's email
+		Realm     string `json:"realm,omitempty"`
+		Role      string `json:"role" binding:"required"` // owner, publisher, editor or viewer
+		ClientID  string `json:"-"`
+		InvitedBy string `json:"invited_by,omitempty"` // the user id of who added the member
+		Created   int64  `json:"created"`
+	}
+
+	// MemberList returns a list of members
+	MemberList struct {
+		Members []*Member `json:"members"`
+	}
+
+	// Webhook subscribes a URL to the events of a production
+	Webhook struct {
+		ID      string   `json:"id"`
+		GUID    string   `json:"guid" binding:"required"` // the production
+		URL     string   `json:"url" binding:"required"`
+		Events  []string `json:"events,omitempty"` // all events if empty
+		Secret  string   `json:"secret,omitempty"` // signs the payloads, only returned when the webhook is created
+		Created int64    `json:"created"`
+	}
+
+	// WebhookList returns a list of webhooks
+	WebhookList struct {
+		Webhooks []*Webhook `json:"webhooks"`
+	}
+
+	// WebhookEvent is the payload sent to a webhook
+	WebhookEvent struct {
+		ID       string      `json:"id"`
+		Event    string      `json:"event"`
+		GUID     string      `json:"guid"`               // the production
+		Resource string      `json:"resource,omitempty"` // the build, episode or asset the event is about
+		Data     interface{} `json:"data,omitempty"`
+		Created  int64       `json:"created"`
+	}
+
+	// WebhookDeliveryRequest queues the delivery of an event to a webhook
+	WebhookDeliveryRequest struct {
+		WebhookID string        `json:"webhook" binding:"required"`
+		Event     *WebhookEvent `json:"event" binding:"required"`
+	}
+
+	// WebhookDelivery records an attempt to deliver an event to a webhook
+	WebhookDelivery struct {
+		ID        string `json:"id"`
+		GUID      string `json:"guid"` // the production
+		WebhookID string `json:"webhook"`
+		URL       string `json:"url"`
+		EventID   string `json:"event_id"`
+		Event     string `json:"event"`
+		Attempt   int    `json:"attempt"`
+		Status    int    `json:"status"` // the HTTP status of the response, 0 if the URL could not be reached
+		Message   string `json:"message,omitempty"`
+		Created   int64  `json:"created"`
+	}
+
+	// WebhookDeliveryList returns a list of deliveries
+	WebhookDeliveryList struct {
+		Deliveries []*WebhookDelivery `json:"deliveries"`
+	}
+
+	// DownloadStats are the downloads of an episode on one day
+	DownloadStats struct {
+		GUID      string `json:"guid"`    // the production
+		Episode   string `json:"episode"` // guid of the episode
+		Name      string `json:"name"`    // name of the episode
+		Asset     string `json:"asset"`   // the enclosure in the CDN
+		Day       string `json:"day"`     // e.g. 2021-03-01, UTC
+		Downloads int64  `json:"downloads"`
+		Requests  int64  `json:"requests"` // all requests, including range requests and bots
+		Bots      int64  `json:"bots"`
+		Bytes     int64  `json:"bytes"`
+	}
+
+	// DownloadStatsList returns a list of download statistics
+	DownloadStatsList struct {
+		Stats []*DownloadStats `json:"stats"`
+	}
+
+	// LintReport is the result of checking a production against the compliance rules
+	LintReport struct {
+		GUID     string       `json:"guid"`
+		Errors   int          `json:"errors"`
+		Warnings int          `json:"warnings"`
+		Issues   []*LintIssue `json:"issues"`
+	}
+
+	// LintIssue is a single violation of a compliance rule
+	LintIssue struct {
+		Rule     string `json:"rule"`
+		Severity string `json:"severity"` // error, warning
+		Message  string `json:"message"`
+	}
+
+	// IntegrityReport is the result of re-hashing the files of a production on the CDN
+	IntegrityReport struct {
+		GUID     string            `json:"guid"`
+		Created  int64             `json:"created"`
+		Finished int64             `json:"finished"` // 0 while the check is running
+		Checked  int               `json:"checked"`
+		Issues   []*IntegrityIssue `json:"issues"`
+	}
+
+	// IntegrityIssue is a file whose content does not match the inventory or the production bucket
+	IntegrityIssue struct {
+		Name     string `json:"name"`
+		Problem  string `json:"problem"`
+		Expected string `json:"expected,omitempty"`
+		Actual   string `json:"actual,omitempty"`
+	}
+
+	// ReconciliationReport is the result of comparing the inventory, the production bucket and the CDN
+	ReconciliationReport struct {
+		GUID     string                 `json:"guid"`
+		Created  int64                  `json:"created"`
+		Finished int64                  `json:"finished"` // 0 while the repair is running
+		Checked  int                    `json:"checked"`
+		Repaired int                    `json:"repaired"`
+		Issues   []*ReconciliationIssue `json:"issues"`
+	}
+
+	// ReconciliationIssue is an asset or resource that is out of sync between the places that track it
+	ReconciliationIssue struct {
+		Store    string `json:"store"` // where the problem is: inventory, bucket, cdn
+		Name     string `json:"name"`
+		Problem  string `json:"problem"` // orphan, missing, size
+		Message  string `json:"message"`
+		Repaired bool   `json:"repaired"`
+	}
+
 	// SyncRequest is used by the import and sync task
 	SyncRequest struct {
 		GUID   string `json:"guid" binding:"required"`
@@ -79,3 +429,18 @@ func (r *Resource) GetPublicLocation() string {
 	}
 	return ""
 }
+
+// IsFinished returns true once the build succeeded or failed
+func (b *Build) IsFinished() bool {
+	return b.State == BuildSucceeded || b.State == BuildFailed
+}
+
+// IsPublished returns true if the build succeeded and published its feed
+func (b *Build) IsPublished() bool {
+	return b.State == BuildSucceeded && !b.ValidateOnly
+}
+
+// FormatChecksum returns the value of an Upload-Checksum header for a SHA-256 sum
+func FormatChecksum(sum []byte) string {
+	return ChecksumAlgorithm + " " + base64.StdEncoding.EncodeToString(sum)
+}
@@ -2,9 +2,16 @@ package podops
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
 	"fmt"
-	"log"
+	"io"
 	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 
 	"github.com/txsvc/platform/v2/pkg/api"
 
@@ -21,6 +28,12 @@ const (
 	productionRoute = NamespacePrefix + "/production"
 	// listProductionsRoute route to call ListProductionsEndpoint
 	listProductionsRoute = NamespacePrefix + "/productions"
+	// updateProductionRoute route to call RenameProductionEndpoint and DeleteProductionEndpoint
+	updateProductionRoute = NamespacePrefix + "/production/%s"
+	// archiveProductionRoute route to call ArchiveProductionEndpoint
+	archiveProductionRoute = NamespacePrefix + "/production/%s/archive"
+	// transferProductionRoute route to call TransferProductionEndpoint
+	transferProductionRoute = NamespacePrefix + "/production/%s/transfer"
 
 	// resourceRoute route to call ResourceEndpoint
 	findResourceRoute   = NamespacePrefix + "/resource/%s"            // "/get/:id"
@@ -28,11 +41,61 @@ const (
 	updateResourceRoute = NamespacePrefix + "/resource/%s/%s/%s?f=%v" // "/update/:prod/:kind/:id"
 	listResourcesRoute  = NamespacePrefix + "/resource/%s/%s"
 	deleteResourceRoute = NamespacePrefix + "/resource/%s/%s/%s"
+	// revisions of a resource
+	listRevisionsRoute   = NamespacePrefix + "/resource/%s/history"
+	diffRevisionsRoute   = NamespacePrefix + "/resource/%s/diff/%d/%d"
+	restoreRevisionRoute = NamespacePrefix + "/resource/%s/restore/%d"
+	// deleted resources
+	listTrashRoute        = NamespacePrefix + "/trash/%s"
+	undeleteResourceRoute = NamespacePrefix + "/trash/%s/%s"
 
 	// buildRoute route to call BuildEndpoint
-	buildRoute = NamespacePrefix + "/build"
-	// uploadRoute route to the CDN UploadEndpoint
-	uploadRoute = "/_w/upload"
+	buildRoute = NamespacePrefix + "/build?s=%v"
+	// getBuildRoute route to call GetBuildEndpoint
+	getBuildRoute = NamespacePrefix + "/build/%s/%s"
+	// listBuildsRoute route to call ListBuildsEndpoint
+	listBuildsRoute = NamespacePrefix + "/builds/%s"
+	// rollbackRoute route to call RollbackEndpoint
+	rollbackRoute = NamespacePrefix + "/rollback"
+	// scheduleRoute route to call ScheduleEndpoint
+	scheduleRoute = NamespacePrefix + "/schedule/%s"
+	// lintRoute route to call LintEndpoint
+	lintRoute = NamespacePrefix + "/lint/%s"
+	// importFeedRoute route to call ImportFeedEndpoint
+	importFeedRoute = NamespacePrefix + "/import?f=%v"
+	// statsRoute route to call StatsEndpoint
+	statsRoute = NamespacePrefix + "/stats/%s?episode=%s&days=%d"
+	// subscriptionRoute route to call CreateSubscriptionEndpoint
+	subscriptionRoute = NamespacePrefix + "/subscription"
+	// listSubscriptionsRoute route to call ListSubscriptionsEndpoint
+	listSubscriptionsRoute = NamespacePrefix + "/subscriptions/%s"
+	// revokeSubscriptionRoute route to call RevokeSubscriptionEndpoint
+	revokeSubscriptionRoute = NamespacePrefix + "/subscription/%s/%s"
+	// memberRoute route to call AddMemberEndpoint
+	memberRoute = NamespacePrefix + "/member"
+	// listMembersRoute route to call ListMembersEndpoint
+	listMembersRoute = NamespacePrefix + "/members/%s"
+	// removeMemberRoute route to call RemoveMemberEndpoint
+	removeMemberRoute = NamespacePrefix + "/member/%s/%s"
+	// webhookRoute route to call CreateWebhookEndpoint
+	webhookRoute = NamespacePrefix + "/webhook"
+	// listWebhooksRoute route to call ListWebhooksEndpoint
+	listWebhooksRoute = NamespacePrefix + "/webhooks/%s"
+	// deleteWebhookRoute route to call DeleteWebhookEndpoint
+	deleteWebhookRoute = NamespacePrefix + "/webhook/%s/%s"
+	// listDeliveriesRoute route to call ListDeliveriesEndpoint
+	listDeliveriesRoute = NamespacePrefix + "/webhooks/%s/deliveries"
+	// createUploadRoute route to the CDN CreateUploadEndpoint
+	createUploadRoute = "/_w/uploads/%s"
+	// verifyRoute route to the CDN VerifyEndpoint
+	verifyRoute = "/_a/verify/%s"
+	// reconcileRoute route to the CDN ReconcileEndpoint
+	reconcileRoute = "/_a/fsck/%s"
+	// repairReportRoute route to the CDN RepairReportEndpoint
+	repairReportRoute = "/_a/fsck/%s/repair"
+
+	// maxChunkRetries is the number of times a chunk is resent before an upload is interrupted
+	maxChunkRetries = 3
 )
 
 func assertNotEmpty(claims ...string) bool {
@@ -47,6 +110,15 @@ func assertNotEmpty(claims ...string) bool {
 	return true
 }
 
+// versionHeader sends the version of a resource the client has read, if there is one
+func versionHeader(version string) http.Header {
+	header := http.Header{}
+	if version != "" {
+		header.Set(transport.HeaderIfMatch, version)
+	}
+	return header
+}
+
 // CreateProduction invokes the CreateProductionEndpoint
 func (cl *Client) CreateProduction(name, title, summary string) (*Production, error) {
 	if !cl.IsValid() {
@@ -86,62 +158,145 @@ func (cl *Client) Productions() (*ProductionList, error) {
 	return &resp, nil
 }
 
-// CreateResource invokes the ResourceEndpoint
-func (cl *Client) CreateResource(production, kind, guid string, force bool, rsrc interface{}) (int, error) {
+// RenameProduction invokes the RenameProductionEndpoint
+func (cl *Client) RenameProduction(production, name string) (*Production, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if !assertNotEmpty(production, name) {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	req := ProductionRequest{
+		Name: name,
+	}
+	resp := Production{}
+
+	_, err := transport.Put(cl.opts.APIEndpoint, fmt.Sprintf(updateProductionRoute, production), cl.opts.Token, &req, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// ArchiveProduction invokes the ArchiveProductionEndpoint. An archived production is read-only.
+func (cl *Client) ArchiveProduction(production string, archived bool) (*Production, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	method := "POST"
+	if !archived {
+		method = "DELETE"
+	}
+	resp := Production{}
+
+	_, _, err := transport.Exchange(method, cl.opts.APIEndpoint, fmt.Sprintf(archiveProductionRoute, production), cl.opts.Token, nil, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// TransferProduction invokes the TransferProductionEndpoint. The new owner is identified by its user id, e.g. an email.
+func (cl *Client) TransferProduction(production, userID string) (*Production, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if !assertNotEmpty(production, userID) {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	req := ProductionRequest{
+		Realm:  cl.Realm(),
+		UserID: userID,
+	}
+	resp := Production{}
+
+	_, err := transport.Post(cl.opts.APIEndpoint, fmt.Sprintf(transferProductionRoute, production), cl.opts.Token, &req, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// DeleteProduction invokes the DeleteProductionEndpoint. The production is moved to the trash and purged
+// with all its resources once the retention period has ended.
+func (cl *Client) DeleteProduction(production string) (int, error) {
 	if !cl.IsValid() {
 		return http.StatusBadRequest, errordef.ErrInvalidClientConfiguration
 	}
-	if !assertNotEmpty(production, kind, guid) {
+	if production == "" {
 		return http.StatusBadRequest, errordef.ErrInvalidParameters
 	}
 
-	resp := api.StatusObject{}
-	status, err := transport.Post(cl.opts.APIEndpoint, fmt.Sprintf(updateResourceRoute, production, kind, guid, force), cl.opts.Token, rsrc, &resp)
+	return transport.Delete(cl.opts.APIEndpoint, fmt.Sprintf(updateProductionRoute, production), cl.opts.Token, nil)
+}
+
+// CreateResource invokes the ResourceEndpoint and returns the version of the new resource
+func (cl *Client) CreateResource(production, kind, guid string, force bool, rsrc interface{}) (string, error) {
+	if !cl.IsValid() {
+		return "", errordef.ErrInvalidClientConfiguration
+	}
+	if !assertNotEmpty(production, kind, guid) {
+		return "", errordef.ErrInvalidParameters
+	}
 
+	resp := api.StatusObject{}
+	_, header, err := transport.Exchange("POST", cl.opts.APIEndpoint, fmt.Sprintf(updateResourceRoute, production, kind, guid, force), cl.opts.Token, nil, rsrc, &resp)
 	if err != nil {
-		return status, err
+		return "", err
 	}
-	return status, nil
+	return header.Get(transport.HeaderETag), nil
 }
 
-// GetResource returns a resource file
-func (cl *Client) GetResource(production, kind, guid string, rsrc interface{}) error {
+// GetResource returns a resource file and its version
+func (cl *Client) GetResource(production, kind, guid string, rsrc interface{}) (string, error) {
 	if !cl.IsValid() {
-		return errordef.ErrInvalidClientConfiguration
+		return "", errordef.ErrInvalidClientConfiguration
 	}
 	if !assertNotEmpty(production, kind, guid) {
-		return errordef.ErrInvalidParameters
+		return "", errordef.ErrInvalidParameters
 	}
 
-	status, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(getResourceRoute, production, kind, guid), cl.opts.Token, rsrc)
+	status, header, err := transport.Exchange("GET", cl.opts.APIEndpoint, fmt.Sprintf(getResourceRoute, production, kind, guid), cl.opts.Token, nil, nil, rsrc)
+	if status == http.StatusNotFound {
+		return "", errordef.ErrNoSuchResource
+	}
 	if status == http.StatusBadRequest {
-		return fmt.Errorf(messagedef.MsgResourceNotFound, fmt.Sprintf("%s/%s-%s", production, kind, guid))
+		return "", fmt.Errorf(messagedef.MsgResourceNotFound, fmt.Sprintf("%s/%s-%s", production, kind, guid))
 	}
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return header.Get(transport.HeaderETag), nil
 }
 
-// FindResource returns a resource file
-func (cl *Client) FindResource(guid string, rsrc interface{}) error {
+// FindResource returns a resource file and its version
+func (cl *Client) FindResource(guid string, rsrc interface{}) (string, error) {
 	if !cl.IsValid() {
-		return errordef.ErrInvalidClientConfiguration
+		return "", errordef.ErrInvalidClientConfiguration
 	}
 	if guid == "" {
-		return errordef.ErrInvalidParameters
+		return "", errordef.ErrInvalidParameters
 	}
 
-	status, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(findResourceRoute, guid), cl.opts.Token, rsrc)
+	status, header, err := transport.Exchange("GET", cl.opts.APIEndpoint, fmt.Sprintf(findResourceRoute, guid), cl.opts.Token, nil, nil, rsrc)
 	if status == http.StatusBadRequest {
-		return fmt.Errorf(messagedef.MsgResourceNotFound, guid)
+		return "", fmt.Errorf(messagedef.MsgResourceNotFound, guid)
 	}
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return header.Get(transport.HeaderETag), nil
 }
 
 // Resources retrieves a list of resources
@@ -164,26 +319,30 @@ func (cl *Client) Resources(production, kind string) (*ResourceList, error) {
 	return &resp, nil
 }
 
-// UpdateResource invokes the ResourceEndpoint
-func (cl *Client) UpdateResource(production, kind, guid string, force bool, rsrc interface{}) (int, error) {
+// UpdateResource invokes the ResourceEndpoint and returns the new version of the resource.
+// The update is rejected with ErrPreconditionFailed if version is not empty and the resource was changed since.
+func (cl *Client) UpdateResource(production, kind, guid, version string, force bool, rsrc interface{}) (string, error) {
 	if !cl.IsValid() {
-		return http.StatusBadRequest, errordef.ErrInvalidClientConfiguration
+		return "", errordef.ErrInvalidClientConfiguration
 	}
 	if !assertNotEmpty(production, kind, guid) {
-		return http.StatusBadRequest, errordef.ErrInvalidParameters
+		return "", errordef.ErrInvalidParameters
 	}
 
 	resp := api.StatusObject{}
-	status, err := transport.Put(cl.opts.APIEndpoint, fmt.Sprintf(updateResourceRoute, production, kind, guid, force), cl.opts.Token, rsrc, &resp)
-
+	status, header, err := transport.Exchange("PUT", cl.opts.APIEndpoint, fmt.Sprintf(updateResourceRoute, production, kind, guid, force), cl.opts.Token, versionHeader(version), rsrc, &resp)
+	if status == http.StatusPreconditionFailed {
+		return "", errordef.ErrPreconditionFailed
+	}
 	if err != nil {
-		return status, err
+		return "", err
 	}
-	return status, nil
+	return header.Get(transport.HeaderETag), nil
 }
 
-// DeleteResource deletes a resources
-func (cl *Client) DeleteResource(production, kind, guid string) (int, error) {
+// DeleteResource deletes a resources.
+// The delete is rejected with ErrPreconditionFailed if version is not empty and the resource was changed since.
+func (cl *Client) DeleteResource(production, kind, guid, version string) (int, error) {
 	if !cl.IsValid() {
 		return http.StatusBadRequest, errordef.ErrInvalidClientConfiguration
 	}
@@ -191,15 +350,109 @@ func (cl *Client) DeleteResource(production, kind, guid string) (int, error) {
 		return http.StatusBadRequest, errordef.ErrInvalidParameters
 	}
 
-	status, err := transport.Delete(cl.opts.APIEndpoint, fmt.Sprintf(deleteResourceRoute, production, kind, guid), cl.opts.Token, nil)
+	status, _, err := transport.Exchange("DELETE", cl.opts.APIEndpoint, fmt.Sprintf(deleteResourceRoute, production, kind, guid), cl.opts.Token, versionHeader(version), nil, nil)
+	if status == http.StatusPreconditionFailed {
+		return status, errordef.ErrPreconditionFailed
+	}
 	if err != nil {
 		return status, err
 	}
 	return status, nil
 }
 
-// Build invokes the BuildEndpoint
-func (cl *Client) Build(production string) (*BuildRequest, error) {
+// Revisions invokes the ListRevisionsEndpoint
+func (cl *Client) Revisions(guid string) (*RevisionList, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if guid == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp RevisionList
+	_, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(listRevisionsRoute, guid), cl.opts.Token, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Diff invokes the DiffRevisionsEndpoint
+func (cl *Client) Diff(guid string, from, to int) (*RevisionDiff, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if guid == "" || from < 1 || to < 1 {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp RevisionDiff
+	_, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(diffRevisionsRoute, guid, from, to), cl.opts.Token, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Restore invokes the RestoreRevisionEndpoint and returns the new revision
+func (cl *Client) Restore(guid string, revision int) (*Revision, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if guid == "" || revision < 1 {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp Revision
+	_, err := transport.Post(cl.opts.APIEndpoint, fmt.Sprintf(restoreRevisionRoute, guid, revision), cl.opts.Token, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Trash invokes the ListTrashEndpoint
+func (cl *Client) Trash(production string) (*TrashList, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp TrashList
+	_, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(listTrashRoute, production), cl.opts.Token, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Undelete invokes the UndeleteResourceEndpoint and returns the restored resource
+func (cl *Client) Undelete(production, guid string) (*Resource, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if !assertNotEmpty(production, guid) {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp Resource
+	_, err := transport.Post(cl.opts.APIEndpoint, fmt.Sprintf(undeleteResourceRoute, production, guid), cl.opts.Token, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Build invokes the BuildEndpoint. The build runs in the background, use GetBuild to check its state.
+// A strict build verifies all enclosures and images before the feed is published.
+func (cl *Client) Build(production string, strict bool) (*Build, error) {
 	if !cl.IsValid() {
 		return nil, errordef.ErrInvalidClientConfiguration
 	}
@@ -210,9 +463,9 @@ func (cl *Client) Build(production string) (*BuildRequest, error) {
 	req := BuildRequest{
 		GUID: production,
 	}
-	resp := BuildRequest{}
+	resp := Build{}
 
-	_, err := transport.Post(cl.opts.APIEndpoint, buildRoute, cl.opts.Token, &req, &resp)
+	_, err := transport.Post(cl.opts.APIEndpoint, fmt.Sprintf(buildRoute, strict), cl.opts.Token, &req, &resp)
 	if err != nil {
 		return nil, err
 	}
@@ -220,35 +473,579 @@ func (cl *Client) Build(production string) (*BuildRequest, error) {
 	return &resp, nil
 }
 
-// Upload invokes the UploadEndpoint
-func (cl *Client) Upload(production, path string, force bool) error {
+// GetBuild invokes the GetBuildEndpoint
+func (cl *Client) GetBuild(production, id string) (*Build, error) {
 	if !cl.IsValid() {
-		return errordef.ErrInvalidClientConfiguration
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if !assertNotEmpty(production, id) {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp Build
+	_, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(getBuildRoute, production, id), cl.opts.Token, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// ListBuilds invokes the ListBuildsEndpoint
+func (cl *Client) ListBuilds(production string) (*BuildList, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp BuildList
+	_, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(listBuildsRoute, production), cl.opts.Token, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Rollback invokes the RollbackEndpoint. Without an id, the feed published before the live one is restored.
+func (cl *Client) Rollback(production, id string) (*Build, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	req := BuildRequest{
+		GUID: production,
+		ID:   id,
+	}
+	resp := Build{}
+
+	_, err := transport.Post(cl.opts.APIEndpoint, rollbackRoute, cl.opts.Token, &req, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Lint invokes the LintEndpoint
+func (cl *Client) Lint(production string) (*LintReport, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp LintReport
+	_, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(lintRoute, production), cl.opts.Token, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Verify invokes the VerifyEndpoint of the CDN. It queues the check and returns its pending report,
+// use VerifyReport to find out when it is finished. Requires a token with admin scope.
+func (cl *Client) Verify(production string) (*IntegrityReport, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp IntegrityReport
+	_, err := transport.Post(cl.opts.CDNEndpoint, fmt.Sprintf(verifyRoute, production), cl.opts.Token, nil, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// VerifyReport returns the report of the latest check of a production. Requires a token with admin scope.
+func (cl *Client) VerifyReport(production string) (*IntegrityReport, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp IntegrityReport
+	status, err := transport.Get(cl.opts.CDNEndpoint, fmt.Sprintf(verifyRoute, production), cl.opts.Token, &resp)
+	if status == http.StatusNotFound {
+		return nil, errordef.ErrNoSuchObject
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Reconcile invokes the ReconcileEndpoint of the CDN. Issues are only reported unless repair is true,
+// the repair is queued and its pending report returned, use RepairReport to find out when it is finished.
+// Requires a token with admin scope.
+func (cl *Client) Reconcile(production string, repair bool) (*ReconciliationReport, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp ReconciliationReport
+	if repair {
+		_, err := transport.Post(cl.opts.CDNEndpoint, fmt.Sprintf(reconcileRoute, production), cl.opts.Token, nil, &resp)
+		if err != nil {
+			return nil, err
+		}
+		return &resp, nil
+	}
+
+	_, err := transport.Get(cl.opts.CDNEndpoint, fmt.Sprintf(reconcileRoute, production), cl.opts.Token, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// RepairReport returns the report of the latest repair of a production. Requires a token with admin scope.
+func (cl *Client) RepairReport(production string) (*ReconciliationReport, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp ReconciliationReport
+	status, err := transport.Get(cl.opts.CDNEndpoint, fmt.Sprintf(repairReportRoute, production), cl.opts.Token, &resp)
+	if status == http.StatusNotFound {
+		return nil, errordef.ErrNoSuchObject
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Stats invokes the StatsEndpoint. Episode is optional.
+func (cl *Client) Stats(production, episode string, days int) (*DownloadStatsList, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" || days < 1 {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp DownloadStatsList
+	_, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(statsRoute, production, url.QueryEscape(episode), days), cl.opts.Token, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// CreateSubscription invokes the CreateSubscriptionEndpoint
+func (cl *Client) CreateSubscription(production, name string) (*Subscription, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if !assertNotEmpty(production, name) {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	req := Subscription{
+		GUID: production,
+		Name: name,
+	}
+	resp := Subscription{}
+
+	_, err := transport.Post(cl.opts.APIEndpoint, subscriptionRoute, cl.opts.Token, &req, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// ListSubscriptions invokes the ListSubscriptionsEndpoint
+func (cl *Client) ListSubscriptions(production string) (*SubscriptionList, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp SubscriptionList
+	_, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(listSubscriptionsRoute, production), cl.opts.Token, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// RevokeSubscription invokes the RevokeSubscriptionEndpoint
+func (cl *Client) RevokeSubscription(production, token string) (int, error) {
+	if !cl.IsValid() {
+		return http.StatusBadRequest, errordef.ErrInvalidClientConfiguration
+	}
+	if !assertNotEmpty(production, token) {
+		return http.StatusBadRequest, errordef.ErrInvalidParameters
+	}
+
+	return transport.Delete(cl.opts.APIEndpoint, fmt.Sprintf(revokeSubscriptionRoute, production, token), cl.opts.Token, nil)
+}
+
+// AddMember invokes the AddMemberEndpoint. The new member is identified by its user id, e.g. an email.
+func (cl *Client) AddMember(production, userID, role string) (*Member, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if !assertNotEmpty(production, userID, role) {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	req := Member{
+		GUID:   production,
+		UserID: userID,
+		Realm:  cl.Realm(),
+		Role:   role,
+	}
+	resp := Member{}
+
+	_, err := transport.Post(cl.opts.APIEndpoint, memberRoute, cl.opts.Token, &req, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// ListMembers invokes the ListMembersEndpoint
+func (cl *Client) ListMembers(production string) (*MemberList, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp MemberList
+	_, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(listMembersRoute, production), cl.opts.Token, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// RemoveMember invokes the RemoveMemberEndpoint
+func (cl *Client) RemoveMember(production, userID string) (int, error) {
+	if !cl.IsValid() {
+		return http.StatusBadRequest, errordef.ErrInvalidClientConfiguration
+	}
+	if !assertNotEmpty(production, userID) {
+		return http.StatusBadRequest, errordef.ErrInvalidParameters
+	}
+
+	return transport.Delete(cl.opts.APIEndpoint, fmt.Sprintf(removeMemberRoute, production, url.PathEscape(userID)), cl.opts.Token, nil)
+}
+
+// CreateWebhook invokes the CreateWebhookEndpoint. The webhook receives all events if none are given.
+func (cl *Client) CreateWebhook(production, uri string, events []string) (*Webhook, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if !assertNotEmpty(production, uri) {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	req := Webhook{
+		GUID:   production,
+		URL:    uri,
+		Events: events,
+	}
+	resp := Webhook{}
+
+	_, err := transport.Post(cl.opts.APIEndpoint, webhookRoute, cl.opts.Token, &req, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// ListWebhooks invokes the ListWebhooksEndpoint
+func (cl *Client) ListWebhooks(production string) (*WebhookList, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp WebhookList
+	_, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(listWebhooksRoute, production), cl.opts.Token, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// DeleteWebhook invokes the DeleteWebhookEndpoint
+func (cl *Client) DeleteWebhook(production, id string) (int, error) {
+	if !cl.IsValid() {
+		return http.StatusBadRequest, errordef.ErrInvalidClientConfiguration
+	}
+	if !assertNotEmpty(production, id) {
+		return http.StatusBadRequest, errordef.ErrInvalidParameters
+	}
+
+	return transport.Delete(cl.opts.APIEndpoint, fmt.Sprintf(deleteWebhookRoute, production, id), cl.opts.Token, nil)
+}
+
+// ListDeliveries invokes the ListDeliveriesEndpoint
+func (cl *Client) ListDeliveries(production string) (*WebhookDeliveryList, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp WebhookDeliveryList
+	_, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(listDeliveriesRoute, production), cl.opts.Token, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Schedule invokes the ScheduleEndpoint
+func (cl *Client) Schedule(production string) (*ResourceList, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if production == "" {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	var resp ResourceList
+	_, err := transport.Get(cl.opts.APIEndpoint, fmt.Sprintf(scheduleRoute, production), cl.opts.Token, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ImportFeed invokes the ImportFeedEndpoint
+func (cl *Client) ImportFeed(production, feedURL string, force bool) (*ResourceList, error) {
+	if !cl.IsValid() {
+		return nil, errordef.ErrInvalidClientConfiguration
+	}
+	if !assertNotEmpty(production, feedURL) {
+		return nil, errordef.ErrInvalidParameters
+	}
+
+	req := FeedImportRequest{
+		GUID:    production,
+		FeedURL: feedURL,
+	}
+	resp := ResourceList{}
+
+	_, err := transport.Post(cl.opts.APIEndpoint, fmt.Sprintf(importFeedRoute, force), cl.opts.Token, &req, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
+// Upload transfers a file to the CDN in chunks. It always starts a new upload, use CreateUpload and ResumeUpload
+// to resume an interrupted one.
+func (cl *Client) Upload(production, path string) error {
+	location, err := cl.CreateUpload(production, path)
+	if err != nil {
+		return err
+	}
+	return cl.ResumeUpload(location, path, nil)
+}
+
+// CreateUpload starts a resumable upload of a file and returns its location in the CDN. Nothing is sent yet,
+// see ResumeUpload.
+func (cl *Client) CreateUpload(production, path string) (string, error) {
+	if !cl.IsValid() {
+		return "", errordef.ErrInvalidClientConfiguration
 	}
 	if !assertNotEmpty(production, path) {
-		return errordef.ErrInvalidParameters
+		return "", errordef.ErrInvalidParameters
 	}
 
-	req, err := transport.Upload(cl.opts.CDNEndpoint, uploadRoute, cl.opts.Token, production, "asset", path)
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	info, err := f.Stat()
 	if err != nil {
-		log.Fatal(err)
+		return "", err
 	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+
+	header := http.Header{}
+	header.Set(HeaderTusResumable, TusVersion)
+	header.Set(HeaderUploadLength, strconv.FormatInt(info.Size(), 10))
+	header.Set(HeaderUploadMetadata, fmt.Sprintf("filename %s,checksum %s",
+		base64.StdEncoding.EncodeToString([]byte(filepath.Base(path))),
+		base64.StdEncoding.EncodeToString([]byte(FormatChecksum(h.Sum(nil))))))
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	status, rh, err := transport.Send("POST", cl.opts.CDNEndpoint+fmt.Sprintf(createUploadRoute, production), cl.opts.Token, header, nil)
 	if err != nil {
-		log.Fatal(err)
+		return "", err
+	}
+	if status != http.StatusCreated || rh.Get("Location") == "" {
+		return "", fmt.Errorf(messagedef.MsgResourceUploadError, fmt.Sprintf("%s:%d", path, status))
+	}
+	return rh.Get("Location"), nil
+}
+
+// ResumeUpload sends the chunks of a file the CDN has not received yet, starting with the first chunk of a new upload.
+// The upload is complete when ResumeUpload returns without an error. errordef.ErrNoSuchUpload is returned if the upload
+// has expired or the file was changed since the upload was created, errordef.ErrChecksumMismatch if the CDN does not
+// serve the same content.
+func (cl *Client) ResumeUpload(location, path string, progress UploadProgress) error {
+	if !cl.IsValid() {
+		return errordef.ErrInvalidClientConfiguration
 	}
-	body := &bytes.Buffer{}
-	_, err = body.ReadFrom(resp.Body)
+	if !assertNotEmpty(location, path) {
+		return errordef.ErrInvalidParameters
+	}
+	uri := location
+	if strings.HasPrefix(location, "/") {
+		uri = cl.opts.CDNEndpoint + location
+	}
+
+	f, err := os.Open(path)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	resp.Body.Close()
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	offset, length, err := cl.uploadOffset(uri)
+	if err != nil {
+		return err
+	}
+	if length != info.Size() {
+		return errordef.ErrNoSuchUpload
+	}
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+	etag := fmt.Sprintf("\"%x\"", h.Sum(nil))
+	if progress != nil {
+		progress(offset, length)
+	}
+
+	buffer := make([]byte, UploadChunkSize)
+	retries := 0
+	for offset < length {
+		n, err := f.ReadAt(buffer, offset)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		chunk := buffer[:n]
+		sum := sha256.Sum256(chunk)
+
+		header := http.Header{}
+		header.Set(HeaderTusResumable, TusVersion)
+		header.Set("Content-Type", ContentTypeOffsetStream)
+		header.Set(HeaderUploadOffset, strconv.FormatInt(offset, 10))
+		header.Set(HeaderUploadChecksum, FormatChecksum(sum[:]))
+
+		status, rh, err := transport.Send("PATCH", uri, cl.opts.Token, header, bytes.NewReader(chunk))
+		if err == nil && status == http.StatusNoContent {
+			if offset, err = strconv.ParseInt(rh.Get(HeaderUploadOffset), 10, 64); err != nil {
+				return err
+			}
+			retries = 0
+			if progress != nil {
+				progress(offset, length)
+			}
+			// the response to the last chunk carries the digest of the file in the CDN
+			if offset == length && rh.Get(transport.HeaderETag) != "" && rh.Get(transport.HeaderETag) != etag {
+				return errordef.ErrChecksumMismatch
+			}
+			continue
+		}
+		if err == nil && (status == http.StatusNotFound || status == http.StatusGone) {
+			return errordef.ErrNoSuchUpload
+		}
+		if err == nil && status < http.StatusInternalServerError && status != http.StatusConflict && status != StatusChecksumMismatch {
+			return fmt.Errorf(messagedef.MsgResourceUploadError, fmt.Sprintf("%s:%d", path, status))
+		}
 
-	if resp.StatusCode > http.StatusNoContent {
-		return fmt.Errorf(messagedef.MsgResourceUploadError, fmt.Sprintf("%s:%s", path, resp.Status))
+		// the connection dropped or the chunk was corrupted, continue where the CDN stopped receiving
+		retries++
+		if retries > maxChunkRetries {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf(messagedef.MsgResourceUploadError, fmt.Sprintf("%s:%d", path, status))
+		}
+		if offset, _, err = cl.uploadOffset(uri); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// uploadOffset returns the number of bytes of an upload the CDN has received so far and the size of the file
+func (cl *Client) uploadOffset(uri string) (int64, int64, error) {
+	header := http.Header{}
+	header.Set(HeaderTusResumable, TusVersion)
+
+	status, rh, err := transport.Send("HEAD", uri, cl.opts.Token, header, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	if status == http.StatusNotFound || status == http.StatusGone {
+		return 0, 0, errordef.ErrNoSuchUpload
+	}
+	if status != http.StatusOK {
+		return 0, 0, fmt.Errorf(messagedef.MsgStatus, status)
+	}
+
+	offset, err := strconv.ParseInt(rh.Get(HeaderUploadOffset), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	length, err := strconv.ParseInt(rh.Get(HeaderUploadLength), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return offset, length, nil
+}
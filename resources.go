@@ -6,8 +6,6 @@ import (
 	"time"
 
 	"github.com/txsvc/platform/v2/pkg/id"
-
-	"github.com/podops/podops/internal/metadata"
 )
 
 const (
@@ -20,6 +18,9 @@ const (
 	//		type:		Episodic | Serial REQUIRED 'channel. itunes.type'
 	//		block:		Yes OPTIONAL 'channel.itunes.block' Anything else than 'Yes' has no effect
 	//		complete:	Yes OPTIONAL 'channel.itunes.complete' Anything else than 'Yes' has no effect
+	//		locked:		Yes OPTIONAL 'channel.podcast.locked' Anything else than 'Yes' is 'no'
+	//		podcast_guid:	<UUIDv5> OPTIONAL 'channel.podcast.guid'
+	//		private:	Yes OPTIONAL The show is only available to subscribers, there is no public feed
 	//
 	//	episode:
 	//		guid:		<unique id> 'item.guid'
@@ -29,6 +30,8 @@ const (
 	//		explicit:	True | False REQUIRED 'channel.itunes.explicit'
 	//		type:		Full | Trailer | Bonus REQUIRED 'item.itunes.episodeType'
 	//		block:		Yes OPTIONAL 'item.itunes.block' Anything else than 'Yes' has no effect
+	//		item_guid:	<original guid> OPTIONAL 'item.guid' Overrides guid, e.g. for imported episodes
+	//		private:	Yes OPTIONAL The episode only appears in the subscribers' feeds
 	//
 
 	// LabelLanguage ISO-639 two-letter language code. channel.language
@@ -41,8 +44,16 @@ const (
 	LabelBlock = "block"
 	// LabelComplete ["Yes"] channel.itunes.complete
 	LabelComplete = "complete"
+	// LabelLocked ["Yes"] channel.podcast.locked
+	LabelLocked = "locked"
+	// LabelPodcastGUID globally unique podcast identifier, channel.podcast.guid
+	LabelPodcastGUID = "podcast_guid"
 	// LabelGUID resources GUID
 	LabelGUID = "guid"
+	// LabelItemGUID the original guid of an imported episode, the resource's GUID is derived from it. item.guid
+	LabelItemGUID = "item_guid"
+	// LabelPrivate ["Yes"] the show or episode is only available to subscribers
+	LabelPrivate = "private"
 	// LabelParentGUID guid of the resources parent resource
 	LabelParentGUID = "parent_guid"
 	// LabelDate used as e.g. publish date of an episode
@@ -71,12 +82,23 @@ const (
 	// ResourceTypeImport references an external resources that will be imported into the CDN
 	ResourceTypeImport = "import"
 
+	// TranscriptTypeSRT SubRip captions
+	TranscriptTypeSRT = "application/x-subrip"
+	// TranscriptTypeVTT WebVTT captions
+	TranscriptTypeVTT = "text/vtt"
+	// TranscriptTypeJSON JSON transcript
+	TranscriptTypeJSON = "application/json"
+	// TranscriptTypeHTML HTML transcript
+	TranscriptTypeHTML = "text/html"
+
 	// ResourceShow is referencing a resource of type "show"
 	ResourceShow = "show"
 	// ResourceEpisode is referencing a resource of type "episode"
 	ResourceEpisode = "episode"
 	// ResourceAsset is referencing any media or binary resource e.g. .mp3 or .png
 	ResourceAsset = "asset"
+	// ResourceProduction is a production in the trash, i.e. the show with everything belonging to it
+	ResourceProduction = "production"
 	// ResourceALL is a wildcard for any kind of resource
 	ResourceALL = "all"
 )
@@ -105,16 +127,26 @@ type (
 		Metadata    Metadata        `json:"metadata" yaml:"metadata" binding:"required"`       // REQUIRED
 		Description ShowDescription `json:"description" yaml:"description" binding:"required"` // REQUIRED
 		Image       Asset           `json:"image" yaml:"image" binding:"required"`             // REQUIRED 'channel.itunes.image'
+		Funding     []Asset         `json:"funding,omitempty" yaml:"funding,omitempty"`        // OPTIONAL 'channel.podcast.funding'
+		Persons     []Person        `json:"persons,omitempty" yaml:"persons,omitempty"`        // OPTIONAL 'channel.podcast.person'
+		Location    *Location       `json:"location,omitempty" yaml:"location,omitempty"`      // OPTIONAL 'channel.podcast.location'
+		Value       *Value          `json:"value,omitempty" yaml:"value,omitempty"`            // OPTIONAL 'channel.podcast.value'
 	}
 
 	// Episode holds all metadata related to a podcast episode
 	Episode struct {
-		APIVersion  string             `json:"apiVersion" yaml:"apiVersion" binding:"required"`   // REQUIRED default: v1.0
-		Kind        string             `json:"kind" yaml:"kind" binding:"required"`               // REQUIRED default: episode
-		Metadata    Metadata           `json:"metadata" yaml:"metadata" binding:"required"`       // REQUIRED
-		Description EpisodeDescription `json:"description" yaml:"description" binding:"required"` // REQUIRED
-		Image       Asset              `json:"image" yaml:"image" binding:"required"`             // REQUIRED 'item.itunes.image'
-		Enclosure   Asset              `json:"enclosure" yaml:"enclosure" binding:"required"`     // REQUIRED
+		APIVersion  string             `json:"apiVersion" yaml:"apiVersion" binding:"required"`    // REQUIRED default: v1.0
+		Kind        string             `json:"kind" yaml:"kind" binding:"required"`                // REQUIRED default: episode
+		Metadata    Metadata           `json:"metadata" yaml:"metadata" binding:"required"`        // REQUIRED
+		Description EpisodeDescription `json:"description" yaml:"description" binding:"required"`  // REQUIRED
+		Image       Asset              `json:"image" yaml:"image" binding:"required"`              // REQUIRED 'item.itunes.image'
+		Enclosure   Asset              `json:"enclosure" yaml:"enclosure" binding:"required"`      // REQUIRED
+		Transcripts []Asset            `json:"transcripts,omitempty" yaml:"transcripts,omitempty"` // OPTIONAL 'item.podcast.transcript'
+		Chapters    *Asset             `json:"chapters,omitempty" yaml:"chapters,omitempty"`       // OPTIONAL 'item.podcast.chapters'
+		Persons     []Person           `json:"persons,omitempty" yaml:"persons,omitempty"`         // OPTIONAL 'item.podcast.person'
+		Location    *Location          `json:"location,omitempty" yaml:"location,omitempty"`       // OPTIONAL 'item.podcast.location'
+		Soundbites  []Soundbite        `json:"soundbites,omitempty" yaml:"soundbites,omitempty"`   // OPTIONAL 'item.podcast.soundbite'
+		Value       *Value             `json:"value,omitempty" yaml:"value,omitempty"`             // OPTIONAL 'item.podcast.value'
 	}
 
 	// ShowDescription holds essential show metadata
@@ -158,6 +190,48 @@ type (
 		Type  string `json:"type,omitempty" yaml:"type,omitempty"`   // OPTIONAL
 		Size  int    `json:"size,omitempty" yaml:"size,omitempty"`   // OPTIONAL
 	}
+
+	// Person describes a person of interest to the show or episode, e.g. host or guest
+	Person struct {
+		Name  string `json:"name" yaml:"name" binding:"required"`    // REQUIRED
+		Role  string `json:"role,omitempty" yaml:"role,omitempty"`   // OPTIONAL default: host
+		Group string `json:"group,omitempty" yaml:"group,omitempty"` // OPTIONAL default: cast
+		Image string `json:"image,omitempty" yaml:"image,omitempty"` // OPTIONAL
+		Link  string `json:"link,omitempty" yaml:"link,omitempty"`   // OPTIONAL
+	}
+
+	// Location describes the location of editorial focus of the show or episode
+	Location struct {
+		Name string `json:"name" yaml:"name" binding:"required"` // REQUIRED
+		Geo  string `json:"geo,omitempty" yaml:"geo,omitempty"`  // OPTIONAL e.g. 'geo:30.2672,97.7431'
+		OSM  string `json:"osm,omitempty" yaml:"osm,omitempty"`  // OPTIONAL e.g. 'R113314'
+	}
+
+	// Soundbite points to a section of the episode, e.g. to be used as a preview
+	Soundbite struct {
+		Start    float64 `json:"start" yaml:"start" binding:"required"`       // REQUIRED seconds
+		Duration float64 `json:"duration" yaml:"duration" binding:"required"` // REQUIRED seconds
+		Title    string  `json:"title,omitempty" yaml:"title,omitempty"`      // OPTIONAL
+	}
+
+	// Value designates the payment layer used to transact value to the podcaster
+	Value struct {
+		Type       string           `json:"type" yaml:"type" binding:"required"`             // REQUIRED e.g. 'lightning'
+		Method     string           `json:"method" yaml:"method" binding:"required"`         // REQUIRED e.g. 'keysend'
+		Suggested  string           `json:"suggested,omitempty" yaml:"suggested,omitempty"`  // OPTIONAL
+		Recipients []ValueRecipient `json:"recipients" yaml:"recipients" binding:"required"` // REQUIRED
+	}
+
+	// ValueRecipient is a destination of the payments
+	ValueRecipient struct {
+		Name        string `json:"name,omitempty" yaml:"name,omitempty"`               // OPTIONAL
+		Type        string `json:"type" yaml:"type" binding:"required"`                // REQUIRED e.g. 'node'
+		Address     string `json:"address" yaml:"address" binding:"required"`          // REQUIRED
+		Split       int    `json:"split" yaml:"split" binding:"required"`              // REQUIRED
+		CustomKey   string `json:"customKey,omitempty" yaml:"customKey,omitempty"`     // OPTIONAL
+		CustomValue string `json:"customValue,omitempty" yaml:"customValue,omitempty"` // OPTIONAL
+		Fee         bool   `json:"fee,omitempty" yaml:"fee,omitempty"`                 // OPTIONAL
+	}
 )
 
 //
@@ -193,6 +267,11 @@ func (e *Episode) Parent() string {
 	return e.Metadata.Labels[LabelParentGUID]
 }
 
+// IsPrivate returns true if the episode only appears in the subscribers' feeds
+func (e *Episode) IsPrivate() bool {
+	return strings.ToLower(e.Metadata.Labels[LabelPrivate]) == "yes"
+}
+
 // GUID is a convenience method to access the resources guid
 func (r *GenericResource) GUID() string {
 	return r.Metadata.Labels[LabelGUID]
@@ -203,6 +282,31 @@ func (s *Show) GUID() string {
 	return s.Metadata.Labels[LabelGUID]
 }
 
+// IsPrivate returns true if the show is only available to subscribers
+func (s *Show) IsPrivate() bool {
+	return strings.ToLower(s.Metadata.Labels[LabelPrivate]) == "yes"
+}
+
+// TranscriptURIs returns the resolved URIs of all transcripts of the episode
+func (e *Episode) TranscriptURIs() []string {
+	if len(e.Transcripts) == 0 {
+		return nil
+	}
+	uris := make([]string, len(e.Transcripts))
+	for i := range e.Transcripts {
+		uris[i] = e.Transcripts[i].ResolveURI(DefaultStorageEndpoint, e.Parent())
+	}
+	return uris
+}
+
+// ChaptersURI returns the resolved URI of the chapters file or an empty string if there is none
+func (e *Episode) ChaptersURI() string {
+	if e.Chapters == nil {
+		return ""
+	}
+	return e.Chapters.ResolveURI(DefaultStorageEndpoint, e.Parent())
+}
+
 // ResolveURI re-writes the URI
 func (r *Asset) ResolveURI(cdn, parent string) string {
 
@@ -210,13 +314,23 @@ func (r *Asset) ResolveURI(cdn, parent string) string {
 		return fmt.Sprintf("%s/%s/%s", cdn, parent, r.URI)
 	}
 	if r.Rel == ResourceTypeImport {
-		return fmt.Sprintf("%s/%s", cdn, metadata.FingerprintWithExt(parent, r.URI))
+		return fmt.Sprintf("%s/%s", cdn, FingerprintWithExt(parent, r.URI))
 	}
 
 	// r.Rel == ResourceTypeExternal or anything else, just return the URI as is ...
 	return r.URI
 }
 
+// FingerprintWithExt creates a unique uri based on the input
+func FingerprintWithExt(parent, uri string) string {
+	id := id.Checksum(parent + uri)
+	parts := strings.Split(uri, ".")
+	if len(parts) == 0 {
+		return id
+	}
+	return fmt.Sprintf("%s/%s.%s", parent, id, parts[len(parts)-1])
+}
+
 func (r *Asset) AssetName() string {
 	parts := strings.Split(r.URI, "/")
 	if len(parts) == 0 {
@@ -236,7 +350,7 @@ func DefaultShowMetadata(guid string) map[string]string {
 	l := make(map[string]string)
 
 	l[LabelLanguage] = "en_US"
-	l[LabelExplicit] = "no"
+	l[LabelExplicit] = "false"
 	l[LabelType] = ShowTypeEpisodic
 	l[LabelBlock] = "no"
 	l[LabelComplete] = "no"
@@ -261,7 +375,7 @@ func DefaultEpisodeMetadata(guid, parent string) map[string]string {
 	l[LabelDate] = time.Now().UTC().Format(time.RFC1123Z)
 	l[LabelSeason] = "1"
 	l[LabelEpisode] = "1"
-	l[LabelExplicit] = "no"
+	l[LabelExplicit] = "false"
 	l[LabelType] = EpisodeTypeFull
 	l[LabelBlock] = "no"
 
@@ -0,0 +1,16 @@
+package podops
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFingerprintWithExt(t *testing.T) {
+	fp := FingerprintWithExt("abcd", "./testfile.mp3")
+
+	assert.NotEmpty(t, fp)
+	assert.True(t, strings.HasPrefix(fp, "abcd"))
+	assert.True(t, strings.HasSuffix(fp, ".mp3"))
+}
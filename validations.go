@@ -1,14 +1,19 @@
 package podops
 
 import (
+	"fmt"
+	"path"
 	"regexp"
+	"strings"
 
 	"github.com/podops/podops/internal/validator"
 )
 
 var (
-	nameRegex  = regexp.MustCompile(`^[a-z]+[a-z0-9_-]`)
-	emailRegex = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
+	nameRegex       = regexp.MustCompile(`^[a-z]+[a-z0-9_-]`)
+	emailRegex      = regexp.MustCompile("^[a-zA-Z0-9.!#$%&'*+\\/=?^_`{|}~-]+@[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?(?:\\.[a-zA-Z0-9](?:[a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?)*$")
+	transcriptTypes = []string{TranscriptTypeSRT, TranscriptTypeVTT, TranscriptTypeJSON, TranscriptTypeHTML}
+	artworkTypes    = []string{"image/jpeg", "image/png"}
 )
 
 // Validate verifies the integrity of struct Show
@@ -33,6 +38,7 @@ func (s *Show) Validate(v *validator.Validator) *validator.Validator {
 	v.AssertContains(s.Metadata.Labels, LabelGUID, "Metadata")
 	v.Validate(&s.Description)
 	v.Validate(&s.Image)
+	validateArtwork(v, &s.Image, "Image")
 
 	return v
 }
@@ -45,6 +51,8 @@ func (s *Show) Validate(v *validator.Validator) *validator.Validator {
 //	Description EpisodeDescription `json:"description" yaml:"description" binding:"required"` // REQUIRED
 //	Image       Resource           `json:"image" yaml:"image" binding:"required"`             // REQUIRED 'item.itunes.image'
 //	Enclosure   Resource           `json:"enclosure" yaml:"enclosure" binding:"required"`     // REQUIRED
+//	Transcripts []Asset            `json:"transcripts,omitempty" yaml:"transcripts,omitempty"` // OPTIONAL 'item.podcast.transcript'
+//	Chapters    *Asset             `json:"chapters,omitempty" yaml:"chapters,omitempty"`       // OPTIONAL 'item.podcast.chapters'
 func (e *Episode) Validate(v *validator.Validator) *validator.Validator {
 	v.AssertStringError(e.APIVersion, Version)
 	v.AssertStringError(e.Kind, ResourceEpisode)
@@ -61,7 +69,15 @@ func (e *Episode) Validate(v *validator.Validator) *validator.Validator {
 	v.AssertContains(e.Metadata.Labels, LabelBlock, "Metadata")
 	v.Validate(&e.Description)
 	v.Validate(&e.Image)
+	validateArtwork(v, &e.Image, "Image")
 	v.Validate(&e.Enclosure)
+	for i := range e.Transcripts {
+		v.Validate(&e.Transcripts[i])
+		v.AssertInList(e.Transcripts[i].Type, transcriptTypes, "Transcripts.Type")
+	}
+	if e.Chapters != nil {
+		v.Validate(e.Chapters)
+	}
 
 	return v
 }
@@ -168,3 +184,18 @@ func ValidEmail(e string) bool {
 	}
 	return emailRegex.MatchString(e)
 }
+
+// validateArtwork verifies the format of cover art. Its dimensions and color model
+// can only be verified once the image is in the CDN, see backend.ValidateProduction.
+func validateArtwork(v *validator.Validator, a *Asset, name string) {
+	if a.Type != "" {
+		v.AssertInList(a.Type, artworkTypes, name+".Type")
+		return
+	}
+	switch strings.ToLower(path.Ext(strings.Split(a.AssetName(), "?")[0])) {
+	case ".jpg", ".jpeg", ".png", "":
+		// looks good or we can't tell
+	default:
+		v.AssertWarning(fmt.Sprintf("Expected JPEG or PNG artwork '%s', found '%s'", name, a.URI))
+	}
+}